@@ -28,6 +28,7 @@ func main() {
 	}
 
 	cache := cache.New(cacheConfig, metricsReporter)
+	defer cache.Close()
 	ctx := context.Background()
 
 	// Example 2: Storing and retrieving simple values
@@ -54,12 +54,20 @@ For gRPC services, the package provides both unary and stream interceptors:
 		grpc.StreamInterceptor(auth.AuthStreamInterceptor(tm)),
 	)
 
+AuthorizedUnaryInterceptor and AuthorizedStreamInterceptor authenticate and then
+authorize a single resource and action in one interceptor.
+
+RequireScope, RequireScopeUnaryInterceptor and RequireScopeStreamInterceptor
+enforce the space-delimited scope claim of tokens created with
+GenerateScopedAccessToken, alongside RBAC.
+
 # Context Helpers
 
 Helper functions are provided to access authentication information from context:
 
 	userID, err := auth.GetUserID(ctx)
 	roles, err := auth.GetUserRoles(ctx)
+	scopes, err := auth.GetUserScopes(ctx)
 
 # Error Handling
 
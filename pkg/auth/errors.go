@@ -15,6 +15,17 @@ const (
 	ErrInvalidResource  liberrors.ErrorCode = "INVALID_RESOURCE"
 	ErrInvalidAction    liberrors.ErrorCode = "INVALID_ACTION"
 	ErrPermissionDenied liberrors.ErrorCode = "PERMISSION_DENIED"
+	ErrTokenReused      liberrors.ErrorCode = "TOKEN_REUSED"
+	ErrInvalidPassword  liberrors.ErrorCode = "INVALID_PASSWORD"
+)
+
+// Token validation failures, matchable with errors.Is. TokenManager returns
+// them wrapped in an ErrUnauthorized AppError so they map to 401 responses.
+// ErrExpiredToken is distinct from the ErrTokenExpired error code it carries.
+var (
+	ErrExpiredToken      = liberrors.New(ErrTokenExpired, "token has expired")
+	ErrTokenTypeMismatch = liberrors.New(ErrInvalidToken, "token type mismatch")
+	ErrInvalidSignature  = liberrors.New(ErrInvalidToken, "invalid token signature")
 )
 
 // Common error creation functions
@@ -46,7 +57,19 @@ func newPermissionDeniedError(msg string) error {
 	return liberrors.New(ErrPermissionDenied, msg)
 }
 
+func newTokenReusedError() error {
+	return liberrors.New(ErrTokenReused, "refresh token reuse detected")
+}
+
+func newInvalidPasswordError() error {
+	return liberrors.New(ErrInvalidPassword, "password does not match")
+}
+
 // Error wrapping functions
+func wrapUnauthorized(err error, msg string) error {
+	return liberrors.Wrap(err, liberrors.ErrUnauthorized, msg)
+}
+
 func wrapTokenError(err error, msg string) error {
 	return liberrors.Wrap(err, ErrInvalidToken, msg)
 }
@@ -132,3 +155,25 @@ func IsPermissionDeniedError(err error) bool {
 	}
 	return false
 }
+
+func IsTokenReusedError(err error) bool {
+	var appErr *liberrors.AppError
+	for err != nil {
+		if errors.As(err, &appErr) && appErr.Code == ErrTokenReused {
+			return true
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
+
+func IsInvalidPasswordError(err error) bool {
+	var appErr *liberrors.AppError
+	for err != nil {
+		if errors.As(err, &appErr) && appErr.Code == ErrInvalidPassword {
+			return true
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
@@ -11,16 +11,21 @@ import (
 )
 
 // AuthUnaryInterceptor creates a gRPC unary interceptor for JWT authentication
-func AuthUnaryInterceptor(tm *TokenManager) grpc.UnaryServerInterceptor {
+func AuthUnaryInterceptor(tm *TokenManager, opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		logger := o.loggerOr(tm.logger)
+
 		// Extract token from metadata
 		token, err := extractToken(ctx)
 		if err != nil {
+			logger.recordValidation(ctx, err)
 			return nil, err
 		}
 
 		// Validate token
 		claims, err := tm.ValidateAccessToken(token)
+		logger.recordValidation(ctx, err)
 		if err != nil {
 			return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
 		}
@@ -28,20 +33,24 @@ func AuthUnaryInterceptor(tm *TokenManager) grpc.UnaryServerInterceptor {
 		// Add claims to context
 		newCtx := context.WithValue(ctx, UserIDKey, claims.UserID)
 		newCtx = context.WithValue(newCtx, UserRolesKey, claims.Roles)
+		newCtx = context.WithValue(newCtx, UserScopesKey, claims.Scopes())
 
 		return handler(newCtx, req)
 	}
 }
 
 // RBACUnaryInterceptor creates a gRPC unary interceptor for RBAC
-func RBACUnaryInterceptor(rbac *RBAC, resource Resource, action Action) grpc.UnaryServerInterceptor {
+func RBACUnaryInterceptor(rbac *RBAC, resource Resource, action Action, opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		roles, err := GetUserRoles(ctx)
 		if err != nil {
 			return nil, status.Errorf(codes.Unauthenticated, "missing user roles: %v", err)
 		}
 
-		if !rbac.IsAllowed(roles, resource, action) {
+		allowed := rbac.IsAllowed(roles, resource, action)
+		o.loggerOr(rbac.logger).recordPermissionCheck(ctx, roles, resource, action, allowed)
+		if !allowed {
 			return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
 		}
 
@@ -50,16 +59,21 @@ func RBACUnaryInterceptor(rbac *RBAC, resource Resource, action Action) grpc.Una
 }
 
 // AuthStreamInterceptor creates a gRPC stream interceptor for JWT authentication
-func AuthStreamInterceptor(tm *TokenManager) grpc.StreamServerInterceptor {
+func AuthStreamInterceptor(tm *TokenManager, opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		logger := o.loggerOr(tm.logger)
+
 		// Extract token from metadata
 		token, err := extractToken(ss.Context())
 		if err != nil {
+			logger.recordValidation(ss.Context(), err)
 			return err
 		}
 
 		// Validate token
 		claims, err := tm.ValidateAccessToken(token)
+		logger.recordValidation(ss.Context(), err)
 		if err != nil {
 			return status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
 		}
@@ -67,6 +81,7 @@ func AuthStreamInterceptor(tm *TokenManager) grpc.StreamServerInterceptor {
 		// Create new context with claims
 		newCtx := context.WithValue(ss.Context(), UserIDKey, claims.UserID)
 		newCtx = context.WithValue(newCtx, UserRolesKey, claims.Roles)
+		newCtx = context.WithValue(newCtx, UserScopesKey, claims.Scopes())
 
 		// Wrap ServerStream to use new context
 		wrappedStream := &wrappedServerStream{
@@ -79,14 +94,17 @@ func AuthStreamInterceptor(tm *TokenManager) grpc.StreamServerInterceptor {
 }
 
 // RBACStreamInterceptor creates a gRPC stream interceptor for RBAC
-func RBACStreamInterceptor(rbac *RBAC, resource Resource, action Action) grpc.StreamServerInterceptor {
+func RBACStreamInterceptor(rbac *RBAC, resource Resource, action Action, opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		roles, err := GetUserRoles(ss.Context())
 		if err != nil {
 			return status.Errorf(codes.Unauthenticated, "missing user roles: %v", err)
 		}
 
-		if !rbac.IsAllowed(roles, resource, action) {
+		allowed := rbac.IsAllowed(roles, resource, action)
+		o.loggerOr(rbac.logger).recordPermissionCheck(ss.Context(), roles, resource, action, allowed)
+		if !allowed {
 			return status.Error(codes.PermissionDenied, "insufficient permissions")
 		}
 
@@ -94,6 +112,31 @@ func RBACStreamInterceptor(rbac *RBAC, resource Resource, action Action) grpc.St
 	}
 }
 
+// AuthorizedUnaryInterceptor creates a gRPC unary interceptor that authenticates
+// the JWT and then checks the caller's roles for the action on the resource.
+// Unauthenticated calls fail with codes.Unauthenticated and unauthorized ones
+// with codes.PermissionDenied.
+func AuthorizedUnaryInterceptor(tm *TokenManager, rbac *RBAC, resource Resource, action Action, opts ...Option) grpc.UnaryServerInterceptor {
+	authenticate := AuthUnaryInterceptor(tm, opts...)
+	authorize := RBACUnaryInterceptor(rbac, resource, action, opts...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return authenticate(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return authorize(ctx, req, info, handler)
+		})
+	}
+}
+
+// AuthorizedStreamInterceptor is the stream variant of AuthorizedUnaryInterceptor
+func AuthorizedStreamInterceptor(tm *TokenManager, rbac *RBAC, resource Resource, action Action, opts ...Option) grpc.StreamServerInterceptor {
+	authenticate := AuthStreamInterceptor(tm, opts...)
+	authorize := RBACStreamInterceptor(rbac, resource, action, opts...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return authenticate(srv, ss, info, func(srv interface{}, ss grpc.ServerStream) error {
+			return authorize(srv, ss, info, handler)
+		})
+	}
+}
+
 // wrappedServerStream wraps grpc.ServerStream to modify context
 type wrappedServerStream struct {
 	grpc.ServerStream
@@ -291,3 +291,81 @@ func TestRBACStreamInterceptor(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizedInterceptors(t *testing.T) {
+	tm := setupTestInterceptors(t)
+	rbac := NewRBAC()
+	require.NoError(t, rbac.AddRole(RoleUser))
+	require.NoError(t, rbac.AddRole(RoleGuest))
+	require.NoError(t, rbac.AddPermission(RoleUser, BuildPermission(ResourceDocument, ActionRead)))
+
+	withToken := func(roles ...string) context.Context {
+		token, err := tm.GenerateAccessToken("test-user", roles)
+		require.NoError(t, err)
+		md := metadata.New(map[string]string{"authorization": "Bearer " + token})
+		return metadata.NewIncomingContext(context.Background(), md)
+	}
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		expectedCode codes.Code
+	}{
+		{"valid and authorized", withToken("user"), codes.OK},
+		{"valid but unauthorized", withToken("guest"), codes.PermissionDenied},
+		{"invalid token", metadata.NewIncomingContext(context.Background(),
+			metadata.New(map[string]string{"authorization": "Bearer invalid-token"})), codes.Unauthenticated},
+		{"missing token", context.Background(), codes.Unauthenticated},
+	}
+
+	unary := AuthorizedUnaryInterceptor(tm, rbac, ResourceDocument, ActionRead)
+	stream := AuthorizedStreamInterceptor(tm, rbac, ResourceDocument, ActionRead)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := unary(tt.ctx, nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+				userID, err := GetUserID(ctx)
+				require.NoError(t, err)
+				assert.Equal(t, "test-user", userID)
+				return "response", nil
+			})
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+
+			err = stream(nil, &mockServerStream{ctx: tt.ctx}, nil, func(srv interface{}, ss grpc.ServerStream) error {
+				userID, err := GetUserID(ss.Context())
+				require.NoError(t, err)
+				assert.Equal(t, "test-user", userID)
+				return nil
+			})
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestInterceptorAuthLoggerOption(t *testing.T) {
+	tm := setupTestInterceptors(t)
+	rbac := NewRBAC()
+	require.NoError(t, rbac.AddRole(RoleUser))
+
+	// The option overrides the logger set on the RBAC
+	rbac.SetAuthLogger(NewAuthLogger(&recordingSink{}))
+	sink := &recordingSink{}
+	interceptor := RBACUnaryInterceptor(rbac, ResourceDocument, ActionWrite, WithAuthLogger(NewAuthLogger(sink)))
+
+	ctx := context.WithValue(context.Background(), UserIDKey, "test-user")
+	ctx = context.WithValue(ctx, UserRolesKey, []string{"user"})
+	_, err := interceptor(ctx, nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, recordedEvent{
+		event: EventPermissionCheck, userID: "test-user", role: "user",
+		resource: string(ResourceDocument), action: string(ActionWrite), success: false,
+	}, sink.events[0])
+
+	// Without a logger configured the interceptors skip recording
+	_, err = AuthUnaryInterceptor(tm)(context.Background(), nil, nil, nil)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -1,11 +1,15 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/StackCatalyst/common-lib/pkg/metrics"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // TokenType represents the type of token
@@ -24,12 +28,23 @@ type Claims struct {
 	UserID    string    `json:"uid"`
 	Roles     []string  `json:"roles"`
 	TokenType TokenType `json:"type"`
+	// FamilyID identifies the lineage of rotated refresh tokens
+	FamilyID string `json:"fid,omitempty"`
+	// Scope is the space-delimited list of scopes granted to the token
+	Scope string `json:"scope,omitempty"`
+}
+
+// Scopes returns the scopes granted by the scope claim
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
 }
 
 // TokenManager handles JWT token operations
 type TokenManager struct {
-	config  Config
-	metrics *MetricsReporter
+	config      Config
+	metrics     *MetricsReporter
+	revocations RevocationStore
+	logger      *AuthLogger
 }
 
 // NewTokenManager creates a new token manager
@@ -39,13 +54,30 @@ func NewTokenManager(config Config, metricsReporter *metrics.Reporter) (*TokenMa
 	}
 
 	return &TokenManager{
-		config:  config,
-		metrics: NewMetricsReporter(metricsReporter),
+		config:      config,
+		metrics:     NewMetricsReporter(metricsReporter),
+		revocations: NewMemoryRevocationStore(),
 	}, nil
 }
 
+// SetRevocationStore sets the store used to track rotated and revoked refresh tokens
+func (tm *TokenManager) SetRevocationStore(store RevocationStore) {
+	tm.revocations = store
+}
+
+// MetricsReporter returns the reporter recording the manager's token metrics
+func (tm *TokenManager) MetricsReporter() *MetricsReporter {
+	return tm.metrics
+}
+
+// SetAuthLogger sets the logger receiving token validation events from the
+// middleware and interceptors
+func (tm *TokenManager) SetAuthLogger(logger *AuthLogger) {
+	tm.logger = logger
+}
+
 // generateToken creates a new JWT token
-func (tm *TokenManager) generateToken(userID string, roles []string, tokenType TokenType) (string, error) {
+func (tm *TokenManager) generateToken(userID string, roles []string, scope string, tokenType TokenType, familyID string) (string, error) {
 	start := time.Now()
 	var secret string
 	var duration time.Duration
@@ -65,17 +97,23 @@ func (tm *TokenManager) generateToken(userID string, roles []string, tokenType T
 
 	claims := &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 		UserID:    userID,
 		Roles:     roles,
 		TokenType: tokenType,
+		FamilyID:  familyID,
+		Scope:     scope,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(secret))
 	tm.metrics.ObserveTokenGeneration(tokenType, err, time.Since(start))
+	if err == nil {
+		tm.metrics.ObserveTokenSize(tokenType, len(tokenString))
+	}
 	return tokenString, err
 }
 
@@ -103,19 +141,20 @@ func (tm *TokenManager) validateToken(tokenString string, tokenType TokenType) (
 	})
 
 	if err != nil {
+		err = classifyParseError(err)
 		tm.metrics.ObserveTokenValidation(tokenType, err, time.Since(start))
-		return nil, fmt.Errorf("invalid token: %w", err)
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok {
-		err = fmt.Errorf("invalid claims type")
+		err = wrapUnauthorized(newInvalidTokenError("invalid claims type"), "invalid token")
 		tm.metrics.ObserveTokenValidation(tokenType, err, time.Since(start))
 		return nil, err
 	}
 
 	if claims.TokenType != tokenType {
-		err = fmt.Errorf("token type mismatch: expected %s, got %s", tokenType, claims.TokenType)
+		err = wrapUnauthorized(ErrTokenTypeMismatch, fmt.Sprintf("expected %s token, got %s", tokenType, claims.TokenType))
 		tm.metrics.ObserveTokenValidation(tokenType, err, time.Since(start))
 		return nil, err
 	}
@@ -124,14 +163,32 @@ func (tm *TokenManager) validateToken(tokenString string, tokenType TokenType) (
 	return claims, nil
 }
 
+// classifyParseError maps a JWT parse failure to the matching auth error
+func classifyParseError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return wrapUnauthorized(ErrExpiredToken, "invalid token")
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return wrapUnauthorized(ErrInvalidSignature, err.Error())
+	default:
+		return wrapUnauthorized(wrapTokenError(err, "malformed token"), "invalid token")
+	}
+}
+
 // GenerateAccessToken generates a new access token
 func (tm *TokenManager) GenerateAccessToken(userID string, roles []string) (string, error) {
-	return tm.generateToken(userID, roles, AccessToken)
+	return tm.generateToken(userID, roles, "", AccessToken, "")
 }
 
-// GenerateRefreshToken generates a new refresh token
+// GenerateScopedAccessToken generates a new access token carrying the given
+// scopes in its scope claim
+func (tm *TokenManager) GenerateScopedAccessToken(userID string, roles []string, scopes []string) (string, error) {
+	return tm.generateToken(userID, roles, strings.Join(scopes, " "), AccessToken, "")
+}
+
+// GenerateRefreshToken generates a new refresh token starting a new token family
 func (tm *TokenManager) GenerateRefreshToken(userID string, roles []string) (string, error) {
-	return tm.generateToken(userID, roles, RefreshToken)
+	return tm.generateToken(userID, roles, "", RefreshToken, uuid.NewString())
 }
 
 // ValidateAccessToken validates an access token
@@ -143,3 +200,100 @@ func (tm *TokenManager) ValidateAccessToken(tokenString string) (*Claims, error)
 func (tm *TokenManager) ValidateRefreshToken(tokenString string) (*Claims, error) {
 	return tm.validateToken(tokenString, RefreshToken)
 }
+
+// TimeUntilExpiry returns how long an access or refresh token remains valid.
+// The token's signature is verified, but an expired token is not an error: its
+// remaining lifetime is reported as zero.
+func (tm *TokenManager) TimeUntilExpiry(tokenString string) (time.Duration, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		switch claims.TokenType {
+		case AccessToken:
+			return []byte(tm.config.Token.AccessTokenSecret), nil
+		case RefreshToken:
+			return []byte(tm.config.Token.RefreshTokenSecret), nil
+		default:
+			return nil, fmt.Errorf("invalid token type: %s", claims.TokenType)
+		}
+	}, jwt.WithoutClaimsValidation())
+	if err != nil {
+		return 0, classifyParseError(err)
+	}
+
+	if claims.ExpiresAt == nil {
+		return 0, wrapUnauthorized(newInvalidTokenError("token has no expiry"), "invalid token")
+	}
+
+	remaining := time.Until(claims.ExpiresAt.Time)
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+// IsExpiringSoon reports whether a token expires within the given duration.
+// Expired tokens are reported as expiring soon.
+func (tm *TokenManager) IsExpiringSoon(tokenString string, within time.Duration) (bool, error) {
+	remaining, err := tm.TimeUntilExpiry(tokenString)
+	if err != nil {
+		return false, err
+	}
+	return remaining <= within, nil
+}
+
+// Refresh rotates a refresh token, returning a new access token and a new refresh
+// token in the same family. Presenting a refresh token that was already rotated
+// revokes the whole family and returns an error satisfying IsTokenReusedError.
+func (tm *TokenManager) Refresh(ctx context.Context, refreshToken string) (string, string, error) {
+	claims, err := tm.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return "", "", err
+	}
+	if claims.ID == "" || claims.FamilyID == "" {
+		return "", "", wrapUnauthorized(newInvalidTokenError("refresh token has no lineage"), "invalid refresh token")
+	}
+
+	revoked, err := tm.revocations.IsRevoked(ctx, claims.FamilyID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to check token family: %w", err)
+	}
+	if revoked {
+		return "", "", wrapUnauthorized(newInvalidTokenError("refresh token has been revoked"), "invalid refresh token")
+	}
+
+	// Checking and revoking in one step ensures concurrent refreshes of the
+	// same token cannot both succeed
+	used, err := tm.revocations.CheckAndRevoke(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	if used {
+		if err := tm.RevokeFamily(ctx, claims.FamilyID); err != nil {
+			return "", "", err
+		}
+		return "", "", wrapUnauthorized(newTokenReusedError(), "invalid refresh token")
+	}
+
+	accessToken, err := tm.generateToken(claims.UserID, claims.Roles, claims.Scope, AccessToken, "")
+	if err != nil {
+		return "", "", err
+	}
+	newRefreshToken, err := tm.generateToken(claims.UserID, claims.Roles, claims.Scope, RefreshToken, claims.FamilyID)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, newRefreshToken, nil
+}
+
+// RevokeFamily revokes every refresh token in the given family
+func (tm *TokenManager) RevokeFamily(ctx context.Context, familyID string) error {
+	// No token in the family can outlive a refresh token issued now
+	expiresAt := time.Now().Add(tm.config.Token.RefreshTokenDuration)
+	if err := tm.revocations.Revoke(ctx, familyID, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	return nil
+}
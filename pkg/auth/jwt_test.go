@@ -1,10 +1,16 @@
 package auth
 
 import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	liberrors "github.com/StackCatalyst/common-lib/pkg/errors"
 	"github.com/StackCatalyst/common-lib/pkg/metrics"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -133,3 +139,220 @@ func TestTokenValidation(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestTokenValidationErrors(t *testing.T) {
+	tm := setupTestTokenManager(t)
+
+	t.Run("expired token", func(t *testing.T) {
+		expiring := setupTestTokenManager(t)
+		expiring.config.Token.AccessTokenDuration = -time.Minute
+
+		token, err := expiring.GenerateAccessToken("test-user", nil)
+		require.NoError(t, err)
+
+		_, err = tm.ValidateAccessToken(token)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrExpiredToken)
+		assert.True(t, IsTokenExpiredError(err))
+		assert.Equal(t, http.StatusUnauthorized, liberrors.HTTPStatus(err))
+	})
+
+	t.Run("wrong token type", func(t *testing.T) {
+		// A refresh-type token signed with the access secret passes the signature check
+		claims := &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+			UserID:           "test-user",
+			TokenType:        RefreshToken,
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(tm.config.Token.AccessTokenSecret))
+		require.NoError(t, err)
+
+		_, err = tm.ValidateAccessToken(token)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrTokenTypeMismatch)
+		assert.True(t, IsInvalidTokenError(err))
+		assert.Equal(t, http.StatusUnauthorized, liberrors.HTTPStatus(err))
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		token, err := tm.GenerateRefreshToken("test-user", nil)
+		require.NoError(t, err)
+
+		// Refresh tokens are signed with a different secret
+		_, err = tm.ValidateAccessToken(token)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("malformed token", func(t *testing.T) {
+		_, err := tm.ValidateAccessToken("invalid-token")
+		require.Error(t, err)
+		assert.True(t, IsInvalidTokenError(err))
+		assert.NotErrorIs(t, err, ErrExpiredToken)
+		assert.Equal(t, http.StatusUnauthorized, liberrors.HTTPStatus(err))
+	})
+}
+
+func TestTimeUntilExpiry(t *testing.T) {
+	tm := setupTestTokenManager(t)
+
+	t.Run("fresh token", func(t *testing.T) {
+		token, err := tm.GenerateAccessToken("test-user", nil)
+		require.NoError(t, err)
+
+		remaining, err := tm.TimeUntilExpiry(token)
+		require.NoError(t, err)
+		assert.InDelta(t, float64(15*time.Minute), float64(remaining), float64(5*time.Second))
+
+		soon, err := tm.IsExpiringSoon(token, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, soon)
+	})
+
+	t.Run("refresh token", func(t *testing.T) {
+		token, err := tm.GenerateRefreshToken("test-user", nil)
+		require.NoError(t, err)
+
+		remaining, err := tm.TimeUntilExpiry(token)
+		require.NoError(t, err)
+		assert.Greater(t, remaining, 23*time.Hour)
+	})
+
+	t.Run("nearly expired token", func(t *testing.T) {
+		expiring := setupTestTokenManager(t)
+		expiring.config.Token.AccessTokenDuration = 30 * time.Second
+
+		token, err := expiring.GenerateAccessToken("test-user", nil)
+		require.NoError(t, err)
+
+		remaining, err := tm.TimeUntilExpiry(token)
+		require.NoError(t, err)
+		assert.Greater(t, remaining, time.Duration(0))
+		assert.LessOrEqual(t, remaining, 30*time.Second)
+
+		soon, err := tm.IsExpiringSoon(token, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, soon)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		expired := setupTestTokenManager(t)
+		expired.config.Token.AccessTokenDuration = -time.Minute
+
+		token, err := expired.GenerateAccessToken("test-user", nil)
+		require.NoError(t, err)
+
+		remaining, err := tm.TimeUntilExpiry(token)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), remaining)
+
+		soon, err := tm.IsExpiringSoon(token, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, soon)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, err := tm.TimeUntilExpiry("invalid-token")
+		assert.True(t, IsInvalidTokenError(err))
+
+		other := setupTestTokenManager(t)
+		other.config.Token.AccessTokenSecret = "other-secret"
+		token, err := other.GenerateAccessToken("test-user", nil)
+		require.NoError(t, err)
+		_, err = tm.IsExpiringSoon(token, time.Minute)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	userID := "test-user"
+	roles := []string{"user"}
+
+	t.Run("rotates within family", func(t *testing.T) {
+		tm := setupTestTokenManager(t)
+
+		refreshToken, err := tm.GenerateRefreshToken(userID, roles)
+		require.NoError(t, err)
+		original, err := tm.ValidateRefreshToken(refreshToken)
+		require.NoError(t, err)
+
+		accessToken, rotated, err := tm.Refresh(ctx, refreshToken)
+		require.NoError(t, err)
+
+		claims, err := tm.ValidateAccessToken(accessToken)
+		require.NoError(t, err)
+		assert.Equal(t, userID, claims.UserID)
+
+		next, err := tm.ValidateRefreshToken(rotated)
+		require.NoError(t, err)
+		assert.Equal(t, original.FamilyID, next.FamilyID)
+		assert.NotEqual(t, original.ID, next.ID)
+	})
+
+	t.Run("stolen token replay revokes family", func(t *testing.T) {
+		tm := setupTestTokenManager(t)
+
+		stolen, err := tm.GenerateRefreshToken(userID, roles)
+		require.NoError(t, err)
+
+		// The legitimate client rotates the token twice
+		_, legit, err := tm.Refresh(ctx, stolen)
+		require.NoError(t, err)
+		_, legit, err = tm.Refresh(ctx, legit)
+		require.NoError(t, err)
+
+		// The attacker replays the stolen, already-rotated token
+		_, _, err = tm.Refresh(ctx, stolen)
+		require.Error(t, err)
+		assert.True(t, IsTokenReusedError(err))
+		assert.Equal(t, http.StatusUnauthorized, liberrors.HTTPStatus(err))
+
+		// The legitimate client's descendant token is now revoked too
+		_, _, err = tm.Refresh(ctx, legit)
+		require.Error(t, err)
+		assert.True(t, IsInvalidTokenError(err))
+		assert.False(t, IsTokenReusedError(err))
+		assert.Equal(t, http.StatusUnauthorized, liberrors.HTTPStatus(err))
+
+		// Other families are unaffected
+		other, err := tm.GenerateRefreshToken(userID, roles)
+		require.NoError(t, err)
+		_, _, err = tm.Refresh(ctx, other)
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrent refreshes rotate once", func(t *testing.T) {
+		tm := setupTestTokenManager(t)
+
+		refreshToken, err := tm.GenerateRefreshToken(userID, roles)
+		require.NoError(t, err)
+
+		const attempts = 10
+		var (
+			wg        sync.WaitGroup
+			succeeded atomic.Int32
+		)
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, _, err := tm.Refresh(ctx, refreshToken); err == nil {
+					succeeded.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), succeeded.Load())
+	})
+
+	t.Run("access token rejected", func(t *testing.T) {
+		tm := setupTestTokenManager(t)
+
+		accessToken, err := tm.GenerateAccessToken(userID, roles)
+		require.NoError(t, err)
+		_, _, err = tm.Refresh(ctx, accessToken)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/StackCatalyst/common-lib/pkg/web"
+	"github.com/gin-gonic/gin"
+)
+
+// LimitBody creates a Gin middleware rejecting request bodies larger than
+// maxBytes. Requests declaring a larger Content-Length get a 413 immediately;
+// other bodies are wrapped with http.MaxBytesReader, so handlers reading past
+// the limit get an *http.MaxBytesError. If the handler hits the limit and
+// writes no response, or records the error with c.Error, the middleware
+// responds 413 itself.
+func LimitBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			abortTooLarge(c)
+			return
+		}
+
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)}
+		c.Request.Body = body
+		c.Next()
+
+		if c.Writer.Written() {
+			return
+		}
+		if body.exceeded || tooLarge(c.Errors) {
+			abortTooLarge(c)
+		}
+	}
+}
+
+// limitedBody records whether a read from the wrapped body hit the limit
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// tooLarge reports whether any of errs is an *http.MaxBytesError
+func tooLarge(errs []*gin.Error) bool {
+	var maxBytesErr *http.MaxBytesError
+	for _, err := range errs {
+		if errors.As(err.Err, &maxBytesErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// abortTooLarge responds 413 with the standard error body
+func abortTooLarge(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, web.ErrorResponse{
+		Error: web.ErrorBody{Code: "PAYLOAD_TOO_LARGE", Message: "request body too large"},
+	})
+}
+
+// RequireContentType creates a Gin middleware rejecting requests with a body
+// whose media type is not one of types with a 415. Parameters such as charset
+// are ignored and requests without a body are let through.
+func RequireContentType(types ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(types))
+	for _, t := range types {
+		allowed[strings.ToLower(t)] = true
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.ContentLength == 0 {
+			c.Next()
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		if err != nil || !allowed[mediaType] {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, web.ErrorResponse{
+				Error: web.ErrorBody{Code: "UNSUPPORTED_MEDIA_TYPE", Message: "unsupported content type"},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
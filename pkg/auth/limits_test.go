@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/upload", LimitBody(10), func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.String(http.StatusOK, string(body))
+	})
+
+	tests := []struct {
+		name    string
+		body    string
+		chunked bool
+		status  int
+	}{
+		{"under limit", "small", false, http.StatusOK},
+		{"at limit", strings.Repeat("a", 10), false, http.StatusOK},
+		{"over limit", strings.Repeat("a", 11), false, http.StatusRequestEntityTooLarge},
+		{"chunked at limit", strings.Repeat("a", 10), true, http.StatusOK},
+		{"chunked over limit", strings.Repeat("a", 11), true, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(tt.body))
+			if tt.chunked {
+				// An unknown length bypasses the Content-Length check
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLimitBodyUnhandledOverflow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(LimitBody(10))
+	r.POST("/ignored", func(c *gin.Context) {
+		// The handler drops the read error and writes nothing
+		io.ReadAll(c.Request.Body)
+	})
+	r.POST("/recorded", func(c *gin.Context) {
+		var body map[string]string
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err)
+		}
+	})
+	r.POST("/written", func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+		}
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"read error ignored", "/ignored", http.StatusRequestEntityTooLarge},
+		{"read error recorded", "/recorded", http.StatusRequestEntityTooLarge},
+		{"handler response kept", "/written", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"name":"` + strings.Repeat("a", 20) + `"}`
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(body))
+			req.ContentLength = -1
+			req.TransferEncoding = []string{"chunked"}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusRequestEntityTooLarge {
+				assert.Contains(t, w.Body.String(), "PAYLOAD_TOO_LARGE")
+			}
+		})
+	}
+}
+
+func TestRequireContentType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/modules", RequireContentType("application/json"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"matching type", "application/json", "{}", http.StatusOK},
+		{"type with parameters", "Application/JSON; charset=utf-8", "{}", http.StatusOK},
+		{"wrong type", "text/plain", "{}", http.StatusUnsupportedMediaType},
+		{"missing type", "", "{}", http.StatusUnsupportedMediaType},
+		{"malformed type", "application/json;;", "{}", http.StatusUnsupportedMediaType},
+		{"no body", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/modules", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"strings"
 
 	"github.com/StackCatalyst/common-lib/pkg/logging"
 	"go.uber.org/zap"
@@ -24,20 +25,85 @@ const (
 	EventRoleRevocation  LogEvent = "role_revocation"
 )
 
-// AuthLogger wraps the common logger with auth-specific functionality
+// AuthEventSink receives authentication and authorization events, allowing
+// them to be routed to any backend such as a log or a SIEM
+type AuthEventSink interface {
+	// TokenValidation is called when a token is validated
+	TokenValidation(ctx context.Context, success bool, err error)
+	// TokenCreation is called when a token is created
+	TokenCreation(ctx context.Context, userID string, success bool, err error)
+	// PermissionCheck is called when an authorization decision is made
+	PermissionCheck(ctx context.Context, userID, role, resource, action string, allowed bool)
+	// RoleChange is called when a role is assigned or revoked
+	RoleChange(ctx context.Context, userID, role string, assigned bool, err error)
+}
+
+// AuthLogger records auth events to an AuthEventSink
 type AuthLogger struct {
+	sink AuthEventSink
+}
+
+// NewAuthLogger creates a new AuthLogger instance. A nil sink logs events
+// with the default logger.
+func NewAuthLogger(sink AuthEventSink) *AuthLogger {
+	if sink == nil {
+		sink = NewLoggingEventSink(logging.LoggerFromContext(context.Background()))
+	}
+	return &AuthLogger{sink: sink}
+}
+
+// LogTokenValidation logs token validation events
+func (l *AuthLogger) LogTokenValidation(ctx context.Context, success bool, err error) {
+	l.sink.TokenValidation(ctx, success, err)
+}
+
+// LogTokenCreation logs token creation events
+func (l *AuthLogger) LogTokenCreation(ctx context.Context, userID string, success bool, err error) {
+	l.sink.TokenCreation(ctx, userID, success, err)
+}
+
+// LogPermissionCheck logs authorization check events
+func (l *AuthLogger) LogPermissionCheck(ctx context.Context, userID, role, resource, action string, allowed bool) {
+	l.sink.PermissionCheck(ctx, userID, role, resource, action, allowed)
+}
+
+// LogRoleChange logs role assignment or revocation events
+func (l *AuthLogger) LogRoleChange(ctx context.Context, userID, role string, assigned bool, err error) {
+	l.sink.RoleChange(ctx, userID, role, assigned, err)
+}
+
+// recordValidation reports a token validation outcome. It is a no-op on a nil
+// logger so unconfigured middleware pays nothing.
+func (l *AuthLogger) recordValidation(ctx context.Context, err error) {
+	if l != nil {
+		l.LogTokenValidation(ctx, err == nil, err)
+	}
+}
+
+// recordPermissionCheck reports an authorization decision for the user in ctx.
+// It is a no-op on a nil logger.
+func (l *AuthLogger) recordPermissionCheck(ctx context.Context, userRoles []string, resource Resource, action Action, allowed bool) {
+	if l == nil {
+		return
+	}
+	userID, _ := ctx.Value(UserIDKey).(string)
+	l.LogPermissionCheck(ctx, userID, strings.Join(userRoles, ","), string(resource), string(action), allowed)
+}
+
+// LoggingEventSink is an AuthEventSink writing structured log entries
+type LoggingEventSink struct {
 	logger *logging.Logger
 }
 
-// NewAuthLogger creates a new AuthLogger instance
-func NewAuthLogger(logger *logging.Logger) *AuthLogger {
-	return &AuthLogger{
+// NewLoggingEventSink creates a sink logging auth events with the given logger
+func NewLoggingEventSink(logger *logging.Logger) *LoggingEventSink {
+	return &LoggingEventSink{
 		logger: logger.With(zap.String("component", "auth")),
 	}
 }
 
 // logAuthEvent logs an authentication event with structured data
-func (l *AuthLogger) logAuthEvent(ctx context.Context, event LogEvent, level logging.Level, msg string, fields ...zapcore.Field) {
+func (l *LoggingEventSink) logAuthEvent(ctx context.Context, event LogEvent, level logging.Level, msg string, fields ...zapcore.Field) {
 	// Add event type to fields
 	fields = append(fields, zap.String("event", string(event)))
 
@@ -57,8 +123,8 @@ func (l *AuthLogger) logAuthEvent(ctx context.Context, event LogEvent, level log
 	}
 }
 
-// LogTokenValidation logs token validation events
-func (l *AuthLogger) LogTokenValidation(ctx context.Context, success bool, err error) {
+// TokenValidation logs token validation events
+func (l *LoggingEventSink) TokenValidation(ctx context.Context, success bool, err error) {
 	fields := []zapcore.Field{
 		zap.Bool("success", success),
 	}
@@ -76,8 +142,8 @@ func (l *AuthLogger) LogTokenValidation(ctx context.Context, success bool, err e
 	l.logAuthEvent(ctx, EventTokenValidation, level, msg, fields...)
 }
 
-// LogTokenCreation logs token creation events
-func (l *AuthLogger) LogTokenCreation(ctx context.Context, userID string, success bool, err error) {
+// TokenCreation logs token creation events
+func (l *LoggingEventSink) TokenCreation(ctx context.Context, userID string, success bool, err error) {
 	fields := []zapcore.Field{
 		zap.String("user_id", userID),
 		zap.Bool("success", success),
@@ -96,8 +162,8 @@ func (l *AuthLogger) LogTokenCreation(ctx context.Context, userID string, succes
 	l.logAuthEvent(ctx, EventTokenCreation, level, msg, fields...)
 }
 
-// LogPermissionCheck logs authorization check events
-func (l *AuthLogger) LogPermissionCheck(ctx context.Context, userID, role, resource, action string, allowed bool) {
+// PermissionCheck logs authorization check events
+func (l *LoggingEventSink) PermissionCheck(ctx context.Context, userID, role, resource, action string, allowed bool) {
 	fields := []zapcore.Field{
 		zap.String("user_id", userID),
 		zap.String("role", role),
@@ -115,8 +181,8 @@ func (l *AuthLogger) LogPermissionCheck(ctx context.Context, userID, role, resou
 	l.logAuthEvent(ctx, EventPermissionCheck, level, msg, fields...)
 }
 
-// LogRoleChange logs role assignment or revocation events
-func (l *AuthLogger) LogRoleChange(ctx context.Context, userID, role string, assigned bool, err error) {
+// RoleChange logs role assignment or revocation events
+func (l *LoggingEventSink) RoleChange(ctx context.Context, userID, role string, assigned bool, err error) {
 	fields := []zapcore.Field{
 		zap.String("user_id", userID),
 		zap.String("role", role),
@@ -4,18 +4,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	loggingtest "github.com/StackCatalyst/common-lib/pkg/logging/testing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func setupTestLogger(t *testing.T) (*AuthLogger, *bytes.Buffer) {
 	var buf bytes.Buffer
 	logger, err := loggingtest.NewTestLogger(&buf)
 	require.NoError(t, err)
-	return NewAuthLogger(logger), &buf
+	return NewAuthLogger(NewLoggingEventSink(logger)), &buf
 }
 
 func TestLogTokenValidation(t *testing.T) {
@@ -190,3 +196,162 @@ func TestLogRoleChange(t *testing.T) {
 		})
 	}
 }
+
+// recordedEvent is an auth event captured by recordingSink
+type recordedEvent struct {
+	event    LogEvent
+	userID   string
+	role     string
+	resource string
+	action   string
+	success  bool
+	err      error
+}
+
+// recordingSink is an AuthEventSink recording every event it receives
+type recordingSink struct {
+	events []recordedEvent
+}
+
+func (s *recordingSink) TokenValidation(ctx context.Context, success bool, err error) {
+	s.events = append(s.events, recordedEvent{event: EventTokenValidation, success: success, err: err})
+}
+
+func (s *recordingSink) TokenCreation(ctx context.Context, userID string, success bool, err error) {
+	s.events = append(s.events, recordedEvent{event: EventTokenCreation, userID: userID, success: success, err: err})
+}
+
+func (s *recordingSink) PermissionCheck(ctx context.Context, userID, role, resource, action string, allowed bool) {
+	s.events = append(s.events, recordedEvent{
+		event: EventPermissionCheck, userID: userID, role: role, resource: resource, action: action, success: allowed,
+	})
+}
+
+func (s *recordingSink) RoleChange(ctx context.Context, userID, role string, assigned bool, err error) {
+	event := EventRoleAssignment
+	if !assigned {
+		event = EventRoleRevocation
+	}
+	s.events = append(s.events, recordedEvent{event: event, userID: userID, role: role, success: err == nil, err: err})
+}
+
+func TestAuthLoggerSink(t *testing.T) {
+	sink := &recordingSink{}
+	logger := NewAuthLogger(sink)
+	ctx := context.Background()
+
+	logger.LogTokenValidation(ctx, true, nil)
+	logger.LogTokenCreation(ctx, "user123", true, nil)
+	logger.LogPermissionCheck(ctx, "user123", "admin", "users", "read", true)
+	logger.LogRoleChange(ctx, "user123", "admin", false, nil)
+
+	require.Len(t, sink.events, 4)
+	assert.Equal(t, EventTokenValidation, sink.events[0].event)
+	assert.Equal(t, EventTokenCreation, sink.events[1].event)
+	assert.Equal(t, EventPermissionCheck, sink.events[2].event)
+	assert.Equal(t, EventRoleRevocation, sink.events[3].event)
+
+	// A nil sink falls back to logging
+	assert.IsType(t, &LoggingEventSink{}, NewAuthLogger(nil).sink)
+}
+
+func TestAuthEventsFromMiddleware(t *testing.T) {
+	tm, rbac := setupTestMiddleware(t)
+	require.NoError(t, rbac.AddRole("admin"))
+	require.NoError(t, rbac.AddPermission("admin", BuildPermission("users", "read")))
+
+	sink := &recordingSink{}
+	tm.SetAuthLogger(NewAuthLogger(sink))
+	rbac.SetAuthLogger(NewAuthLogger(sink))
+	router := setupTestRouter(tm, rbac, t)
+
+	token, err := tm.GenerateAccessToken("user123", []string{"admin"})
+	require.NoError(t, err)
+
+	serve := func(path, header string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			req.Header.Set(AuthHeaderKey, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("token validation", func(t *testing.T) {
+		sink.events = nil
+		assert.Equal(t, http.StatusOK, serve("/protected", "Bearer "+token))
+		assert.Equal(t, http.StatusUnauthorized, serve("/protected", "Bearer invalid"))
+		assert.Equal(t, http.StatusUnauthorized, serve("/protected", ""))
+
+		require.Len(t, sink.events, 3)
+		assert.Equal(t, recordedEvent{event: EventTokenValidation, success: true}, sink.events[0])
+		assert.False(t, sink.events[1].success)
+		assert.Error(t, sink.events[1].err)
+		assert.False(t, sink.events[2].success)
+		assert.True(t, IsMissingTokenError(sink.events[2].err))
+	})
+
+	t.Run("permission check", func(t *testing.T) {
+		sink.events = nil
+		assert.Equal(t, http.StatusForbidden, serve("/users/write", "Bearer "+token))
+
+		require.Len(t, sink.events, 2)
+		assert.Equal(t, EventTokenValidation, sink.events[0].event)
+		assert.Equal(t, recordedEvent{
+			event: EventPermissionCheck, userID: "user123", role: "admin", resource: "users", action: "write", success: false,
+		}, sink.events[1])
+	})
+}
+
+func TestAuthEventsFromInterceptors(t *testing.T) {
+	tm := setupTestInterceptors(t)
+	rbac := NewRBAC()
+	require.NoError(t, rbac.AddRole(RoleUser))
+	require.NoError(t, rbac.AddPermission(RoleUser, BuildPermission(ResourceDocument, ActionRead)))
+
+	sink := &recordingSink{}
+	tm.SetAuthLogger(NewAuthLogger(sink))
+	rbac.SetAuthLogger(NewAuthLogger(sink))
+
+	token, err := tm.GenerateAccessToken("test-user", []string{"user"})
+	require.NoError(t, err)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{
+		"authorization": "Bearer " + token,
+	}))
+
+	// Chain authentication and authorization as a server would
+	rbacInterceptor := RBACUnaryInterceptor(rbac, ResourceDocument, ActionRead)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return rbacInterceptor(ctx, req, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "response", nil
+		})
+	}
+
+	t.Run("unary", func(t *testing.T) {
+		sink.events = nil
+		_, err := AuthUnaryInterceptor(tm)(ctx, nil, nil, handler)
+		require.NoError(t, err)
+
+		require.Len(t, sink.events, 2)
+		assert.Equal(t, recordedEvent{event: EventTokenValidation, success: true}, sink.events[0])
+		assert.Equal(t, recordedEvent{
+			event: EventPermissionCheck, userID: "test-user", role: "user",
+			resource: string(ResourceDocument), action: string(ActionRead), success: true,
+		}, sink.events[1])
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		sink.events = nil
+		stream := &mockServerStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.MD{})}
+		err := AuthStreamInterceptor(tm)(nil, stream, nil, func(srv interface{}, ss grpc.ServerStream) error {
+			return nil
+		})
+		require.Error(t, err)
+
+		require.Len(t, sink.events, 1)
+		assert.Equal(t, EventTokenValidation, sink.events[0].event)
+		assert.False(t, sink.events[0].success)
+		assert.Equal(t, codes.Unauthenticated, status.Code(sink.events[0].err))
+	})
+}
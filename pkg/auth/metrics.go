@@ -12,8 +12,10 @@ type MetricsReporter struct {
 	tokenValidations  *prometheus.CounterVec
 	tokenGenerations  *prometheus.CounterVec
 	permissionChecks  *prometheus.CounterVec
+	authzDecisions    *prometheus.CounterVec
 	validationLatency *prometheus.HistogramVec
 	generationLatency *prometheus.HistogramVec
+	tokenSize         *prometheus.HistogramVec
 	activeTokens      *prometheus.GaugeVec
 }
 
@@ -35,6 +37,11 @@ func NewMetricsReporter(reporter *metrics.Reporter) *MetricsReporter {
 			"Total number of permission checks",
 			[]string{"resource", "action", "status"},
 		),
+		authzDecisions: reporter.Counter(
+			"auth_authorization_decisions_total",
+			"Total number of RBAC authorization decisions",
+			[]string{"resource", "action", "result"},
+		),
 		validationLatency: reporter.Histogram(
 			"auth_token_validation_duration_seconds",
 			"Token validation duration in seconds",
@@ -47,6 +54,13 @@ func NewMetricsReporter(reporter *metrics.Reporter) *MetricsReporter {
 			[]string{"type"},
 			[]float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
 		),
+		tokenSize: reporter.Histogram(
+			"auth_token_size_bytes",
+			"Size of signed tokens in bytes",
+			[]string{"type"},
+			// Common proxy header limits are 4KB to 16KB
+			[]float64{256, 512, 1024, 2048, 4096, 6144, 8192, 16384},
+		),
 		activeTokens: reporter.Gauge(
 			"auth_active_tokens",
 			"Number of active tokens",
@@ -75,6 +89,11 @@ func (m *MetricsReporter) ObserveTokenGeneration(tokenType TokenType, err error,
 	m.generationLatency.WithLabelValues(string(tokenType)).Observe(duration.Seconds())
 }
 
+// ObserveTokenSize records the length of a signed token
+func (m *MetricsReporter) ObserveTokenSize(tokenType TokenType, size int) {
+	m.tokenSize.WithLabelValues(string(tokenType)).Observe(float64(size))
+}
+
 // ObservePermissionCheck records a permission check attempt
 func (m *MetricsReporter) ObservePermissionCheck(resource Resource, action Action, err error) {
 	status := "allowed"
@@ -84,6 +103,15 @@ func (m *MetricsReporter) ObservePermissionCheck(resource Resource, action Actio
 	m.permissionChecks.WithLabelValues(string(resource), string(action), status).Inc()
 }
 
+// ObserveAuthorizationDecision records an RBAC allow or deny decision
+func (m *MetricsReporter) ObserveAuthorizationDecision(resource Resource, action Action, allowed bool) {
+	result := "allowed"
+	if !allowed {
+		result = "denied"
+	}
+	m.authzDecisions.WithLabelValues(string(resource), string(action), result).Inc()
+}
+
 // SetActiveTokens sets the number of active tokens
 func (m *MetricsReporter) SetActiveTokens(tokenType TokenType, count int) {
 	m.activeTokens.WithLabelValues(string(tokenType)).Set(float64(count))
@@ -1,12 +1,19 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/StackCatalyst/common-lib/pkg/metrics"
+	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -53,3 +60,106 @@ func TestAuthMetricsReporter(t *testing.T) {
 		metricsReporter.SetActiveTokens(RefreshToken, 50)
 	})
 }
+
+func TestAuthorizationDecisionMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metricsReporter := NewMetricsReporter(metrics.New(metrics.Options{
+		Namespace: "test",
+		Subsystem: "auth",
+		Registry:  registry,
+	}))
+
+	rbac := NewRBAC()
+	rbac.SetMetricsReporter(metricsReporter)
+	require.NoError(t, rbac.AddRole(RoleUser))
+	require.NoError(t, rbac.AddPermission(RoleUser, BuildPermission(ResourceDocument, ActionRead)))
+
+	assert.True(t, rbac.IsAllowed([]string{"user"}, ResourceDocument, ActionRead))
+	// Memoized decisions are counted too
+	assert.True(t, rbac.IsAllowed([]string{"user"}, ResourceDocument, ActionRead))
+	assert.False(t, rbac.IsAllowed([]string{"user"}, ResourceDocument, ActionDelete))
+
+	// The middleware records its decision through IsAllowed
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.DELETE("/documents", func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), UserRolesKey, []string{"user"})
+		c.Request = c.Request.WithContext(ctx)
+	}, RequirePermission(rbac, ResourceDocument, ActionDelete), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/documents", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	decisions := make(map[string]float64)
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "test_auth_auth_authorization_decisions_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			var labels []string
+			for _, label := range m.GetLabel() {
+				labels = append(labels, label.GetValue())
+			}
+			decisions[strings.Join(labels, "/")] = m.GetCounter().GetValue()
+		}
+	}
+
+	// Labels are reported in name order: action, resource, result
+	assert.Equal(t, map[string]float64{
+		"read/document/allowed":  2,
+		"delete/document/denied": 2,
+	}, decisions)
+}
+
+func TestTokenSizeMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reporter := metrics.New(metrics.Options{
+		Namespace: "test",
+		Subsystem: "auth",
+		Registry:  registry,
+	})
+	cfg := DefaultConfig()
+	cfg.Token.AccessTokenSecret = "test-access-secret"
+	cfg.Token.RefreshTokenSecret = "test-refresh-secret"
+	tm, err := NewTokenManager(cfg, reporter)
+	require.NoError(t, err)
+
+	// observedSize returns the size recorded by the latest token generation
+	var lastSum float64
+	observedSize := func() float64 {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		for _, family := range families {
+			if family.GetName() != "test_auth_auth_token_size_bytes" {
+				continue
+			}
+			sum := family.GetMetric()[0].GetHistogram().GetSampleSum()
+			size := sum - lastSum
+			lastSum = sum
+			return size
+		}
+		t.Fatal("token size histogram not found")
+		return 0
+	}
+
+	small, err := tm.GenerateAccessToken("test-user", []string{"user"})
+	require.NoError(t, err)
+	smallSize := observedSize()
+	assert.Equal(t, float64(len(small)), smallSize)
+
+	roles := make([]string, 200)
+	for i := range roles {
+		roles[i] = fmt.Sprintf("team-%03d-reader", i)
+	}
+	large, err := tm.GenerateAccessToken("test-user", roles)
+	require.NoError(t, err)
+	largeSize := observedSize()
+	assert.Equal(t, float64(len(large)), largeSize)
+
+	assert.Greater(t, largeSize, smallSize)
+	assert.Greater(t, largeSize, float64(4096), "many roles should push the token past common header limits")
+}
@@ -2,10 +2,10 @@ package auth
 
 import (
 	"context"
-	"net/http"
 	"strings"
 
 	"github.com/StackCatalyst/common-lib/pkg/errors"
+	"github.com/StackCatalyst/common-lib/pkg/web"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,6 +16,8 @@ const (
 	UserIDKey contextKey = "user_id"
 	// UserRolesKey is the context key for user roles
 	UserRolesKey contextKey = "user_roles"
+	// UserScopesKey is the context key for the scopes granted by the token
+	UserScopesKey contextKey = "user_scopes"
 	// AuthHeaderKey is the authorization header key
 	AuthHeaderKey = "Authorization"
 	// BearerSchema is the bearer token schema
@@ -23,38 +25,41 @@ const (
 )
 
 // AuthMiddleware creates a Gin middleware for JWT authentication
-func AuthMiddleware(tm *TokenManager) gin.HandlerFunc {
+func AuthMiddleware(tm *TokenManager, opts ...Option) gin.HandlerFunc {
+	o := newOptions(opts)
 	return func(c *gin.Context) {
+		logger := o.loggerOr(tm.logger)
+
 		// Extract token from header
 		authHeader := c.GetHeader(AuthHeaderKey)
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "no authorization header",
-			})
+			err := newMissingTokenError()
+			logger.recordValidation(c.Request.Context(), err)
+			web.WriteError(c, wrapUnauthorized(err, "no authorization header"))
 			return
 		}
 
 		// Check bearer schema
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != BearerSchema {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "invalid authorization header format",
-			})
+			err := newInvalidTokenError("invalid authorization header format")
+			logger.recordValidation(c.Request.Context(), err)
+			web.WriteError(c, wrapUnauthorized(err, "invalid authorization header format"))
 			return
 		}
 
 		// Validate token
 		claims, err := tm.ValidateAccessToken(parts[1])
+		logger.recordValidation(c.Request.Context(), err)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": err.Error(),
-			})
+			web.WriteError(c, err)
 			return
 		}
 
 		// Set claims in context
 		ctx := context.WithValue(c.Request.Context(), UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, UserRolesKey, claims.Roles)
+		ctx = context.WithValue(ctx, UserScopesKey, claims.Scopes())
 		c.Request = c.Request.WithContext(ctx)
 
 		c.Next()
@@ -66,16 +71,12 @@ func RequireRole(rbac *RBAC, role Role) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRoles, exists := c.Request.Context().Value(UserRolesKey).([]string)
 		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "user roles not found in context",
-			})
+			web.WriteError(c, errors.New(errors.ErrUnauthorized, "user roles not found in context"))
 			return
 		}
 
 		if !rbac.HasRole(userRoles, role) {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-				"error": "insufficient permissions",
-			})
+			web.WriteError(c, errors.New(errors.ErrForbidden, "insufficient permissions"))
 			return
 		}
 
@@ -84,20 +85,19 @@ func RequireRole(rbac *RBAC, role Role) gin.HandlerFunc {
 }
 
 // RequirePermission creates a Gin middleware for permission-based authorization
-func RequirePermission(rbac *RBAC, resource Resource, action Action) gin.HandlerFunc {
+func RequirePermission(rbac *RBAC, resource Resource, action Action, opts ...Option) gin.HandlerFunc {
+	o := newOptions(opts)
 	return func(c *gin.Context) {
 		userRoles, exists := c.Request.Context().Value(UserRolesKey).([]string)
 		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "user roles not found in context",
-			})
+			web.WriteError(c, errors.New(errors.ErrUnauthorized, "user roles not found in context"))
 			return
 		}
 
-		if !rbac.IsAllowed(userRoles, resource, action) {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-				"error": "insufficient permissions",
-			})
+		allowed := rbac.IsAllowed(userRoles, resource, action)
+		o.loggerOr(rbac.logger).recordPermissionCheck(c.Request.Context(), userRoles, resource, action, allowed)
+		if !allowed {
+			web.WriteError(c, errors.New(errors.ErrForbidden, "insufficient permissions"))
 			return
 		}
 
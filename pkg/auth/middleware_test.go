@@ -2,10 +2,12 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/StackCatalyst/common-lib/pkg/web"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -227,6 +229,83 @@ func TestPermissionMiddleware(t *testing.T) {
 	}
 }
 
+func TestPermissionMiddlewareAuditLog(t *testing.T) {
+	tm, rbac := setupTestMiddleware(t)
+	require.NoError(t, rbac.AddRole("user"))
+	require.NoError(t, rbac.AddPermission("user", BuildPermission("users", "read")))
+
+	logger, buf := setupTestLogger(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/users/write",
+		AuthMiddleware(tm, WithAuthLogger(logger)),
+		RequirePermission(rbac, "users", "write", WithAuthLogger(logger)),
+		func(c *gin.Context) { c.Status(http.StatusOK) },
+	)
+
+	token, err := tm.GenerateAccessToken("user123", []string{"user"})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/users/write", nil)
+	req.Header.Set(AuthHeaderKey, "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	decoder := json.NewDecoder(buf)
+	var validation, check map[string]interface{}
+	require.NoError(t, decoder.Decode(&validation))
+	require.NoError(t, decoder.Decode(&check))
+
+	assert.Equal(t, "token_validation", validation["event"])
+	assert.Equal(t, true, validation["success"])
+
+	assert.Equal(t, "permission_check", check["event"])
+	assert.Equal(t, false, check["allowed"])
+	assert.Equal(t, "user123", check["user_id"])
+	assert.Equal(t, "user", check["role"])
+	assert.Equal(t, "users", check["resource"])
+	assert.Equal(t, "write", check["action"])
+}
+
+func TestMiddlewareErrorResponse(t *testing.T) {
+	tm, rbac := setupTestMiddleware(t)
+	require.NoError(t, rbac.AddRole("user"))
+	router := setupTestRouter(tm, rbac, t)
+
+	token, err := tm.GenerateAccessToken("user123", []string{"user"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		status int
+		code   string
+	}{
+		{"missing header", "/protected", "", http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"invalid token", "/protected", "Bearer invalid-token", http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"insufficient permissions", "/users/write", "Bearer " + token, http.StatusForbidden, "FORBIDDEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(AuthHeaderKey, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			var resp web.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.code, resp.Error.Code)
+			assert.NotEmpty(t, resp.Error.Message)
+		})
+	}
+}
+
 func TestContextHelpers(t *testing.T) {
 	// Test GetUserID
 	t.Run("get user id", func(t *testing.T) {
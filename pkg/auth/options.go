@@ -0,0 +1,34 @@
+package auth
+
+// Option configures the auth middleware and interceptors
+type Option func(*options)
+
+// options holds the settings applied by Option
+type options struct {
+	logger *AuthLogger
+}
+
+// WithAuthLogger records token validations and permission checks with logger,
+// overriding any logger set with TokenManager.SetAuthLogger or RBAC.SetAuthLogger
+func WithAuthLogger(logger *AuthLogger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// newOptions applies opts to the default options
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// loggerOr returns the configured logger, or fallback if none was set
+func (o options) loggerOr(fallback *AuthLogger) *AuthLogger {
+	if o.logger != nil {
+		return o.logger
+	}
+	return fallback
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordAlgorithm is the algorithm used to hash passwords
+type PasswordAlgorithm string
+
+const (
+	// PasswordBcrypt hashes passwords with bcrypt
+	PasswordBcrypt PasswordAlgorithm = "bcrypt"
+	// PasswordArgon2id hashes passwords with argon2id
+	PasswordArgon2id PasswordAlgorithm = "argon2id"
+)
+
+// argon2idPrefix identifies hashes in the PHC string format produced by argon2id
+const argon2idPrefix = "$argon2id$"
+
+// PasswordConfig holds password hashing settings
+type PasswordConfig struct {
+	// Algorithm is the algorithm used for new hashes
+	Algorithm PasswordAlgorithm `json:"algorithm" yaml:"algorithm"`
+	// BcryptCost is the bcrypt work factor
+	BcryptCost int `json:"bcrypt_cost" yaml:"bcrypt_cost"`
+	// Argon2Time is the number of argon2id passes over memory
+	Argon2Time uint32 `json:"argon2_time" yaml:"argon2_time"`
+	// Argon2Memory is the argon2id memory size in KiB
+	Argon2Memory uint32 `json:"argon2_memory" yaml:"argon2_memory"`
+	// Argon2Threads is the argon2id degree of parallelism
+	Argon2Threads uint8 `json:"argon2_threads" yaml:"argon2_threads"`
+	// Argon2KeyLength is the length of the argon2id hash in bytes
+	Argon2KeyLength uint32 `json:"argon2_key_length" yaml:"argon2_key_length"`
+	// Argon2SaltLength is the length of the random salt in bytes
+	Argon2SaltLength uint32 `json:"argon2_salt_length" yaml:"argon2_salt_length"`
+}
+
+// DefaultPasswordConfig returns the default password hashing configuration
+func DefaultPasswordConfig() PasswordConfig {
+	return PasswordConfig{
+		Algorithm:        PasswordBcrypt,
+		BcryptCost:       12,
+		Argon2Time:       1,
+		Argon2Memory:     64 * 1024,
+		Argon2Threads:    4,
+		Argon2KeyLength:  32,
+		Argon2SaltLength: 16,
+	}
+}
+
+// Validate validates the password hashing configuration
+func (c *PasswordConfig) Validate() error {
+	switch c.Algorithm {
+	case PasswordBcrypt:
+		if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+			return newInvalidConfigError(fmt.Sprintf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+		}
+	case PasswordArgon2id:
+		if c.Argon2Time == 0 || c.Argon2Memory == 0 || c.Argon2Threads == 0 {
+			return newInvalidConfigError("argon2 time, memory and threads must be positive")
+		}
+		if c.Argon2KeyLength == 0 || c.Argon2SaltLength == 0 {
+			return newInvalidConfigError("argon2 key and salt lengths must be positive")
+		}
+	default:
+		return newInvalidConfigError("unknown password algorithm: " + string(c.Algorithm))
+	}
+	return nil
+}
+
+// PasswordHasher hashes and verifies passwords
+type PasswordHasher struct {
+	config PasswordConfig
+}
+
+// NewPasswordHasher creates a new password hasher
+func NewPasswordHasher(config PasswordConfig) (*PasswordHasher, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return &PasswordHasher{config: config}, nil
+}
+
+// defaultPasswordHasher backs the package-level HashPassword and VerifyPassword
+var defaultPasswordHasher = &PasswordHasher{config: DefaultPasswordConfig()}
+
+// HashPassword hashes a password using the default configuration
+func HashPassword(password string) (string, error) {
+	return defaultPasswordHasher.Hash(password)
+}
+
+// VerifyPassword checks a password against a bcrypt or argon2id hash
+func VerifyPassword(hash, password string) error {
+	return defaultPasswordHasher.Verify(hash, password)
+}
+
+// Hash hashes a password with the configured algorithm
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	if h.config.Algorithm == PasswordArgon2id {
+		return h.hashArgon2id(password)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Verify checks a password against a hash. The algorithm is taken from the hash,
+// so hashes created with a different configuration still verify.
+func (h *PasswordHasher) Verify(hash, password string) error {
+	if strings.HasPrefix(hash, argon2idPrefix) {
+		return verifyArgon2id(hash, password)
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return newInvalidPasswordError()
+	}
+	if err != nil {
+		return fmt.Errorf("invalid password hash: %w", err)
+	}
+	return nil
+}
+
+// hashArgon2id hashes a password and encodes it in the PHC string format
+func (h *PasswordHasher) hashArgon2id(password string) (string, error) {
+	salt := make([]byte, h.config.Argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.config.Argon2Time, h.config.Argon2Memory, h.config.Argon2Threads, h.config.Argon2KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version,
+		h.config.Argon2Memory, h.config.Argon2Time, h.config.Argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// verifyArgon2id checks a password against a PHC-encoded argon2id hash
+func verifyArgon2id(hash, password string) error {
+	// $argon2id$v=19$m=65536,t=1,p=4$<salt>$<key>
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return fmt.Errorf("invalid password hash: malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return fmt.Errorf("invalid password hash: %w", err)
+	}
+	if version != argon2.Version {
+		return fmt.Errorf("invalid password hash: unsupported argon2 version %d", version)
+	}
+
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return fmt.Errorf("invalid password hash: %w", err)
+	}
+	if memory == 0 || time == 0 || threads == 0 {
+		return fmt.Errorf("invalid password hash: zero argon2id parameter")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return fmt.Errorf("invalid password hash: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return fmt.Errorf("invalid password hash: %w", err)
+	}
+	// An empty key would compare equal to the empty key derived for any password
+	if len(salt) == 0 || len(key) == 0 {
+		return fmt.Errorf("invalid password hash: empty argon2id salt or key")
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, candidate) != 1 {
+		return newInvalidPasswordError()
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, "correct horse", hash)
+
+	assert.NoError(t, VerifyPassword(hash, "correct horse"))
+
+	err = VerifyPassword(hash, "battery staple")
+	require.Error(t, err)
+	assert.True(t, IsInvalidPasswordError(err))
+}
+
+func TestPasswordHasher(t *testing.T) {
+	bcryptConfig := DefaultPasswordConfig()
+	bcryptConfig.BcryptCost = bcrypt.MinCost
+
+	argonConfig := DefaultPasswordConfig()
+	argonConfig.Algorithm = PasswordArgon2id
+	argonConfig.Argon2Memory = 1024
+
+	tests := []struct {
+		name   string
+		config PasswordConfig
+		prefix string
+	}{
+		{"bcrypt", bcryptConfig, "$2a$04$"},
+		{"argon2id", argonConfig, "$argon2id$v=19$m=1024,t=1,p=4$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher, err := NewPasswordHasher(tt.config)
+			require.NoError(t, err)
+
+			hash, err := hasher.Hash("secret")
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(hash, tt.prefix), hash)
+
+			assert.NoError(t, hasher.Verify(hash, "secret"))
+			assert.True(t, IsInvalidPasswordError(hasher.Verify(hash, "wrong")))
+
+			// Hashes are salted
+			other, err := hasher.Hash("secret")
+			require.NoError(t, err)
+			assert.NotEqual(t, hash, other)
+
+			// Hashes verify regardless of the hasher's configured algorithm
+			assert.NoError(t, VerifyPassword(hash, "secret"))
+		})
+	}
+
+	t.Run("malformed hash", func(t *testing.T) {
+		err := VerifyPassword("$argon2id$v=19$garbage", "secret")
+		require.Error(t, err)
+		assert.False(t, IsInvalidPasswordError(err))
+
+		err = VerifyPassword("not-a-hash", "secret")
+		require.Error(t, err)
+		assert.False(t, IsInvalidPasswordError(err))
+	})
+
+	t.Run("degenerate argon2id hash", func(t *testing.T) {
+		for _, hash := range []string{
+			"$argon2id$v=19$m=65536,t=1,p=4$c2FsdA$",
+			"$argon2id$v=19$m=65536,t=1,p=4$$a2V5",
+			"$argon2id$v=19$m=65536,t=1,p=0$c2FsdA$a2V5",
+			"$argon2id$v=19$m=65536,t=0,p=4$c2FsdA$a2V5",
+			"$argon2id$v=19$m=0,t=1,p=4$c2FsdA$a2V5",
+		} {
+			err := VerifyPassword(hash, "any password")
+			require.Error(t, err, hash)
+			assert.False(t, IsInvalidPasswordError(err), hash)
+			assert.Contains(t, err.Error(), "invalid password hash", hash)
+		}
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		config := DefaultPasswordConfig()
+		config.BcryptCost = bcrypt.MaxCost + 1
+		_, err := NewPasswordHasher(config)
+		assert.Error(t, err)
+
+		config = DefaultPasswordConfig()
+		config.Algorithm = "md5"
+		_, err = NewPasswordHasher(config)
+		assert.Error(t, err)
+	})
+}
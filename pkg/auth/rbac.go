@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/StackCatalyst/common-lib/pkg/errors"
 )
@@ -36,12 +38,27 @@ func BuildPermission(resource Resource, action Action) Permission {
 	return Permission(string(resource) + ":" + string(action))
 }
 
+// maxDecisions bounds the IsAllowed memoization; the cache is reset when full
+const maxDecisions = 10000
+
 // RBAC manages role-based access control
 type RBAC struct {
 	// rolePermissions maps roles to their permissions
 	rolePermissions map[Role]map[Permission]bool
 	// roleHierarchy maps roles to their parent roles
 	roleHierarchy map[Role][]Role
+	// logger receives permission check events, if set
+	logger *AuthLogger
+	// metrics counts authorization decisions, if set
+	metrics *MetricsReporter
+
+	// decisions memoizes IsAllowed results by role set, resource and action;
+	// it is reset whenever roles or permissions change
+	decisionsMu sync.RWMutex
+	decisions   map[string]bool
+	// generation counts resets of decisions, so a result evaluated before a
+	// reset is never memoized after it
+	generation uint64
 }
 
 // NewRBAC creates a new RBAC manager
@@ -49,9 +66,22 @@ func NewRBAC() *RBAC {
 	return &RBAC{
 		rolePermissions: make(map[Role]map[Permission]bool),
 		roleHierarchy:   make(map[Role][]Role),
+		decisions:       make(map[string]bool),
 	}
 }
 
+// SetAuthLogger sets the logger receiving permission check events from the
+// middleware and interceptors
+func (r *RBAC) SetAuthLogger(logger *AuthLogger) {
+	r.logger = logger
+}
+
+// SetMetricsReporter sets the reporter counting IsAllowed decisions. A
+// TokenManager's reporter can be shared via TokenManager.MetricsReporter.
+func (r *RBAC) SetMetricsReporter(metrics *MetricsReporter) {
+	r.metrics = metrics
+}
+
 // AddRole adds a new role with optional parent roles
 func (r *RBAC) AddRole(role Role, parents ...Role) error {
 	if _, exists := r.rolePermissions[role]; exists {
@@ -62,6 +92,7 @@ func (r *RBAC) AddRole(role Role, parents ...Role) error {
 	if len(parents) > 0 {
 		r.roleHierarchy[role] = parents
 	}
+	r.invalidate()
 
 	return nil
 }
@@ -76,6 +107,7 @@ func (r *RBAC) AddPermission(role Role, permissions ...Permission) error {
 	for _, perm := range permissions {
 		perms[perm] = true
 	}
+	r.invalidate()
 
 	return nil
 }
@@ -90,6 +122,7 @@ func (r *RBAC) RemovePermission(role Role, permissions ...Permission) error {
 	for _, perm := range permissions {
 		delete(perms, perm)
 	}
+	r.invalidate()
 
 	return nil
 }
@@ -115,8 +148,52 @@ func (r *RBAC) HasRole(userRoles []string, role Role) bool {
 	return false
 }
 
-// IsAllowed checks if a user has permission to perform an action
+// IsAllowed checks if a user has permission to perform an action. Results
+// are memoized until the roles or permissions next change.
 func (r *RBAC) IsAllowed(userRoles []string, resource Resource, action Action) bool {
+	key := decisionKey(userRoles, resource, action)
+
+	r.decisionsMu.RLock()
+	allowed, ok := r.decisions[key]
+	r.decisionsMu.RUnlock()
+	if !ok {
+		allowed = r.decide(key, userRoles, resource, action)
+	}
+
+	if r.metrics != nil {
+		r.metrics.ObserveAuthorizationDecision(resource, action, allowed)
+	}
+	return allowed
+}
+
+// decide evaluates a permission check and memoizes the result under key
+func (r *RBAC) decide(key string, userRoles []string, resource Resource, action Action) bool {
+	r.decisionsMu.RLock()
+	generation := r.generation
+	r.decisionsMu.RUnlock()
+
+	allowed := r.isAllowed(userRoles, resource, action)
+	r.remember(key, allowed, generation)
+	return allowed
+}
+
+// remember memoizes a result evaluated at the given generation, unless the
+// decisions were invalidated since
+func (r *RBAC) remember(key string, allowed bool, generation uint64) {
+	r.decisionsMu.Lock()
+	defer r.decisionsMu.Unlock()
+
+	if r.generation != generation {
+		return
+	}
+	if len(r.decisions) >= maxDecisions {
+		r.decisions = make(map[string]bool)
+	}
+	r.decisions[key] = allowed
+}
+
+// isAllowed evaluates a permission check against the role hierarchy
+func (r *RBAC) isAllowed(userRoles []string, resource Resource, action Action) bool {
 	permission := BuildPermission(resource, action)
 
 	// Check each role the user has
@@ -130,6 +207,25 @@ func (r *RBAC) IsAllowed(userRoles []string, resource Resource, action Action) b
 	return false
 }
 
+// invalidate discards memoized IsAllowed results
+func (r *RBAC) invalidate() {
+	r.decisionsMu.Lock()
+	r.decisions = make(map[string]bool)
+	r.generation++
+	r.decisionsMu.Unlock()
+}
+
+// decisionKey builds the memoization key for a permission check. Roles are
+// sorted so that the same set in any order shares an entry.
+func decisionKey(userRoles []string, resource Resource, action Action) string {
+	roles := userRoles
+	if len(roles) > 1 && !sort.StringsAreSorted(roles) {
+		roles = append([]string(nil), userRoles...)
+		sort.Strings(roles)
+	}
+	return strings.Join(roles, "\x00") + "\x01" + string(resource) + "\x01" + string(action)
+}
+
 func (r *RBAC) hasDirectPermission(role Role, permission Permission) bool {
 	perms, exists := r.rolePermissions[role]
 	if !exists {
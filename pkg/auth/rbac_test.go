@@ -125,6 +125,74 @@ func TestRBACIsAllowed(t *testing.T) {
 	assert.True(t, rbac.IsAllowed(multiRoles, ResourceDocument, ActionAll))
 }
 
+func TestRBACIsAllowedCacheInvalidation(t *testing.T) {
+	rbac := NewRBAC()
+	require.NoError(t, rbac.AddRole(RoleUser))
+	require.NoError(t, rbac.AddRole(RoleGuest, RoleUser))
+
+	docReadPerm := BuildPermission(ResourceDocument, ActionRead)
+	require.NoError(t, rbac.AddPermission(RoleUser, docReadPerm))
+
+	// Role order does not matter for the cached result
+	assert.True(t, rbac.IsAllowed([]string{"user", "guest"}, ResourceDocument, ActionRead))
+	assert.True(t, rbac.IsAllowed([]string{"guest", "user"}, ResourceDocument, ActionRead))
+	assert.True(t, rbac.IsAllowed([]string{"guest"}, ResourceDocument, ActionRead))
+
+	// Removing the permission busts the cached allow, including inherited ones
+	require.NoError(t, rbac.RemovePermission(RoleUser, docReadPerm))
+	assert.False(t, rbac.IsAllowed([]string{"user", "guest"}, ResourceDocument, ActionRead))
+	assert.False(t, rbac.IsAllowed([]string{"guest"}, ResourceDocument, ActionRead))
+
+	// Adding it back busts the cached deny
+	require.NoError(t, rbac.AddPermission(RoleUser, BuildPermission(ResourceDocument, ActionAll)))
+	assert.True(t, rbac.IsAllowed([]string{"guest"}, ResourceDocument, ActionRead))
+
+	// A newly added role is picked up
+	assert.False(t, rbac.IsAllowed([]string{"admin"}, ResourceDocument, ActionRead))
+	require.NoError(t, rbac.AddRole(RoleAdmin, RoleUser))
+	assert.True(t, rbac.IsAllowed([]string{"admin"}, ResourceDocument, ActionRead))
+}
+
+func TestRBACStaleDecisionNotMemoized(t *testing.T) {
+	rbac := NewRBAC()
+	require.NoError(t, rbac.AddRole(RoleUser))
+	key := decisionKey([]string{"user"}, ResourceDocument, ActionRead)
+
+	// A decision evaluated before an invalidation must not be stored after it
+	rbac.decisionsMu.RLock()
+	generation := rbac.generation
+	rbac.decisionsMu.RUnlock()
+	rbac.invalidate()
+	rbac.remember(key, true, generation)
+
+	rbac.decisionsMu.RLock()
+	_, cached := rbac.decisions[key]
+	rbac.decisionsMu.RUnlock()
+	assert.False(t, cached)
+	assert.False(t, rbac.IsAllowed([]string{"user"}, ResourceDocument, ActionRead))
+}
+
+func BenchmarkRBACIsAllowed(b *testing.B) {
+	rbac := NewRBAC()
+	require.NoError(b, rbac.AddRole(RoleAdmin))
+	require.NoError(b, rbac.AddRole(RoleUser, RoleAdmin))
+	require.NoError(b, rbac.AddRole(RoleGuest, RoleUser))
+	require.NoError(b, rbac.AddPermission(RoleAdmin, BuildPermission(ResourceProject, ActionAll)))
+	roles := []string{"guest"}
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rbac.isAllowed(roles, ResourceProject, ActionUpdate)
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rbac.IsAllowed(roles, ResourceProject, ActionUpdate)
+		}
+	})
+}
+
 func TestRBACHasRole(t *testing.T) {
 	rbac := NewRBAC()
 
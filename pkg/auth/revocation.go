@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RevocationStore records revoked token and token family IDs
+type RevocationStore interface {
+	// Revoke marks id as revoked until expiresAt
+	Revoke(ctx context.Context, id string, expiresAt time.Time) error
+	// IsRevoked reports whether id has been revoked
+	IsRevoked(ctx context.Context, id string) (bool, error)
+	// CheckAndRevoke atomically marks id as revoked until expiresAt, reporting
+	// whether it was already revoked
+	CheckAndRevoke(ctx context.Context, id string, expiresAt time.Time) (bool, error)
+}
+
+// MemoryRevocationStore is an in-memory RevocationStore
+type MemoryRevocationStore struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+// NewMemoryRevocationStore creates a new in-memory revocation store
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		revoked: make(map[string]time.Time),
+	}
+}
+
+// Revoke marks id as revoked until expiresAt, dropping expired entries
+func (s *MemoryRevocationStore) Revoke(ctx context.Context, id string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(time.Now())
+	if current, ok := s.revoked[id]; !ok || expiresAt.After(current) {
+		s.revoked[id] = expiresAt
+	}
+	return nil
+}
+
+// IsRevoked reports whether id has been revoked and the revocation has not expired
+func (s *MemoryRevocationStore) IsRevoked(ctx context.Context, id string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exp, ok := s.revoked[id]
+	return ok && time.Now().Before(exp), nil
+}
+
+// CheckAndRevoke marks id as revoked until expiresAt, reporting whether it was
+// already revoked. The check and the revocation happen under one lock, so of
+// several concurrent callers with the same id exactly one sees false.
+func (s *MemoryRevocationStore) CheckAndRevoke(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(time.Now())
+	if _, ok := s.revoked[id]; ok {
+		return true, nil
+	}
+	s.revoked[id] = expiresAt
+	return false, nil
+}
+
+// prune drops revocations that expired before now. The caller must hold mu.
+func (s *MemoryRevocationStore) prune(now time.Time) {
+	for key, exp := range s.revoked {
+		if now.After(exp) {
+			delete(s.revoked, key)
+		}
+	}
+}
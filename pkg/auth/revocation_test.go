@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRevocationStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRevocationStore()
+
+	revoked, err := store.IsRevoked(ctx, "token")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, store.Revoke(ctx, "token", time.Now().Add(time.Hour)))
+	revoked, err = store.IsRevoked(ctx, "token")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	require.NoError(t, store.Revoke(ctx, "expired", time.Now().Add(-time.Second)))
+	revoked, err = store.IsRevoked(ctx, "expired")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestMemoryRevocationStoreCheckAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRevocationStore()
+
+	already, err := store.CheckAndRevoke(ctx, "token", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, already)
+
+	revoked, err := store.IsRevoked(ctx, "token")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	already, err = store.CheckAndRevoke(ctx, "token", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, already)
+
+	// An expired revocation no longer counts
+	require.NoError(t, store.Revoke(ctx, "expired", time.Now().Add(-time.Second)))
+	already, err = store.CheckAndRevoke(ctx, "expired", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, already)
+}
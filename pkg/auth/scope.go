@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/StackCatalyst/common-lib/pkg/errors"
+	"github.com/StackCatalyst/common-lib/pkg/web"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequireScope creates a Gin middleware that requires the token to grant every
+// one of the given scopes. It complements RBAC and must run after AuthMiddleware.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, err := GetUserScopes(c.Request.Context())
+		if err != nil {
+			web.WriteError(c, err)
+			return
+		}
+
+		if !hasScopes(granted, scopes) {
+			web.WriteError(c, errors.New(errors.ErrForbidden, "insufficient scope"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireScopeUnaryInterceptor creates a gRPC unary interceptor that requires
+// the token to grant every one of the given scopes
+func RequireScopeUnaryInterceptor(scopes ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkScopes(ctx, scopes); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RequireScopeStreamInterceptor creates a gRPC stream interceptor that requires
+// the token to grant every one of the given scopes
+func RequireScopeStreamInterceptor(scopes ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkScopes(ss.Context(), scopes); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// GetUserScopes retrieves the scopes granted by the token from the context
+func GetUserScopes(ctx context.Context) ([]string, error) {
+	scopes, ok := ctx.Value(UserScopesKey).([]string)
+	if !ok {
+		return nil, errors.New(errors.ErrUnauthorized, "user scopes not found in context")
+	}
+	return scopes, nil
+}
+
+// checkScopes returns a gRPC status error unless the context grants all scopes
+func checkScopes(ctx context.Context, scopes []string) error {
+	granted, err := GetUserScopes(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "missing user scopes: %v", err)
+	}
+	if !hasScopes(granted, scopes) {
+		return status.Error(codes.PermissionDenied, "insufficient scope")
+	}
+	return nil
+}
+
+// hasScopes reports whether granted contains every required scope
+func hasScopes(granted, required []string) bool {
+	for _, scope := range required {
+		found := false
+		for _, g := range granted {
+			if g == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
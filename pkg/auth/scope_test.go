@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequireScope(t *testing.T) {
+	tm, _ := setupTestMiddleware(t)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/modules", AuthMiddleware(tm), RequireScope("modules:read", "modules:write"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.GET("/unauthenticated", RequireScope("modules:read"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	scoped := func(scopes ...string) string {
+		token, err := tm.GenerateScopedAccessToken("user123", []string{"user"}, scopes)
+		require.NoError(t, err)
+		return token
+	}
+	unscoped, err := tm.GenerateAccessToken("user123", []string{"user"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		path         string
+		token        string
+		expectedCode int
+	}{
+		{"sufficient scope", "/modules", scoped("modules:read", "modules:write", "admin"), http.StatusOK},
+		{"insufficient scope", "/modules", scoped("modules:read"), http.StatusForbidden},
+		{"missing scope claim", "/modules", unscoped, http.StatusForbidden},
+		{"not authenticated", "/unauthenticated", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set(AuthHeaderKey, BearerSchema+" "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestRequireScopeInterceptors(t *testing.T) {
+	tm := setupTestInterceptors(t)
+
+	incoming := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	}
+	sufficient, err := tm.GenerateScopedAccessToken("test-user", []string{"user"}, []string{"modules:read"})
+	require.NoError(t, err)
+	insufficient, err := tm.GenerateScopedAccessToken("test-user", []string{"user"}, []string{"modules:list"})
+	require.NoError(t, err)
+	unscoped, err := tm.GenerateAccessToken("test-user", []string{"user"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		token        string
+		expectedCode codes.Code
+	}{
+		{"sufficient scope", sufficient, codes.OK},
+		{"insufficient scope", insufficient, codes.PermissionDenied},
+		{"missing scope claim", unscoped, codes.PermissionDenied},
+	}
+
+	authUnary := AuthUnaryInterceptor(tm)
+	scopeUnary := RequireScopeUnaryInterceptor("modules:read")
+	authStream := AuthStreamInterceptor(tm)
+	scopeStream := RequireScopeStreamInterceptor("modules:read")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authUnary(incoming(tt.token), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return scopeUnary(ctx, req, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return "ok", nil
+				})
+			})
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+
+			err = authStream(nil, &mockServerStream{ctx: incoming(tt.token)}, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+				return scopeStream(srv, ss, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+					return nil
+				})
+			})
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+		})
+	}
+
+	t.Run("not authenticated", func(t *testing.T) {
+		_, err := scopeUnary(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestClaimsScopes(t *testing.T) {
+	tm := setupTestInterceptors(t)
+
+	token, err := tm.GenerateScopedAccessToken("test-user", nil, []string{"a", "b"})
+	require.NoError(t, err)
+	claims, err := tm.ValidateAccessToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "a b", claims.Scope)
+	assert.Equal(t, []string{"a", "b"}, claims.Scopes())
+
+	assert.Empty(t, (&Claims{Scope: "  "}).Scopes())
+}
@@ -1,10 +1,16 @@
 package cache
 
 import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/StackCatalyst/common-lib/pkg/metrics"
@@ -21,6 +27,9 @@ type Config struct {
 	MaxSize int64 `json:"max_size" yaml:"max_size"`
 	// PurgeInterval is how often to check for expired entries
 	PurgeInterval time.Duration `json:"purge_interval" yaml:"purge_interval"`
+	// CompressThreshold is the encoded size in bytes above which values are
+	// gzip-compressed; 0 disables compression
+	CompressThreshold int64 `json:"compress_threshold" yaml:"compress_threshold"`
 }
 
 // DefaultConfig returns the default cache configuration
@@ -33,25 +42,75 @@ func DefaultConfig() *Config {
 	}
 }
 
+// defaultLabel is the cache metrics label for lookups outside any namespace
+const defaultLabel = "memory"
+
+// ErrClosed is returned when storing a value in a closed cache
+var ErrClosed = errors.New("cache is closed")
+
 // entry represents a cache entry
 type entry struct {
-	value     []byte
-	size      int64
-	expiresAt time.Time
+	key        string
+	value      []byte
+	size       int64 // stored size, after any compression
+	rawSize    int64 // size before compression
+	compressed bool
+	expiresAt  time.Time
+}
+
+// decode returns the entry's JSON, decompressing it if needed. Entries are
+// never modified once stored, so this is safe without holding the lock.
+func (e *entry) decode() ([]byte, error) {
+	if !e.compressed {
+		return e.value, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(e.value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress value: %w", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress value: %w", err)
+	}
+	return data, nil
 }
 
 // Cache represents an in-memory cache with TTL and size limits
 type Cache struct {
 	config     *Config
-	mu         sync.RWMutex
-	data       map[string]*entry
+	mu         sync.Mutex
+	data       map[string]*list.Element
+	order      *list.List // front is most recently used
 	totalBytes int64
+	rawBytes   int64 // totalBytes before compression
+	hitCount   atomic.Int64
+	missCount  atomic.Int64
+
+	// Background cleanup lifecycle
+	closed      atomic.Bool
+	stopCleanup context.CancelFunc
+	cleanupDone chan struct{}
 
 	// Metrics
-	hits        *prometheus.CounterVec
-	misses      *prometheus.CounterVec
-	sizeMetric  *prometheus.GaugeVec
-	itemsMetric *prometheus.GaugeVec
+	hits          *prometheus.CounterVec
+	misses        *prometheus.CounterVec
+	sizeMetric    *prometheus.GaugeVec
+	rawSizeMetric *prometheus.GaugeVec
+	itemsMetric   *prometheus.GaugeVec
+	hitRateMetric *prometheus.GaugeVec
+}
+
+// Stats represents a point-in-time snapshot of cache usage
+type Stats struct {
+	Items   int     // Number of entries currently cached
+	Bytes   int64   // Total size of cached values in bytes
+	Hits    int64   // Number of cache hits since creation
+	Misses  int64   // Number of cache misses since creation
+	HitRate float64 // Ratio of hits to total lookups, 0 when there were none
+
+	UncompressedBytes int64 // Total size of cached values before compression
 }
 
 // New creates a new cache instance
@@ -62,7 +121,8 @@ func New(config *Config, metricsReporter *metrics.Reporter) *Cache {
 
 	c := &Cache{
 		config: config,
-		data:   make(map[string]*entry),
+		data:   make(map[string]*list.Element),
+		order:  list.New(),
 		hits: metricsReporter.Counter("cache_hits_total",
 			"Total number of cache hits",
 			[]string{"cache"}),
@@ -72,84 +132,309 @@ func New(config *Config, metricsReporter *metrics.Reporter) *Cache {
 		sizeMetric: metricsReporter.Gauge("cache_size_bytes",
 			"Current size of cache in bytes",
 			[]string{"cache"}),
+		rawSizeMetric: metricsReporter.Gauge("cache_uncompressed_size_bytes",
+			"Current size of cache in bytes before compression",
+			[]string{"cache"}),
 		itemsMetric: metricsReporter.Gauge("cache_items_total",
 			"Total number of items in cache",
 			[]string{"cache"}),
+		hitRateMetric: metricsReporter.Gauge("cache_hit_rate",
+			"Ratio of cache hits to total lookups",
+			[]string{"cache"}),
 	}
 
-	// Start background cleanup if enabled
+	// Start background cleanup if enabled; Close stops it
 	if config.Enabled && config.PurgeInterval > 0 {
-		go c.startCleanup(context.Background())
+		ctx, cancel := context.WithCancel(context.Background())
+		c.stopCleanup = cancel
+		c.cleanupDone = make(chan struct{})
+		go c.startCleanup(ctx)
 	}
 
 	return c
 }
 
-// Set stores a value in the cache
+// Set stores a value in the cache using the default TTL
 func (c *Cache) Set(ctx context.Context, key string, value interface{}) error {
+	return c.SetWithTTL(ctx, key, value, c.config.TTL)
+}
+
+// SetWithTTL stores a value in the cache with an entry-specific TTL
+func (c *Cache) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	if !c.config.Enabled {
 		return nil
 	}
+	if c.closed.Load() {
+		return ErrClosed
+	}
 
-	// Convert value to bytes
-	data, err := json.Marshal(value)
+	e, err := c.encode(value)
 	if err != nil {
-		return fmt.Errorf("failed to marshal value: %w", err)
+		return err
 	}
 
-	size := int64(len(data))
-	if size > c.config.MaxSize {
-		return fmt.Errorf("value size %d exceeds maximum cache size %d", size, c.config.MaxSize)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Close may have run since the check above
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	c.insert(key, e, time.Now().Add(ttl))
+	c.updateSizeMetrics()
+
+	return nil
+}
+
+// SetMulti stores several values using the default TTL under a single lock
+// acquisition. If any value cannot be encoded, nothing is stored.
+func (c *Cache) SetMulti(ctx context.Context, items map[string]interface{}) error {
+	if !c.config.Enabled {
+		return nil
+	}
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	encoded := make(map[string]*entry, len(items))
+	for key, value := range items {
+		e, err := c.encode(value)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+		encoded[key] = e
 	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	expiresAt := time.Now().Add(c.config.TTL)
+	for key, e := range encoded {
+		c.insert(key, e, expiresAt)
+	}
+	c.updateSizeMetrics()
+
+	return nil
+}
+
+// encode marshals a value into an entry, compressing it when it exceeds the
+// compression threshold, and rejects values larger than the whole cache
+func (c *Cache) encode(value interface{}) (*entry, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	e := &entry{value: data, size: int64(len(data)), rawSize: int64(len(data))}
+	if c.config.CompressThreshold > 0 && e.rawSize > c.config.CompressThreshold {
+		compressed, err := compress(data)
+		if err != nil {
+			return nil, err
+		}
+		// Keep the original when compression does not help
+		if int64(len(compressed)) < e.rawSize {
+			e.value = compressed
+			e.size = int64(len(compressed))
+			e.compressed = true
+		}
+	}
+
+	if e.size > c.config.MaxSize {
+		return nil, fmt.Errorf("value size %d exceeds maximum cache size %d", e.size, c.config.MaxSize)
+	}
+	return e, nil
+}
+
+// compress gzips data
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress value: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress value: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// insert stores an encoded entry, evicting entries to make room; the caller must hold c.mu
+func (c *Cache) insert(key string, e *entry, expiresAt time.Time) {
+	size := e.size
+
+	// Replace any existing entry so its size is not counted twice
+	if elem, exists := c.data[key]; exists {
+		c.removeElement(elem)
+	}
+
 	// Check if we need to make room
 	if c.totalBytes+size > c.config.MaxSize {
 		c.evict(size)
 	}
 
-	// Store the entry
-	c.data[key] = &entry{
-		value:     data,
-		size:      size,
-		expiresAt: time.Now().Add(c.config.TTL),
-	}
+	e.key = key
+	e.expiresAt = expiresAt
+	c.data[key] = c.order.PushFront(e)
 
 	c.totalBytes += size
-	c.itemsMetric.WithLabelValues("memory").Inc()
-	c.sizeMetric.WithLabelValues("memory").Set(float64(c.totalBytes))
-
-	return nil
+	c.rawBytes += e.rawSize
+	c.itemsMetric.WithLabelValues(defaultLabel).Inc()
 }
 
-// Get retrieves a value from the cache
+// Get retrieves a value from the cache. A closed cache always misses.
 func (c *Cache) Get(ctx context.Context, key string, value interface{}) bool {
-	if !c.config.Enabled {
-		c.misses.WithLabelValues("memory").Inc()
+	return c.get(key, value, defaultLabel)
+}
+
+// get retrieves a value, recording the hit or miss under the given metrics label
+func (c *Cache) get(key string, value interface{}, label string) bool {
+	if !c.config.Enabled || c.closed.Load() {
+		c.recordMiss(label)
 		return false
 	}
 
-	c.mu.RLock()
-	entry, exists := c.data[key]
-	if !exists || time.Now().After(entry.expiresAt) {
-		c.mu.RUnlock()
-		c.misses.WithLabelValues("memory").Inc()
+	c.mu.Lock()
+	elem, exists := c.data[key]
+	if !exists || time.Now().After(elem.Value.(*entry).expiresAt) {
+		c.mu.Unlock()
+		c.recordMiss(label)
 		return false
 	}
-	data := entry.value
-	c.mu.RUnlock()
+	c.order.MoveToFront(elem)
+	e := elem.Value.(*entry)
+	c.mu.Unlock()
 
+	data, err := e.decode()
+	if err != nil {
+		c.recordMiss(label)
+		return false
+	}
 	if err := json.Unmarshal(data, value); err != nil {
-		c.misses.WithLabelValues("memory").Inc()
+		c.recordMiss(label)
 		return false
 	}
 
-	c.hits.WithLabelValues("memory").Inc()
+	c.recordHit(label)
 	return true
 }
 
+// GetMulti looks up several keys under a single lock acquisition, storing the
+// raw JSON of each live entry in dest and returning the keys that were found
+func (c *Cache) GetMulti(ctx context.Context, keys []string, dest map[string]json.RawMessage) []string {
+	if !c.config.Enabled || c.closed.Load() {
+		for range keys {
+			c.recordMiss(defaultLabel)
+		}
+		return nil
+	}
+
+	var hits []*entry
+	now := time.Now()
+
+	c.mu.Lock()
+	for _, key := range keys {
+		elem, exists := c.data[key]
+		if !exists || now.After(elem.Value.(*entry).expiresAt) {
+			continue
+		}
+		c.order.MoveToFront(elem)
+		hits = append(hits, elem.Value.(*entry))
+	}
+	c.mu.Unlock()
+
+	var found []string
+	for _, e := range hits {
+		data, err := e.decode()
+		if err != nil {
+			continue
+		}
+		// Copy so callers cannot modify the cached bytes
+		dest[e.key] = append(json.RawMessage(nil), data...)
+		found = append(found, e.key)
+	}
+
+	for i := len(found); i < len(keys); i++ {
+		c.recordMiss(defaultLabel)
+	}
+	for range found {
+		c.recordHit(defaultLabel)
+	}
+	return found
+}
+
+// Keys returns the keys of all unexpired entries, most recently used first
+func (c *Cache) Keys() []string {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, len(c.data))
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		if e := elem.Value.(*entry); !now.After(e.expiresAt) {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
+
+// Len returns the number of unexpired entries
+func (c *Cache) Len() int {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, elem := range c.data {
+		if !now.After(elem.Value.(*entry).expiresAt) {
+			n++
+		}
+	}
+	return n
+}
+
+// Range calls fn for each unexpired key, most recently used first, until fn
+// returns false. It iterates over a snapshot, so fn may use the cache.
+func (c *Cache) Range(fn func(key string) bool) {
+	for _, key := range c.Keys() {
+		if !fn(key) {
+			return
+		}
+	}
+}
+
+// Stats returns a snapshot of the cache's current usage
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	stats := Stats{
+		Items:             len(c.data),
+		Bytes:             c.totalBytes,
+		UncompressedBytes: c.rawBytes,
+	}
+	c.mu.Unlock()
+
+	stats.Hits = c.hitCount.Load()
+	stats.Misses = c.missCount.Load()
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+func (c *Cache) recordHit(label string) {
+	c.hitCount.Add(1)
+	c.hits.WithLabelValues(label).Inc()
+}
+
+func (c *Cache) recordMiss(label string) {
+	c.missCount.Add(1)
+	c.misses.WithLabelValues(label).Inc()
+}
+
 // Delete removes a value from the cache
 func (c *Cache) Delete(ctx context.Context, key string) {
 	if !c.config.Enabled {
@@ -157,11 +442,9 @@ func (c *Cache) Delete(ctx context.Context, key string) {
 	}
 
 	c.mu.Lock()
-	if entry, exists := c.data[key]; exists {
-		c.totalBytes -= entry.size
-		delete(c.data, key)
-		c.itemsMetric.WithLabelValues("memory").Dec()
-		c.sizeMetric.WithLabelValues("memory").Set(float64(c.totalBytes))
+	if elem, exists := c.data[key]; exists {
+		c.removeElement(elem)
+		c.updateSizeMetrics()
 	}
 	c.mu.Unlock()
 }
@@ -173,28 +456,69 @@ func (c *Cache) Clear(ctx context.Context) {
 	}
 
 	c.mu.Lock()
-	c.data = make(map[string]*entry)
+	c.data = make(map[string]*list.Element)
+	c.order.Init()
 	c.totalBytes = 0
-	c.itemsMetric.WithLabelValues("memory").Set(0)
-	c.sizeMetric.WithLabelValues("memory").Set(0)
+	c.rawBytes = 0
+	c.itemsMetric.WithLabelValues(defaultLabel).Set(0)
+	c.updateSizeMetrics()
 	c.mu.Unlock()
 }
 
-// evict removes entries to make room for the requested size
+// Close stops the background cleanup and drops all entries. After Close, Set
+// returns ErrClosed and Get always misses. Close is safe to call more than once.
+func (c *Cache) Close() error {
+	if !c.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	if c.stopCleanup != nil {
+		c.stopCleanup()
+		<-c.cleanupDone
+	}
+
+	c.mu.Lock()
+	c.data = make(map[string]*list.Element)
+	c.order.Init()
+	c.totalBytes = 0
+	c.rawBytes = 0
+	c.itemsMetric.WithLabelValues(defaultLabel).Set(0)
+	c.updateSizeMetrics()
+	c.mu.Unlock()
+
+	return nil
+}
+
+// evict removes least recently used entries to make room for the requested size
 func (c *Cache) evict(needed int64) {
-	// Simple LRU-like eviction: remove oldest entries first
-	for key, entry := range c.data {
-		if c.totalBytes+needed <= c.config.MaxSize {
+	for c.totalBytes+needed > c.config.MaxSize {
+		elem := c.order.Back()
+		if elem == nil {
 			break
 		}
-		c.totalBytes -= entry.size
-		delete(c.data, key)
-		c.itemsMetric.WithLabelValues("memory").Dec()
+		c.removeElement(elem)
 	}
 }
 
+// updateSizeMetrics publishes the stored and uncompressed sizes; the caller must hold c.mu
+func (c *Cache) updateSizeMetrics() {
+	c.sizeMetric.WithLabelValues(defaultLabel).Set(float64(c.totalBytes))
+	c.rawSizeMetric.WithLabelValues(defaultLabel).Set(float64(c.rawBytes))
+}
+
+// removeElement removes an entry from the cache; the caller must hold c.mu
+func (c *Cache) removeElement(elem *list.Element) {
+	e := c.order.Remove(elem).(*entry)
+	delete(c.data, e.key)
+	c.totalBytes -= e.size
+	c.rawBytes -= e.rawSize
+	c.itemsMetric.WithLabelValues(defaultLabel).Dec()
+}
+
 // startCleanup runs periodic cleanup of expired entries
 func (c *Cache) startCleanup(ctx context.Context) {
+	defer close(c.cleanupDone)
+
 	ticker := time.NewTicker(c.config.PurgeInterval)
 	defer ticker.Stop()
 
@@ -204,6 +528,7 @@ func (c *Cache) startCleanup(ctx context.Context) {
 			return
 		case <-ticker.C:
 			c.cleanup()
+			c.hitRateMetric.WithLabelValues(defaultLabel).Set(c.Stats().HitRate)
 		}
 	}
 }
@@ -212,13 +537,13 @@ func (c *Cache) startCleanup(ctx context.Context) {
 func (c *Cache) cleanup() {
 	now := time.Now()
 	c.mu.Lock()
-	for key, entry := range c.data {
-		if now.After(entry.expiresAt) {
-			c.totalBytes -= entry.size
-			delete(c.data, key)
-			c.itemsMetric.WithLabelValues("memory").Dec()
+	for elem := c.order.Back(); elem != nil; {
+		prev := elem.Prev()
+		if now.After(elem.Value.(*entry).expiresAt) {
+			c.removeElement(elem)
 		}
+		elem = prev
 	}
-	c.sizeMetric.WithLabelValues("memory").Set(float64(c.totalBytes))
+	c.updateSizeMetrics()
 	c.mu.Unlock()
 }
@@ -2,6 +2,10 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -9,6 +13,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
 )
 
 func newTestMetricsReporter() *metrics.Reporter {
@@ -110,6 +115,134 @@ func TestCacheEviction(t *testing.T) {
 	assert.Equal(t, value, retrieved)
 }
 
+func TestCacheLRUEviction(t *testing.T) {
+	ctx := context.Background()
+	metricsReporter := newTestMetricsReporter()
+	config := &Config{
+		Enabled:       true,
+		TTL:           time.Hour,
+		MaxSize:       30, // Room for three 10-byte values
+		PurgeInterval: time.Hour,
+	}
+
+	cache := New(config, metricsReporter)
+	require.NotNil(t, cache)
+
+	// Each marshaled value is exactly 10 bytes
+	require.NoError(t, cache.Set(ctx, "a", "aaaaaaaa"))
+	require.NoError(t, cache.Set(ctx, "b", "bbbbbbbb"))
+	require.NoError(t, cache.Set(ctx, "c", "cccccccc"))
+
+	// Touch A so that B becomes the least recently used entry
+	var retrieved string
+	require.True(t, cache.Get(ctx, "a", &retrieved))
+
+	// Filling the cache must evict B, the oldest untouched entry
+	require.NoError(t, cache.Set(ctx, "d", "dddddddd"))
+	assert.True(t, cache.Get(ctx, "a", &retrieved))
+	assert.False(t, cache.Get(ctx, "b", &retrieved))
+	assert.True(t, cache.Get(ctx, "c", &retrieved))
+	assert.True(t, cache.Get(ctx, "d", &retrieved))
+	assert.Equal(t, int64(30), cache.totalBytes)
+}
+
+func TestCacheOverwriteAccounting(t *testing.T) {
+	ctx := context.Background()
+	cache := New(&Config{Enabled: true, TTL: time.Hour, MaxSize: 100}, newTestMetricsReporter())
+
+	require.NoError(t, cache.Set(ctx, "key", "aaaaaaaa"))
+	require.NoError(t, cache.Set(ctx, "key", "bbbbbbbb"))
+
+	assert.Equal(t, int64(10), cache.totalBytes)
+	assert.Len(t, cache.data, 1)
+}
+
+func TestCacheSetWithTTL(t *testing.T) {
+	ctx := context.Background()
+	metricsReporter := newTestMetricsReporter()
+	config := &Config{
+		Enabled:       true,
+		TTL:           time.Hour,
+		MaxSize:       1024,
+		PurgeInterval: time.Millisecond * 50,
+	}
+
+	cache := New(config, metricsReporter)
+	require.NotNil(t, cache)
+
+	require.NoError(t, cache.SetWithTTL(ctx, "short", "value", time.Millisecond*100))
+	require.NoError(t, cache.SetWithTTL(ctx, "long", "value", time.Hour))
+	require.NoError(t, cache.Set(ctx, "default", "value"))
+
+	var retrieved string
+	assert.True(t, cache.Get(ctx, "short", &retrieved))
+
+	time.Sleep(time.Millisecond * 200)
+
+	// The short-lived entry expires first while the others survive
+	assert.False(t, cache.Get(ctx, "short", &retrieved))
+	assert.True(t, cache.Get(ctx, "long", &retrieved))
+	assert.True(t, cache.Get(ctx, "default", &retrieved))
+
+	// The cleanup goroutine has purged the expired entry
+	cache.mu.Lock()
+	_, exists := cache.data["short"]
+	cache.mu.Unlock()
+	assert.False(t, exists)
+}
+
+func TestCacheStats(t *testing.T) {
+	ctx := context.Background()
+	registry := prometheus.NewRegistry()
+	metricsReporter := metrics.New(metrics.Options{
+		Namespace: "test",
+		Subsystem: "cache",
+		Registry:  registry,
+	})
+	config := &Config{
+		Enabled:       true,
+		TTL:           time.Hour,
+		MaxSize:       1024,
+		PurgeInterval: time.Millisecond * 50,
+	}
+
+	cache := New(config, metricsReporter)
+	require.NotNil(t, cache)
+
+	stats := cache.Stats()
+	assert.Equal(t, 0, stats.Items)
+	assert.Equal(t, float64(0), stats.HitRate)
+
+	require.NoError(t, cache.Set(ctx, "a", "aaaaaaaa"))
+	require.NoError(t, cache.Set(ctx, "b", "bbbbbbbb"))
+
+	// Three hits and one miss
+	var retrieved string
+	assert.True(t, cache.Get(ctx, "a", &retrieved))
+	assert.True(t, cache.Get(ctx, "a", &retrieved))
+	assert.True(t, cache.Get(ctx, "b", &retrieved))
+	assert.False(t, cache.Get(ctx, "c", &retrieved))
+
+	stats = cache.Stats()
+	assert.Equal(t, 2, stats.Items)
+	assert.Equal(t, int64(20), stats.Bytes)
+	assert.Equal(t, int64(3), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.InDelta(t, 0.75, stats.HitRate, 0.0001)
+
+	// The hit-rate gauge is refreshed by the cleanup loop
+	assert.Eventually(t, func() bool {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		for _, m := range families {
+			if m.GetName() == "test_cache_cache_hit_rate" {
+				return m.GetMetric()[0].GetGauge().GetValue() == 0.75
+			}
+		}
+		return false
+	}, time.Second, time.Millisecond*10)
+}
+
 func TestCacheDisabled(t *testing.T) {
 	ctx := context.Background()
 	metricsReporter := newTestMetricsReporter()
@@ -128,3 +261,203 @@ func TestCacheDisabled(t *testing.T) {
 	ok := cache.Get(ctx, "test", &retrieved)
 	assert.False(t, ok)
 }
+
+func TestCacheClose(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{
+		Enabled:       true,
+		TTL:           time.Minute,
+		MaxSize:       1024,
+		PurgeInterval: time.Millisecond * 10,
+	}
+
+	cache := New(config, newTestMetricsReporter())
+	require.NoError(t, cache.Set(ctx, "test", "value"))
+
+	require.NoError(t, cache.Close())
+
+	// The cleanup goroutine has exited once Close returns
+	select {
+	case <-cache.cleanupDone:
+	default:
+		t.Fatal("cleanup goroutine still running after Close")
+	}
+
+	var retrieved string
+	assert.False(t, cache.Get(ctx, "test", &retrieved))
+	assert.ErrorIs(t, cache.Set(ctx, "test", "value"), ErrClosed)
+	assert.Equal(t, 0, cache.Stats().Items)
+
+	// Closing again is a no-op
+	assert.NoError(t, cache.Close())
+
+	// Caches without background cleanup can be closed too
+	noCleanup := New(&Config{Enabled: true, TTL: time.Minute, MaxSize: 1024}, newTestMetricsReporter())
+	assert.NoError(t, noCleanup.Close())
+}
+
+func TestCacheMulti(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{
+		Enabled: true,
+		TTL:     time.Minute,
+		MaxSize: 1024,
+	}
+	cache := New(config, newTestMetricsReporter())
+
+	require.NoError(t, cache.SetMulti(ctx, map[string]interface{}{
+		"a": "alpha",
+		"b": map[string]int{"n": 2},
+	}))
+	require.NoError(t, cache.SetWithTTL(ctx, "expired", "gone", -time.Second))
+
+	dest := make(map[string]json.RawMessage)
+	found := cache.GetMulti(ctx, []string{"a", "missing", "b", "expired"}, dest)
+
+	assert.Equal(t, []string{"a", "b"}, found)
+	assert.Len(t, dest, 2)
+	assert.JSONEq(t, `"alpha"`, string(dest["a"]))
+	assert.JSONEq(t, `{"n":2}`, string(dest["b"]))
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+
+	// Modifying the returned bytes does not affect the cached value
+	dest["a"][1] = 'X'
+	var value string
+	require.True(t, cache.Get(ctx, "a", &value))
+	assert.Equal(t, "alpha", value)
+
+	// An unencodable value stores nothing
+	err := cache.SetMulti(ctx, map[string]interface{}{
+		"c":   "charlie",
+		"bad": make(chan int),
+	})
+	require.Error(t, err)
+	assert.False(t, cache.Get(ctx, "c", &value))
+
+	require.NoError(t, cache.Close())
+	assert.ErrorIs(t, cache.SetMulti(ctx, map[string]interface{}{"a": "alpha"}), ErrClosed)
+	assert.Empty(t, cache.GetMulti(ctx, []string{"a"}, dest))
+}
+
+func TestCacheCompression(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{
+		Enabled:           true,
+		TTL:               time.Minute,
+		MaxSize:           1024 * 1024,
+		CompressThreshold: 256,
+	}
+	cache := New(config, newTestMetricsReporter())
+
+	large := strings.Repeat("terraform module content ", 1000)
+	require.NoError(t, cache.Set(ctx, "large", large))
+	require.NoError(t, cache.Set(ctx, "small", "tiny"))
+
+	cache.mu.Lock()
+	largeEntry := cache.data["large"].Value.(*entry)
+	smallEntry := cache.data["small"].Value.(*entry)
+	cache.mu.Unlock()
+	assert.True(t, largeEntry.compressed)
+	assert.Less(t, largeEntry.size, largeEntry.rawSize)
+	assert.False(t, smallEntry.compressed)
+
+	stats := cache.Stats()
+	assert.Equal(t, largeEntry.size+smallEntry.size, stats.Bytes)
+	assert.Equal(t, largeEntry.rawSize+smallEntry.rawSize, stats.UncompressedBytes)
+
+	var value string
+	require.True(t, cache.Get(ctx, "large", &value))
+	assert.Equal(t, large, value)
+
+	dest := make(map[string]json.RawMessage)
+	assert.Equal(t, []string{"large"}, cache.GetMulti(ctx, []string{"large"}, dest))
+	require.NoError(t, json.Unmarshal(dest["large"], &value))
+	assert.Equal(t, large, value)
+
+	cache.Delete(ctx, "large")
+	stats = cache.Stats()
+	assert.Equal(t, smallEntry.size, stats.Bytes)
+	assert.Equal(t, smallEntry.rawSize, stats.UncompressedBytes)
+}
+
+func TestCacheCloseNoGoroutineLeak(t *testing.T) {
+	// Caches left open by other tests in the package are not this test's concern
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	config := &Config{
+		Enabled:       true,
+		TTL:           time.Minute,
+		MaxSize:       1024,
+		PurgeInterval: time.Millisecond,
+	}
+	caches := make([]*Cache, 50)
+	for i := range caches {
+		caches[i] = New(config, newTestMetricsReporter())
+	}
+
+	for _, cache := range caches {
+		require.NoError(t, cache.Close())
+	}
+}
+
+func TestCacheKeys(t *testing.T) {
+	ctx := context.Background()
+	cache := New(&Config{Enabled: true, TTL: time.Minute, MaxSize: 1024}, newTestMetricsReporter())
+
+	require.NoError(t, cache.Set(ctx, "a", 1))
+	require.NoError(t, cache.Set(ctx, "b", 2))
+	require.NoError(t, cache.Set(ctx, "c", 3))
+	require.NoError(t, cache.SetWithTTL(ctx, "expired", 4, -time.Second))
+
+	// Reading "a" makes it the most recently used
+	var value int
+	require.True(t, cache.Get(ctx, "a", &value))
+
+	assert.Equal(t, []string{"a", "c", "b"}, cache.Keys())
+	assert.Equal(t, 3, cache.Len())
+
+	var visited []string
+	cache.Range(func(key string) bool {
+		visited = append(visited, key)
+		return len(visited) < 2
+	})
+	assert.Equal(t, []string{"a", "c"}, visited)
+
+	// The callback may modify the cache
+	cache.Range(func(key string) bool {
+		cache.Delete(ctx, key)
+		return true
+	})
+	assert.Empty(t, cache.Keys())
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCacheKeysConcurrent(t *testing.T) {
+	ctx := context.Background()
+	cache := New(&Config{Enabled: true, TTL: time.Minute, MaxSize: 1024 * 1024}, newTestMetricsReporter())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = cache.Set(ctx, fmt.Sprintf("key-%d-%d", i, j), j)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cache.Keys()
+				cache.Len()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 400, cache.Len())
+	assert.Len(t, cache.Keys(), 400)
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespacedCache(t *testing.T) {
+	ctx := context.Background()
+	cache := New(&Config{Enabled: true, TTL: time.Minute, MaxSize: 1024}, newTestMetricsReporter())
+
+	users := cache.Namespace("users")
+	sessions := cache.Namespace("sessions")
+
+	// The same logical key does not collide across namespaces
+	require.NoError(t, users.Set(ctx, "123", "alice"))
+	require.NoError(t, sessions.Set(ctx, "123", "token"))
+	require.NoError(t, cache.Set(ctx, "123", "global"))
+
+	var value string
+	require.True(t, users.Get(ctx, "123", &value))
+	assert.Equal(t, "alice", value)
+	require.True(t, sessions.Get(ctx, "123", &value))
+	assert.Equal(t, "token", value)
+	require.True(t, cache.Get(ctx, "123", &value))
+	assert.Equal(t, "global", value)
+
+	require.NoError(t, users.SetWithTTL(ctx, "456", "bob", time.Minute))
+	users.Delete(ctx, "456")
+	assert.False(t, users.Get(ctx, "456", &value))
+
+	// Clearing one namespace leaves the others intact
+	require.NoError(t, users.Set(ctx, "789", "carol"))
+	users.ClearNamespace(ctx)
+
+	assert.False(t, users.Get(ctx, "123", &value))
+	assert.False(t, users.Get(ctx, "789", &value))
+	require.True(t, sessions.Get(ctx, "123", &value))
+	assert.Equal(t, "token", value)
+	require.True(t, cache.Get(ctx, "123", &value))
+	assert.Equal(t, 2, cache.Stats().Items)
+}
+
+func TestNestedNamespaces(t *testing.T) {
+	ctx := context.Background()
+	cache := New(&Config{Enabled: true, TTL: time.Minute, MaxSize: 1024}, newTestMetricsReporter())
+
+	parent := cache.Namespace("a")
+	child := cache.Namespace("a:b")
+	require.NoError(t, parent.Set(ctx, "b:key", "parent"))
+	require.NoError(t, child.Set(ctx, "key", "child"))
+
+	// Namespaces containing the separator do not collide with their parent
+	var value string
+	require.True(t, parent.Get(ctx, "b:key", &value))
+	assert.Equal(t, "parent", value)
+	require.True(t, child.Get(ctx, "key", &value))
+	assert.Equal(t, "child", value)
+
+	parent.ClearNamespace(ctx)
+	assert.False(t, parent.Get(ctx, "b:key", &value))
+	require.True(t, child.Get(ctx, "key", &value))
+	assert.Equal(t, "child", value)
+
+	require.NoError(t, parent.Set(ctx, "key", "parent"))
+	child.ClearNamespace(ctx)
+	require.True(t, parent.Get(ctx, "key", &value))
+	assert.Equal(t, "parent", value)
+}
+
+func TestNamespacedCacheMetrics(t *testing.T) {
+	ctx := context.Background()
+	registry := prometheus.NewRegistry()
+	reporter := metrics.New(metrics.Options{
+		Namespace: "test",
+		Subsystem: "cache",
+		Registry:  registry,
+	})
+	cache := New(&Config{Enabled: true, TTL: time.Minute, MaxSize: 1024}, reporter)
+
+	users := cache.Namespace("users")
+	require.NoError(t, users.Set(ctx, "123", "alice"))
+
+	var value string
+	users.Get(ctx, "123", &value)
+	users.Get(ctx, "missing", &value)
+	cache.Get(ctx, "missing", &value)
+
+	counts := make(map[string]float64)
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "cache" && m.GetCounter() != nil {
+					counts[family.GetName()+"/"+label.GetValue()] = m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, float64(1), counts["test_cache_cache_hits_total/users"])
+	assert.Equal(t, float64(1), counts["test_cache_cache_misses_total/users"])
+	assert.Equal(t, float64(1), counts["test_cache_cache_misses_total/memory"])
+}
@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// TypedCache is a type-safe view over a Cache for values of type T
+type TypedCache[T any] struct {
+	cache *Cache
+}
+
+// NewTyped creates a typed wrapper that shares the given cache's storage, config and metrics
+func NewTyped[T any](cache *Cache) *TypedCache[T] {
+	return &TypedCache[T]{cache: cache}
+}
+
+// Get retrieves a value from the cache, returning the zero value and false on a miss
+func (c *TypedCache[T]) Get(ctx context.Context, key string) (T, bool) {
+	var value T
+	if !c.cache.Get(ctx, key, &value) {
+		var zero T
+		return zero, false
+	}
+	return value, true
+}
+
+// Set stores a value in the cache using the default TTL
+func (c *TypedCache[T]) Set(ctx context.Context, key string, value T) error {
+	return c.cache.Set(ctx, key, value)
+}
+
+// SetWithTTL stores a value in the cache with an entry-specific TTL
+func (c *TypedCache[T]) SetWithTTL(ctx context.Context, key string, value T, ttl time.Duration) error {
+	return c.cache.SetWithTTL(ctx, key, value, ttl)
+}
+
+// Delete removes a value from the cache
+func (c *TypedCache[T]) Delete(ctx context.Context, key string) {
+	c.cache.Delete(ctx, key)
+}
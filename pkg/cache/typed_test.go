@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStruct struct {
+	Name  string            `json:"name"`
+	Count int               `json:"count"`
+	Tags  []string          `json:"tags"`
+	Attrs map[string]string `json:"attrs"`
+}
+
+func TestTypedCache(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{
+		Enabled:       true,
+		TTL:           time.Hour,
+		MaxSize:       1024,
+		PurgeInterval: time.Hour,
+	}
+
+	cache := NewTyped[testStruct](New(config, newTestMetricsReporter()))
+	require.NotNil(t, cache)
+
+	value := testStruct{
+		Name:  "test",
+		Count: 42,
+		Tags:  []string{"a", "b"},
+		Attrs: map[string]string{"key": "value"},
+	}
+
+	// Test round trip
+	require.NoError(t, cache.Set(ctx, "test", value))
+	retrieved, ok := cache.Get(ctx, "test")
+	require.True(t, ok)
+	assert.Equal(t, value, retrieved)
+
+	// Test miss returns the zero value
+	retrieved, ok = cache.Get(ctx, "nonexistent")
+	assert.False(t, ok)
+	assert.Equal(t, testStruct{}, retrieved)
+
+	// Test deletion
+	cache.Delete(ctx, "test")
+	_, ok = cache.Get(ctx, "test")
+	assert.False(t, ok)
+}
+
+func TestTypedCacheSharesStorage(t *testing.T) {
+	ctx := context.Background()
+	underlying := New(&Config{Enabled: true, TTL: time.Hour, MaxSize: 1024}, newTestMetricsReporter())
+	typed := NewTyped[int](underlying)
+
+	require.NoError(t, typed.Set(ctx, "answer", 42))
+
+	var raw int
+	require.True(t, underlying.Get(ctx, "answer", &raw))
+	assert.Equal(t, 42, raw)
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// Validator is implemented by configs that can check their own values, such
+// as database.Config and auth.Config
+type Validator interface {
+	Validate() error
+}
+
+// Load reads a YAML or JSON file into out, applies environment variable
+// overrides using the default EnvPrefix and validates the result if out
+// implements Validator. See LoadWithPrefix.
+func Load(path string, out interface{}) error {
+	return LoadWithPrefix(path, DefaultOptions().EnvPrefix, out)
+}
+
+// LoadWithPrefix reads a YAML or JSON file into out, which must be a pointer
+// to a struct. Fields are keyed by their yaml tags and can be overridden by
+// environment variables named after the key path, so with the prefix "APP"
+// database.host is overridden by APP_DATABASE_HOST; slices are given as
+// comma-separated values. Fields absent from both
+// keep their current values, so out may be pre-filled with defaults. An empty
+// path loads from the environment only.
+func LoadWithPrefix(path, envPrefix string, out interface{}) error {
+	t := reflect.TypeOf(out)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return errors.New("config target must be a pointer to a struct")
+	}
+
+	v := viper.New()
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("error reading config file: %w", err)
+		}
+	}
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	for _, key := range envKeys(t.Elem(), "") {
+		if err := v.BindEnv(key); err != nil {
+			return fmt.Errorf("error binding environment variable for %s: %w", key, err)
+		}
+	}
+
+	if err := v.Unmarshal(out, func(c *mapstructure.DecoderConfig) {
+		c.TagName = "yaml"
+	}); err != nil {
+		return fmt.Errorf("error decoding config: %w", err)
+	}
+
+	if validator, ok := out.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	return nil
+}
+
+// envKeys returns the key paths of the fields of t that can be set from a
+// single environment variable. Nested structs are walked; maps and slices of
+// structs are skipped.
+func envKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		key := prefix + name
+
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		switch {
+		case ft.Kind() == reflect.Struct && ft.PkgPath() != "time":
+			keys = append(keys, envKeys(ft, key+".")...)
+		case ft.Kind() == reflect.Map:
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Struct:
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type serverConfig struct {
+	Port    int           `yaml:"port"`
+	Timeout time.Duration `yaml:"timeout"`
+	Origins []string      `yaml:"origins"`
+}
+
+type appConfig struct {
+	Database database.Config `yaml:"database"`
+	Server   serverConfig    `yaml:"server"`
+	Debug    bool            `yaml:"debug"`
+}
+
+func (c *appConfig) Validate() error {
+	if c.Server.Port == 0 {
+		return errors.New("server port must be provided")
+	}
+	return c.Database.Validate()
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	yamlFile := writeConfigFile(t, "config.yaml", `
+database:
+  host: localhost
+  database: orders
+  user: admin
+  password: secret
+  max_conn_lifetime: 10m
+server:
+  port: 8080
+  timeout: 30s
+`)
+
+	t.Run("yaml with defaults", func(t *testing.T) {
+		cfg := appConfig{Database: database.DefaultConfig()}
+		require.NoError(t, Load(yamlFile, &cfg))
+
+		assert.Equal(t, "localhost", cfg.Database.Host)
+		assert.Equal(t, "orders", cfg.Database.Database)
+		assert.Equal(t, 10*time.Minute, cfg.Database.MaxConnLifetime)
+		assert.Equal(t, 8080, cfg.Server.Port)
+		assert.Equal(t, 30*time.Second, cfg.Server.Timeout)
+
+		// Values missing from the file keep their defaults
+		assert.Equal(t, 5432, cfg.Database.Port)
+		assert.Equal(t, "disable", cfg.Database.SSLMode)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		jsonFile := writeConfigFile(t, "config.json", `{
+			"database": {"host": "db", "port": 5433, "database": "orders", "user": "admin", "password": "secret"},
+			"server": {"port": 9090}
+		}`)
+
+		var cfg appConfig
+		require.NoError(t, Load(jsonFile, &cfg))
+		assert.Equal(t, "db", cfg.Database.Host)
+		assert.Equal(t, 5433, cfg.Database.Port)
+		assert.Equal(t, 9090, cfg.Server.Port)
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		t.Setenv("APP_DATABASE_HOST", "db.internal")
+		t.Setenv("APP_DATABASE_MAX_CONNS", "16")
+		t.Setenv("APP_SERVER_ORIGINS", "a.example.com,b.example.com")
+		t.Setenv("APP_DEBUG", "true")
+
+		cfg := appConfig{Database: database.DefaultConfig()}
+		require.NoError(t, LoadWithPrefix(yamlFile, "APP", &cfg))
+
+		assert.Equal(t, "db.internal", cfg.Database.Host)
+		assert.Equal(t, int32(16), cfg.Database.MaxConns)
+		assert.Equal(t, []string{"a.example.com", "b.example.com"}, cfg.Server.Origins)
+		assert.True(t, cfg.Debug)
+		assert.Equal(t, "orders", cfg.Database.Database)
+	})
+
+	t.Run("default prefix", func(t *testing.T) {
+		t.Setenv(DefaultOptions().EnvPrefix+"_SERVER_PORT", "7070")
+
+		cfg := appConfig{Database: database.DefaultConfig()}
+		require.NoError(t, Load(yamlFile, &cfg))
+		assert.Equal(t, 7070, cfg.Server.Port)
+	})
+
+	t.Run("environment only", func(t *testing.T) {
+		t.Setenv("APP_DATABASE_HOST", "db")
+		t.Setenv("APP_DATABASE_DATABASE", "orders")
+		t.Setenv("APP_DATABASE_USER", "admin")
+		t.Setenv("APP_DATABASE_PASSWORD", "secret")
+
+		cfg := database.DefaultConfig()
+		require.NoError(t, LoadWithPrefix("", "APP_DATABASE", &cfg))
+		assert.Equal(t, "db", cfg.Host)
+		assert.Equal(t, "secret", cfg.Password)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		file := writeConfigFile(t, "invalid.yaml", "server:\n  port: 8080\n")
+
+		var cfg appConfig
+		err := Load(file, &cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid config")
+		assert.Contains(t, err.Error(), "host must be provided")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var cfg appConfig
+		assert.Error(t, Load(filepath.Join(t.TempDir(), "missing.yaml"), &cfg))
+		assert.Error(t, Load(yamlFile, cfg))
+	})
+}
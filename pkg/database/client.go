@@ -2,7 +2,10 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/StackCatalyst/common-lib/pkg/metrics"
@@ -33,6 +36,31 @@ type Config struct {
 	MaxConnLifetime time.Duration `json:"max_conn_lifetime" yaml:"max_conn_lifetime"`
 	// MaxConnIdleTime is the maximum idle time of a connection
 	MaxConnIdleTime time.Duration `json:"max_conn_idle_time" yaml:"max_conn_idle_time"`
+	// ReplicaConfigs are optional read replicas used for read-only queries
+	ReplicaConfigs []Config `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+	// ReadFromReplicas routes Query and QueryRow to the replicas when any are configured
+	ReadFromReplicas bool `json:"read_from_replicas" yaml:"read_from_replicas"`
+	// Retry configures ExecWithRetry and QueryWithRetry
+	Retry RetryConfig `json:"retry" yaml:"retry"`
+}
+
+// RetryConfig holds the retry configuration for transient database errors
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts, including the first one
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts"`
+	// RetryWaitMin is the wait before the first retry; it doubles on each attempt
+	RetryWaitMin time.Duration `json:"retry_wait_min" yaml:"retry_wait_min"`
+	// RetryWaitMax is the maximum time to wait between retries
+	RetryWaitMax time.Duration `json:"retry_wait_max" yaml:"retry_wait_max"`
+}
+
+// DefaultRetryConfig returns the default retry configuration
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:  3,
+		RetryWaitMin: 50 * time.Millisecond,
+		RetryWaitMax: time.Second,
+	}
 }
 
 // DefaultConfig returns the default database configuration
@@ -45,6 +73,7 @@ func DefaultConfig() Config {
 		MinConns:        0,
 		MaxConnLifetime: time.Hour,
 		MaxConnIdleTime: 30 * time.Minute,
+		Retry:           DefaultRetryConfig(),
 	}
 }
 
@@ -68,13 +97,59 @@ func (c *Config) Validate() error {
 	if c.MaxConns < c.MinConns {
 		return fmt.Errorf("max_conns must be greater than or equal to min_conns")
 	}
+	for i := range c.ReplicaConfigs {
+		if err := c.ReplicaConfigs[i].Validate(); err != nil {
+			return fmt.Errorf("replica %d: %w", i, err)
+		}
+	}
 	return nil
 }
 
+// pool is the subset of pgxpool.Pool used by the client
+type pool interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Ping(ctx context.Context) error
+	Stat() *pgxpool.Stat
+	Close()
+}
+
+// Pool labels used in metrics
+const (
+	poolPrimary = "primary"
+	poolReplica = "replica"
+)
+
 // Client is a database client that provides connection management and metrics
 type Client struct {
-	pool    *pgxpool.Pool
-	metrics *MetricsReporter
+	pool             pool
+	replicas         []pool
+	nextReplica      atomic.Uint64
+	readFromReplicas bool
+	retry            RetryConfig
+	metrics          *MetricsReporter
+
+	stmtMu     sync.RWMutex
+	statements map[string]string
+
+	healthMu       sync.Mutex
+	exhaustedSince time.Time
+}
+
+// Health check settings
+const (
+	// healthCheckTimeout bounds how long HealthCheck waits for the database
+	healthCheckTimeout = 2 * time.Second
+	// poolExhaustedThreshold is how long every connection may stay acquired before the pool is reported unhealthy
+	poolExhaustedThreshold = 30 * time.Second
+)
+
+// acquirer is implemented by pools that hand out dedicated connections
+type acquirer interface {
+	Acquire(ctx context.Context) (*pgxpool.Conn, error)
 }
 
 // New creates a new database client
@@ -83,6 +158,35 @@ func New(config Config, metricsReporter *metrics.Reporter) (*Client, error) {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
+	primary, err := newPool(config)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := make([]pool, 0, len(config.ReplicaConfigs))
+	for i, replicaConfig := range config.ReplicaConfigs {
+		replica, err := newPool(replicaConfig)
+		if err != nil {
+			primary.Close()
+			for _, r := range replicas {
+				r.Close()
+			}
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		replicas = append(replicas, replica)
+	}
+
+	return &Client{
+		pool:             primary,
+		replicas:         replicas,
+		readFromReplicas: config.ReadFromReplicas,
+		retry:            config.Retry,
+		metrics:          NewMetricsReporter(metricsReporter),
+	}, nil
+}
+
+// newPool creates a connection pool for a single database server
+func newPool(config Config) (*pgxpool.Pool, error) {
 	connString := fmt.Sprintf(
 		"host=%s port=%d dbname=%s user=%s password=%s sslmode=%s",
 		config.Host,
@@ -108,17 +212,17 @@ func New(config Config, metricsReporter *metrics.Reporter) (*Client, error) {
 		return nil, fmt.Errorf("error creating connection pool: %w", err)
 	}
 
-	return &Client{
-		pool:    pool,
-		metrics: NewMetricsReporter(metricsReporter),
-	}, nil
+	return pool, nil
 }
 
-// Close closes the database client and its connection pool
+// Close closes the database client and its connection pools
 func (c *Client) Close() {
 	if c.pool != nil {
 		c.pool.Close()
 	}
+	for _, replica := range c.replicas {
+		replica.Close()
+	}
 }
 
 // Ping verifies a connection to the database is still alive
@@ -132,6 +236,58 @@ func (c *Client) Ping(ctx context.Context) error {
 	return err
 }
 
+// HealthCheck verifies the database answers a trivial query within a short
+// timeout and that the connection pool has not been exhausted for too long.
+// It is intended for readiness probes and updates the db_up gauge.
+func (c *Client) HealthCheck(ctx context.Context) error {
+	err := c.healthCheck(ctx)
+	c.metrics.SetUp(err == nil)
+	return err
+}
+
+func (c *Client) healthCheck(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	var one int
+	err := c.pool.QueryRow(ctx, "SELECT 1").Scan(&one)
+	c.metrics.ObserveQuery("health_check", err, time.Since(start))
+	if err != nil {
+		c.metrics.ObserveConnectionError("health_check")
+		return fmt.Errorf("database is unreachable: %w", err)
+	}
+
+	if stat := c.pool.Stat(); stat != nil {
+		if err := c.checkPoolExhaustion(stat.AcquiredConns(), stat.MaxConns(), time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkPoolExhaustion reports an error once every connection has been acquired
+// continuously for longer than poolExhaustedThreshold
+func (c *Client) checkPoolExhaustion(acquired, max int32, now time.Time) error {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	if max <= 0 || acquired < max {
+		c.exhaustedSince = time.Time{}
+		return nil
+	}
+
+	if c.exhaustedSince.IsZero() {
+		c.exhaustedSince = now
+	}
+
+	if exhaustedFor := now.Sub(c.exhaustedSince); exhaustedFor > poolExhaustedThreshold {
+		return fmt.Errorf("connection pool exhausted: all %d connections acquired for %s", max, exhaustedFor.Round(time.Second))
+	}
+	return nil
+}
+
 // Begin starts a new transaction
 func (c *Client) Begin(ctx context.Context) (pgx.Tx, error) {
 	start := time.Now()
@@ -154,30 +310,286 @@ func (c *Client) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx,
 	return tx, err
 }
 
-// Query executes a query that returns rows
+// WithTx runs fn inside a transaction, committing if fn succeeds and rolling
+// back if it returns an error or panics. Panics are re-raised after rollback.
+func (c *Client) WithTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	return c.WithTxOptions(ctx, pgx.TxOptions{}, fn)
+}
+
+// WithTxOptions is like WithTx but starts the transaction with the given options
+func (c *Client) WithTxOptions(ctx context.Context, txOptions pgx.TxOptions, fn func(pgx.Tx) error) error {
+	tx, err := c.BeginTx(ctx, txOptions)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			c.metrics.ObserveTransaction("panic")
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			c.metrics.ObserveTransaction("rollback_failed")
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		c.metrics.ObserveTransaction("rolled_back")
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.metrics.ObserveTransaction("commit_failed")
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	c.metrics.ObserveTransaction("committed")
+	return nil
+}
+
+// Query executes a query that returns rows, using a replica when read routing is enabled
 func (c *Client) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	if c.readFromReplicas {
+		return c.QueryReplica(ctx, sql, args...)
+	}
+	return c.query(ctx, c.pool, poolPrimary, sql, args...)
+}
+
+// QueryRow executes a query that is expected to return at most one row,
+// using a replica when read routing is enabled
+func (c *Client) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	if c.readFromReplicas {
+		return c.QueryRowReplica(ctx, sql, args...)
+	}
+	return c.queryRow(ctx, c.pool, poolPrimary, sql, args...)
+}
+
+// QueryReplica executes a query that returns rows on the next replica,
+// falling back to the primary when no replicas are configured
+func (c *Client) QueryReplica(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	p, label := c.readPool()
+	return c.query(ctx, p, label, sql, args...)
+}
+
+// QueryRowReplica executes a single-row query on the next replica,
+// falling back to the primary when no replicas are configured
+func (c *Client) QueryRowReplica(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	p, label := c.readPool()
+	return c.queryRow(ctx, p, label, sql, args...)
+}
+
+func (c *Client) query(ctx context.Context, p pool, label, sql string, args ...interface{}) (pgx.Rows, error) {
+	finish := startQuerySpan(ctx, label, "query", sql)
 	start := time.Now()
-	rows, err := c.pool.Query(ctx, sql, args...)
-	c.metrics.ObserveQuery("query", err, time.Since(start))
+	rows, err := p.Query(ctx, sql, args...)
+	c.metrics.ObservePoolQuery(label, "query", err, time.Since(start))
+	finish(err)
 	return rows, err
 }
 
-// QueryRow executes a query that is expected to return at most one row
-func (c *Client) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+func (c *Client) queryRow(ctx context.Context, p pool, label, sql string, args ...interface{}) pgx.Row {
+	finish := startQuerySpan(ctx, label, "query_row", sql)
 	start := time.Now()
-	row := c.pool.QueryRow(ctx, sql, args...)
-	c.metrics.ObserveQuery("query_row", nil, time.Since(start))
+	row := p.QueryRow(ctx, sql, args...)
+	c.metrics.ObservePoolQuery(label, "query_row", nil, time.Since(start))
+	finish(nil)
 	return row
 }
 
+// readPool selects the next replica in round-robin order, or the primary if there are none
+func (c *Client) readPool() (pool, string) {
+	if len(c.replicas) == 0 {
+		return c.pool, poolPrimary
+	}
+	n := c.nextReplica.Add(1) - 1
+	return c.replicas[n%uint64(len(c.replicas))], poolReplica
+}
+
 // Exec executes a query that doesn't return rows
 func (c *Client) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	finish := startQuerySpan(ctx, poolPrimary, "exec", sql)
 	start := time.Now()
 	tag, err := c.pool.Exec(ctx, sql, args...)
 	c.metrics.ObserveQuery("exec", err, time.Since(start))
+	finish(err)
+	return tag, err
+}
+
+// Prepare registers a named statement for use with QueryPrepared and
+// ExecPrepared. The SQL is validated against the server immediately; each
+// pooled connection then prepares it once on first use and reuses the
+// server-side statement for later calls.
+func (c *Client) Prepare(ctx context.Context, name, sql string) error {
+	if name == "" {
+		return fmt.Errorf("statement name must be provided")
+	}
+
+	if a, ok := c.pool.(acquirer); ok {
+		start := time.Now()
+		conn, err := a.Acquire(ctx)
+		if err != nil {
+			c.metrics.ObserveConnectionError("acquire")
+			return fmt.Errorf("failed to acquire connection: %w", err)
+		}
+		// An anonymous statement validates the SQL without leaving it allocated
+		_, err = conn.Conn().Prepare(ctx, "", sql)
+		conn.Release()
+		c.metrics.ObserveQuery("prepare", err, time.Since(start))
+		if err != nil {
+			return fmt.Errorf("failed to prepare statement %q: %w", name, err)
+		}
+	}
+
+	c.stmtMu.Lock()
+	if c.statements == nil {
+		c.statements = make(map[string]string)
+	}
+	c.statements[name] = sql
+	c.stmtMu.Unlock()
+
+	return nil
+}
+
+// QueryPrepared executes a named statement that returns rows, following the same routing as Query
+func (c *Client) QueryPrepared(ctx context.Context, name string, args ...interface{}) (pgx.Rows, error) {
+	sql, err := c.statement(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p, label := c.pool, poolPrimary
+	if c.readFromReplicas {
+		p, label = c.readPool()
+	}
+
+	finish := startQuerySpan(ctx, label, "query", sql)
+	start := time.Now()
+	rows, err := p.Query(ctx, sql, append([]interface{}{pgx.QueryExecModeCacheStatement}, args...)...)
+	c.metrics.ObservePoolQuery(label, name, err, time.Since(start))
+	finish(err)
+	return rows, err
+}
+
+// ExecPrepared executes a named statement that doesn't return rows on the primary
+func (c *Client) ExecPrepared(ctx context.Context, name string, args ...interface{}) (pgconn.CommandTag, error) {
+	sql, err := c.statement(name)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	finish := startQuerySpan(ctx, poolPrimary, "exec", sql)
+	start := time.Now()
+	tag, err := c.pool.Exec(ctx, sql, append([]interface{}{pgx.QueryExecModeCacheStatement}, args...)...)
+	c.metrics.ObservePoolQuery(poolPrimary, name, err, time.Since(start))
+	finish(err)
 	return tag, err
 }
 
+// statement looks up the SQL of a prepared statement by name
+func (c *Client) statement(name string) (string, error) {
+	c.stmtMu.RLock()
+	sql, ok := c.statements[name]
+	c.stmtMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("statement %q is not prepared", name)
+	}
+	return sql, nil
+}
+
+// ExecWithRetry executes a query that doesn't return rows, retrying transient errors
+func (c *Client) ExecWithRetry(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	var tag pgconn.CommandTag
+	err := c.withRetry(ctx, func() error {
+		var err error
+		tag, err = c.Exec(ctx, sql, args...)
+		return err
+	})
+	return tag, err
+}
+
+// QueryWithRetry executes a query and collects its rows with fn, retrying
+// transient errors. The rows are read within each attempt, so errors that only
+// surface while iterating, such as a serialization failure, are retried too:
+//
+//	names, err := database.QueryWithRetry(ctx, client, pgx.RowTo[string], "SELECT name FROM modules")
+func QueryWithRetry[T any](ctx context.Context, c *Client, fn pgx.RowToFunc[T], sql string, args ...interface{}) ([]T, error) {
+	var collected []T
+	err := c.withRetry(ctx, func() error {
+		rows, err := c.Query(ctx, sql, args...)
+		if err != nil {
+			return err
+		}
+		collected, err = pgx.CollectRows(rows, fn)
+		return err
+	})
+	return collected, err
+}
+
+// withRetry runs op until it succeeds, fails with a non-transient error,
+// runs out of attempts, or the context is done. An error for a done context
+// wraps both the context's error and op's last error.
+func (c *Client) withRetry(ctx context.Context, op func() error) error {
+	attempts := c.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		err = op()
+		if err == nil || !IsTransient(err) || i == attempts-1 {
+			return err
+		}
+
+		c.metrics.ObserveRetry()
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %w)", ctx.Err(), err)
+		case <-time.After(c.getRetryBackoff(i)):
+		}
+	}
+	return err
+}
+
+// getRetryBackoff returns the backoff duration for a retry attempt
+func (c *Client) getRetryBackoff(attempt int) time.Duration {
+	backoff := c.retry.RetryWaitMin << attempt
+	if backoff <= 0 || (c.retry.RetryWaitMax > 0 && backoff > c.retry.RetryWaitMax) {
+		backoff = c.retry.RetryWaitMax
+	}
+	return backoff
+}
+
+// Transient SQLSTATE codes that are safe to retry
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// IsTransient reports whether err is a transient database error that may
+// succeed on retry: serialization failures, deadlocks, and connection errors
+// that occurred before any data was sent
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == sqlStateSerializationFailure || pgErr.Code == sqlStateDeadlockDetected
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	return pgconn.SafeToRetry(err)
+}
+
 // UpdatePoolStats updates the pool statistics metrics
 func (c *Client) UpdatePoolStats() {
 	stats := c.pool.Stat()
@@ -1,10 +1,14 @@
 package database
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/StackCatalyst/common-lib/pkg/metrics"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,6 +23,160 @@ func newTestMetricsReporter() *metrics.Reporter {
 	})
 }
 
+// fakePool implements the pool interface and records which operations it served
+type fakePool struct {
+	calls map[string]int
+	tx    *fakeTx
+	errs  []error // returned, in order, by Exec and Query before succeeding
+	// rowErrs are returned, in order, by the Err of rows from successful queries
+	rowErrs []error
+	sql     []string
+	args    [][]interface{}
+	hang    bool // QueryRow blocks until the context is done, simulating an unreachable database
+}
+
+func (p *fakePool) record(sql string, args []interface{}) {
+	p.sql = append(p.sql, sql)
+	p.args = append(p.args, args)
+}
+
+func (p *fakePool) nextErr() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	return err
+}
+
+func newFakePool() *fakePool {
+	return &fakePool{calls: make(map[string]int), tx: &fakeTx{}}
+}
+
+func (p *fakePool) Begin(ctx context.Context) (pgx.Tx, error) {
+	p.calls["begin"]++
+	return p.tx, nil
+}
+
+func (p *fakePool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	p.calls["begin"]++
+	return p.tx, nil
+}
+
+func (p *fakePool) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	p.calls["query"]++
+	p.record(sql, args)
+	if err := p.nextErr(); err != nil {
+		return nil, err
+	}
+	rows := &fakeRows{values: []int{1, 2}}
+	if len(p.rowErrs) > 0 {
+		rows.err = p.rowErrs[0]
+		p.rowErrs = p.rowErrs[1:]
+	}
+	return rows, nil
+}
+
+// fakeRows implements pgx.Rows over a fixed set of integers, failing with err
+// after they have been read
+type fakeRows struct {
+	pgx.Rows
+	values []int
+	next   int
+	err    error
+}
+
+func (r *fakeRows) Next() bool {
+	if r.next >= len(r.values) {
+		return false
+	}
+	r.next++
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = r.values[r.next-1]
+	return nil
+}
+
+func (r *fakeRows) Err() error {
+	if r.next < len(r.values) {
+		return nil
+	}
+	return r.err
+}
+
+func (r *fakeRows) Close() {}
+
+func (r *fakeRows) CommandTag() pgconn.CommandTag {
+	return pgconn.NewCommandTag("SELECT 2")
+}
+
+func (p *fakePool) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	p.calls["query_row"]++
+	if p.hang {
+		<-ctx.Done()
+		return fakeRow{err: ctx.Err()}
+	}
+	return fakeRow{}
+}
+
+// fakeRow implements pgx.Row, scanning 1 into every destination
+type fakeRow struct {
+	err error
+}
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	for _, d := range dest {
+		if n, ok := d.(*int); ok {
+			*n = 1
+		}
+	}
+	return nil
+}
+
+func (p *fakePool) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	p.calls["exec"]++
+	p.record(sql, args)
+	if err := p.nextErr(); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (p *fakePool) Ping(ctx context.Context) error {
+	p.calls["ping"]++
+	return nil
+}
+
+func (p *fakePool) Stat() *pgxpool.Stat {
+	return nil
+}
+
+func (p *fakePool) Close() {
+	p.calls["close"]++
+}
+
+// fakeTx implements pgx.Tx and records whether it was committed or rolled back
+type fakeTx struct {
+	pgx.Tx
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
 func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
 	assert.Equal(t, "localhost", cfg.Host)
@@ -172,6 +330,377 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestReplicaRouting(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reads round-robin across replicas", func(t *testing.T) {
+		primary := newFakePool()
+		replica1 := newFakePool()
+		replica2 := newFakePool()
+		client := &Client{
+			pool:             primary,
+			replicas:         []pool{replica1, replica2},
+			readFromReplicas: true,
+			metrics:          NewMetricsReporter(newTestMetricsReporter()),
+		}
+
+		for i := 0; i < 4; i++ {
+			_, err := client.Query(ctx, "SELECT 1")
+			require.NoError(t, err)
+		}
+		client.QueryRow(ctx, "SELECT 1")
+		client.QueryRow(ctx, "SELECT 1")
+
+		assert.Equal(t, 2, replica1.calls["query"])
+		assert.Equal(t, 2, replica2.calls["query"])
+		assert.Equal(t, 1, replica1.calls["query_row"])
+		assert.Equal(t, 1, replica2.calls["query_row"])
+		assert.Equal(t, 0, primary.calls["query"])
+		assert.Equal(t, 0, primary.calls["query_row"])
+
+		// Writes always hit the primary
+		_, err := client.Exec(ctx, "UPDATE t SET x = 1")
+		require.NoError(t, err)
+		_, err = client.Begin(ctx)
+		require.NoError(t, err)
+		_, err = client.BeginTx(ctx, pgx.TxOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, primary.calls["exec"])
+		assert.Equal(t, 2, primary.calls["begin"])
+		assert.Equal(t, 0, replica1.calls["exec"]+replica2.calls["exec"])
+		assert.Equal(t, 0, replica1.calls["begin"]+replica2.calls["begin"])
+
+		client.Close()
+		assert.Equal(t, 1, primary.calls["close"])
+		assert.Equal(t, 1, replica1.calls["close"])
+		assert.Equal(t, 1, replica2.calls["close"])
+	})
+
+	t.Run("reads stay on primary unless enabled", func(t *testing.T) {
+		primary := newFakePool()
+		replica := newFakePool()
+		client := &Client{
+			pool:     primary,
+			replicas: []pool{replica},
+			metrics:  NewMetricsReporter(newTestMetricsReporter()),
+		}
+
+		_, err := client.Query(ctx, "SELECT 1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, primary.calls["query"])
+
+		// Explicit replica queries still use the replica
+		_, err = client.QueryReplica(ctx, "SELECT 1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, replica.calls["query"])
+	})
+
+	t.Run("falls back to primary without replicas", func(t *testing.T) {
+		primary := newFakePool()
+		client := &Client{
+			pool:             primary,
+			readFromReplicas: true,
+			metrics:          NewMetricsReporter(newTestMetricsReporter()),
+		}
+
+		_, err := client.QueryReplica(ctx, "SELECT 1")
+		require.NoError(t, err)
+		client.QueryRow(ctx, "SELECT 1")
+
+		assert.Equal(t, 1, primary.calls["query"])
+		assert.Equal(t, 1, primary.calls["query_row"])
+	})
+}
+
+func TestWithTx(t *testing.T) {
+	ctx := context.Background()
+	newClient := func() (*Client, *fakePool) {
+		primary := newFakePool()
+		return &Client{
+			pool:    primary,
+			metrics: NewMetricsReporter(newTestMetricsReporter()),
+		}, primary
+	}
+
+	t.Run("commits on success", func(t *testing.T) {
+		client, primary := newClient()
+
+		err := client.WithTx(ctx, func(tx pgx.Tx) error {
+			assert.Same(t, primary.tx, tx)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, primary.tx.committed)
+		assert.False(t, primary.tx.rolledBack)
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		client, primary := newClient()
+
+		err := client.WithTxOptions(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(tx pgx.Tx) error {
+			return assert.AnError
+		})
+		require.ErrorIs(t, err, assert.AnError)
+		assert.False(t, primary.tx.committed)
+		assert.True(t, primary.tx.rolledBack)
+	})
+
+	t.Run("rolls back and re-panics on panic", func(t *testing.T) {
+		client, primary := newClient()
+
+		assert.PanicsWithValue(t, "boom", func() {
+			_ = client.WithTx(ctx, func(tx pgx.Tx) error {
+				panic("boom")
+			})
+		})
+		assert.False(t, primary.tx.committed)
+		assert.True(t, primary.tx.rolledBack)
+	})
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	serializationFailure := &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+	deadlock := &pgconn.PgError{Code: "40P01", Message: "deadlock detected"}
+	uniqueViolation := &pgconn.PgError{Code: "23505", Message: "duplicate key"}
+
+	newClient := func(errs ...error) (*Client, *fakePool) {
+		primary := newFakePool()
+		primary.errs = errs
+		return &Client{
+			pool: primary,
+			retry: RetryConfig{
+				MaxAttempts:  3,
+				RetryWaitMin: time.Millisecond,
+				RetryWaitMax: 5 * time.Millisecond,
+			},
+			metrics: NewMetricsReporter(newTestMetricsReporter()),
+		}, primary
+	}
+
+	t.Run("exec succeeds after transient failures", func(t *testing.T) {
+		client, primary := newClient(serializationFailure, deadlock)
+
+		tag, err := client.ExecWithRetry(ctx, "UPDATE t SET x = 1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), tag.RowsAffected())
+		assert.Equal(t, 3, primary.calls["exec"])
+	})
+
+	t.Run("query succeeds after transient failure", func(t *testing.T) {
+		client, primary := newClient(serializationFailure)
+
+		values, err := QueryWithRetry(ctx, client, pgx.RowTo[int], "SELECT 1 FOR UPDATE")
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, values)
+		assert.Equal(t, 2, primary.calls["query"])
+	})
+
+	t.Run("query retries failures while reading rows", func(t *testing.T) {
+		client, primary := newClient()
+		primary.rowErrs = []error{serializationFailure}
+
+		values, err := QueryWithRetry(ctx, client, pgx.RowTo[int], "SELECT x FROM t")
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, values)
+		assert.Equal(t, 2, primary.calls["query"])
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		client, primary := newClient(serializationFailure, serializationFailure, serializationFailure, serializationFailure)
+
+		_, err := client.ExecWithRetry(ctx, "UPDATE t SET x = 1")
+		require.ErrorIs(t, err, serializationFailure)
+		assert.Equal(t, 3, primary.calls["exec"])
+	})
+
+	t.Run("does not retry non-transient errors", func(t *testing.T) {
+		client, primary := newClient(uniqueViolation)
+
+		_, err := client.ExecWithRetry(ctx, "INSERT INTO t VALUES (1)")
+		require.ErrorIs(t, err, uniqueViolation)
+		assert.Equal(t, 1, primary.calls["exec"])
+	})
+
+	t.Run("honors context cancellation", func(t *testing.T) {
+		client, primary := newClient(serializationFailure, serializationFailure)
+		client.retry.RetryWaitMin = time.Hour
+		client.retry.RetryWaitMax = time.Hour
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		_, err := client.ExecWithRetry(ctx, "UPDATE t SET x = 1")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, err, serializationFailure, "the last transient error is kept")
+		assert.Equal(t, 1, primary.calls["exec"])
+	})
+}
+
+func TestPreparedStatements(t *testing.T) {
+	ctx := context.Background()
+	registry := prometheus.NewRegistry()
+	primary := newFakePool()
+	client := &Client{
+		pool: primary,
+		metrics: NewMetricsReporter(metrics.New(metrics.Options{
+			Namespace: "test",
+			Subsystem: "database",
+			Registry:  registry,
+		})),
+	}
+
+	const getUser = "SELECT name FROM users WHERE id = $1"
+	require.NoError(t, client.Prepare(ctx, "get_user", getUser))
+	require.NoError(t, client.Prepare(ctx, "touch_user", "UPDATE users SET seen = now() WHERE id = $1"))
+
+	for i := 0; i < 3; i++ {
+		_, err := client.QueryPrepared(ctx, "get_user", i)
+		require.NoError(t, err)
+	}
+	_, err := client.ExecPrepared(ctx, "touch_user", 1)
+	require.NoError(t, err)
+
+	// Every call sends the same SQL in statement-cache mode so pgx reuses the
+	// server-side prepared statement instead of re-parsing
+	require.Len(t, primary.sql, 4)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, getUser, primary.sql[i])
+		assert.Equal(t, []interface{}{pgx.QueryExecModeCacheStatement, i}, primary.args[i])
+	}
+	assert.Equal(t, pgx.QueryExecModeCacheStatement, primary.args[3][0])
+
+	// Metrics are labelled with the statement name, not the SQL
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	types := make(map[string]float64)
+	for _, m := range families {
+		if m.GetName() != "test_database_database_query_executions_total" {
+			continue
+		}
+		for _, metric := range m.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "type" {
+					types[label.GetValue()] += metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	assert.Equal(t, float64(3), types["get_user"])
+	assert.Equal(t, float64(1), types["touch_user"])
+	assert.NotContains(t, types, getUser)
+
+	// Unknown statements are rejected
+	_, err = client.QueryPrepared(ctx, "missing")
+	assert.Error(t, err)
+	_, err = client.ExecPrepared(ctx, "missing")
+	assert.Error(t, err)
+	assert.Error(t, client.Prepare(ctx, "", getUser))
+}
+
+func BenchmarkQueryPrepared(b *testing.B) {
+	ctx := context.Background()
+	client := &Client{
+		pool:    newFakePool(),
+		metrics: NewMetricsReporter(newTestMetricsReporter()),
+	}
+	require.NoError(b, client.Prepare(ctx, "get_user", "SELECT name FROM users WHERE id = $1"))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = client.QueryPrepared(ctx, "get_user", i)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	newClient := func(p *fakePool) (*Client, *prometheus.Registry) {
+		registry := prometheus.NewRegistry()
+		return &Client{
+			pool: p,
+			metrics: NewMetricsReporter(metrics.New(metrics.Options{
+				Namespace: "test",
+				Subsystem: "database",
+				Registry:  registry,
+			})),
+		}, registry
+	}
+	dbUp := func(t *testing.T, registry *prometheus.Registry) float64 {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		for _, m := range families {
+			if m.GetName() == "test_database_db_up" {
+				return m.GetMetric()[0].GetGauge().GetValue()
+			}
+		}
+		t.Fatal("db_up gauge not found")
+		return 0
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		client, registry := newClient(newFakePool())
+		require.NoError(t, client.HealthCheck(context.Background()))
+		assert.Equal(t, 1.0, dbUp(t, registry))
+	})
+
+	t.Run("unavailable database fails promptly", func(t *testing.T) {
+		p := newFakePool()
+		p.hang = true
+		client, registry := newClient(p)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := client.HealthCheck(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unreachable")
+		assert.Less(t, time.Since(start), healthCheckTimeout)
+		assert.Equal(t, 0.0, dbUp(t, registry))
+	})
+
+	t.Run("pool exhaustion", func(t *testing.T) {
+		client, _ := newClient(newFakePool())
+		now := time.Now()
+
+		assert.NoError(t, client.checkPoolExhaustion(4, 4, now))
+		assert.NoError(t, client.checkPoolExhaustion(4, 4, now.Add(poolExhaustedThreshold/2)))
+		err := client.checkPoolExhaustion(4, 4, now.Add(poolExhaustedThreshold+time.Second))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exhausted")
+
+		// A free connection resets the window
+		assert.NoError(t, client.checkPoolExhaustion(3, 4, now.Add(poolExhaustedThreshold+2*time.Second)))
+		assert.NoError(t, client.checkPoolExhaustion(4, 4, now.Add(poolExhaustedThreshold+3*time.Second)))
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, IsTransient(&pgconn.PgError{Code: "40001"}))
+	assert.True(t, IsTransient(&pgconn.PgError{Code: "40P01"}))
+	assert.False(t, IsTransient(&pgconn.PgError{Code: "23505"}))
+	assert.False(t, IsTransient(context.Canceled))
+	assert.False(t, IsTransient(assert.AnError))
+	assert.False(t, IsTransient(nil))
+}
+
+func TestReplicaConfigValidation(t *testing.T) {
+	config := Config{
+		Host:     "localhost",
+		Port:     5432,
+		Database: "test",
+		User:     "user",
+		Password: "pass",
+		ReplicaConfigs: []Config{
+			{Host: "replica", Port: 5432, Database: "test", User: "user"},
+		},
+	}
+
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "replica 0")
+}
+
 func TestMetricsReporter(t *testing.T) {
 	reporter := NewMetricsReporter(newTestMetricsReporter())
 	require.NotNil(t, reporter)
@@ -13,6 +13,9 @@ type MetricsReporter struct {
 	queryLatency     *prometheus.HistogramVec
 	connectionErrors *prometheus.CounterVec
 	poolStats        *prometheus.GaugeVec
+	transactions     *prometheus.CounterVec
+	retries          *prometheus.CounterVec
+	up               *prometheus.GaugeVec
 }
 
 // NewMetricsReporter creates a new database metrics reporter
@@ -21,12 +24,12 @@ func NewMetricsReporter(reporter *metrics.Reporter) *MetricsReporter {
 		queryExecutions: reporter.Counter(
 			"database_query_executions_total",
 			"Total number of database query executions",
-			[]string{"type", "status"},
+			[]string{"pool", "type", "status"},
 		),
 		queryLatency: reporter.Histogram(
 			"database_query_duration_seconds",
 			"Database query duration in seconds",
-			[]string{"type"},
+			[]string{"pool", "type"},
 			[]float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
 		),
 		connectionErrors: reporter.Counter(
@@ -39,17 +42,37 @@ func NewMetricsReporter(reporter *metrics.Reporter) *MetricsReporter {
 			"Database connection pool statistics",
 			[]string{"type"},
 		),
+		transactions: reporter.Counter(
+			"database_transactions_total",
+			"Total number of managed database transactions by outcome",
+			[]string{"outcome"},
+		),
+		retries: reporter.Counter(
+			"database_retries_total",
+			"Total number of retried database operations",
+			[]string{},
+		),
+		up: reporter.Gauge(
+			"db_up",
+			"Whether the database passed its last health check (1) or not (0)",
+			[]string{},
+		),
 	}
 }
 
-// ObserveQuery records a query execution
+// ObserveQuery records a query execution against the primary
 func (m *MetricsReporter) ObserveQuery(queryType string, err error, duration time.Duration) {
+	m.ObservePoolQuery(poolPrimary, queryType, err, duration)
+}
+
+// ObservePoolQuery records a query execution against the named pool (primary or replica)
+func (m *MetricsReporter) ObservePoolQuery(pool, queryType string, err error, duration time.Duration) {
 	status := "success"
 	if err != nil {
 		status = "failure"
 	}
-	m.queryExecutions.WithLabelValues(queryType, status).Inc()
-	m.queryLatency.WithLabelValues(queryType).Observe(duration.Seconds())
+	m.queryExecutions.WithLabelValues(pool, queryType, status).Inc()
+	m.queryLatency.WithLabelValues(pool, queryType).Observe(duration.Seconds())
 }
 
 // ObserveConnectionError records a connection error
@@ -57,6 +80,25 @@ func (m *MetricsReporter) ObserveConnectionError(errorType string) {
 	m.connectionErrors.WithLabelValues(errorType).Inc()
 }
 
+// ObserveTransaction records the outcome of a managed transaction
+func (m *MetricsReporter) ObserveTransaction(outcome string) {
+	m.transactions.WithLabelValues(outcome).Inc()
+}
+
+// ObserveRetry records a retry of a transient failure
+func (m *MetricsReporter) ObserveRetry() {
+	m.retries.WithLabelValues().Inc()
+}
+
+// SetUp records the result of the last health check
+func (m *MetricsReporter) SetUp(up bool) {
+	value := 0.0
+	if up {
+		value = 1
+	}
+	m.up.WithLabelValues().Set(value)
+}
+
 // SetPoolStats sets the current pool statistics
 func (m *MetricsReporter) SetPoolStats(totalConns, idleConns, inUseConns int64) {
 	m.poolStats.WithLabelValues("total").Set(float64(totalConns))
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// migrationLockID is the advisory lock key that serializes concurrent Migrate calls
+const migrationLockID = 7_362_118_410
+
+// migration is a single SQL migration file
+type migration struct {
+	version  string
+	sql      string
+	checksum string
+}
+
+// Migrate applies pending .sql migrations from dir in fsys, in lexical file
+// name order (e.g. 0001_init.sql, 0002_add_index.sql). Applied versions and
+// their checksums are tracked in the schema_migrations table; already-applied
+// files are skipped, and an error is returned if one has been modified since
+// it was applied. All pending migrations run in a single transaction.
+func (c *Client) Migrate(ctx context.Context, fsys fs.FS, dir string) error {
+	migrations, err := loadMigrations(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	return c.WithTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockID); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+
+		_, err := tx.Exec(ctx, `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version TEXT PRIMARY KEY,
+				checksum TEXT NOT NULL,
+				applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+			)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to create schema_migrations table: %w", err)
+		}
+
+		applied, err := appliedMigrations(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if checksum, ok := applied[m.version]; ok {
+				if checksum != m.checksum {
+					return fmt.Errorf("migration %s has changed since it was applied", m.version)
+				}
+				continue
+			}
+
+			if _, err := tx.Exec(ctx, m.sql, pgx.QueryExecModeSimpleProtocol); err != nil {
+				return fmt.Errorf("failed to apply migration %s: %w", m.version, err)
+			}
+
+			_, err := tx.Exec(ctx,
+				"INSERT INTO schema_migrations (version, checksum) VALUES ($1, $2)",
+				m.version, m.checksum,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to record migration %s: %w", m.version, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// loadMigrations reads the .sql files in dir, sorted by file name
+func loadMigrations(fsys fs.FS, dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var migrations []migration
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		sum := sha256.Sum256(data)
+		migrations = append(migrations, migration{
+			version:  strings.TrimSuffix(entry.Name(), ".sql"),
+			sql:      string(data),
+			checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+// appliedMigrations returns the checksums of already-applied migrations keyed by version
+func appliedMigrations(ctx context.Context, tx pgx.Tx) (map[string]string, error) {
+	rows, err := tx.Query(ctx, "SELECT version, checksum FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]string)
+	for rows.Next() {
+		var version, checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = checksum
+	}
+
+	return applied, rows.Err()
+}
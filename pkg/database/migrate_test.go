@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"os/exec"
+	"strconv"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	commontesting "github.com/StackCatalyst/common-lib/pkg/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//go:embed testdata/migrations/*.sql
+var testMigrations embed.FS
+
+func isDockerAvailable() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx, "docker", "info").Run() == nil
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0002_second.sql": {Data: []byte("SELECT 2;")},
+		"migrations/0001_first.sql":  {Data: []byte("SELECT 1;")},
+		"migrations/README.md":       {Data: []byte("not a migration")},
+	}
+
+	migrations, err := loadMigrations(fsys, "migrations")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, "0001_first", migrations[0].version)
+	assert.Equal(t, "0002_second", migrations[1].version)
+	assert.Equal(t, "SELECT 1;", migrations[0].sql)
+	assert.Len(t, migrations[0].checksum, 64)
+	assert.NotEqual(t, migrations[0].checksum, migrations[1].checksum)
+
+	embedded, err := loadMigrations(testMigrations, "testdata/migrations")
+	require.NoError(t, err)
+	assert.Len(t, embedded, 2)
+
+	_, err = loadMigrations(fsys, "missing")
+	assert.Error(t, err)
+}
+
+func TestMigrate(t *testing.T) {
+	if !isDockerAvailable() {
+		t.Skip("Docker is not available")
+	}
+
+	ctx := context.Background()
+	config := startPostgres(t, ctx)
+
+	client, err := New(config, newTestMetricsReporter())
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Applies both migrations
+	require.NoError(t, client.Migrate(ctx, testMigrations, "testdata/migrations"))
+	_, err = client.Exec(ctx, "INSERT INTO widgets (name, color) VALUES ('a', 'red')")
+	require.NoError(t, err)
+
+	var applied int
+	require.NoError(t, client.QueryRow(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&applied))
+	assert.Equal(t, 2, applied)
+
+	// Re-running is a no-op
+	require.NoError(t, client.Migrate(ctx, testMigrations, "testdata/migrations"))
+	require.NoError(t, client.QueryRow(ctx, "SELECT COUNT(*) FROM schema_migrations").Scan(&applied))
+	assert.Equal(t, 2, applied)
+
+	// Modifying an applied migration is detected
+	changed := fstest.MapFS{
+		"migrations/0001_create_widgets.sql":   {Data: []byte("CREATE TABLE widgets (id INT);")},
+		"migrations/0002_add_widget_color.sql": {Data: mustReadFile(t, "testdata/migrations/0002_add_widget_color.sql")},
+	}
+	err = client.Migrate(ctx, changed, "migrations")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "0001_create_widgets has changed")
+}
+
+// startPostgres starts a Postgres container for the test and returns a config pointing at it
+func startPostgres(t *testing.T, ctx context.Context) Config {
+	container, err := commontesting.PostgresContainer(ctx, commontesting.PostgresConfig{})
+	require.NoError(t, err)
+	t.Cleanup(func() { container.Stop(ctx) })
+
+	host, err := container.GetHost(ctx)
+	require.NoError(t, err)
+	port, err := container.GetHostPort(ctx, "5432/tcp")
+	require.NoError(t, err)
+
+	config := DefaultConfig()
+	config.Host = host
+	config.Database = "test"
+	config.User = "test"
+	config.Password = "test"
+	config.Port, err = strconv.Atoi(port)
+	require.NoError(t, err)
+	return config
+}
+
+func mustReadFile(t *testing.T, name string) []byte {
+	data, err := testMigrations.ReadFile(name)
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Notification is a message received on a Postgres LISTEN channel
+type Notification struct {
+	Channel string
+	Payload string
+	PID     uint32 // Backend process ID of the notifying session
+}
+
+// listenReconnectWait is the fallback delay between reconnect attempts when no retry wait is configured
+const listenReconnectWait = time.Second
+
+// Listen subscribes to a Postgres notification channel on a dedicated
+// connection and streams notifications until ctx is cancelled, at which point
+// the returned channel is closed. If the connection is lost, Listen reconnects
+// and re-issues LISTEN; notifications sent while disconnected are lost.
+func (c *Client) Listen(ctx context.Context, channel string) (<-chan Notification, error) {
+	if channel == "" {
+		return nil, fmt.Errorf("channel must be provided")
+	}
+
+	a, ok := c.pool.(acquirer)
+	if !ok {
+		return nil, fmt.Errorf("listen is not supported by this pool")
+	}
+
+	conn, err := c.listen(ctx, a, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make(chan Notification)
+	go c.receive(ctx, a, conn, channel, notifications)
+	return notifications, nil
+}
+
+// listen acquires a dedicated connection and issues LISTEN on it. The
+// connection is hijacked from the pool so it is never handed out to other
+// callers while subscribed.
+func (c *Client) listen(ctx context.Context, a acquirer, channel string) (*pgx.Conn, error) {
+	pooled, err := a.Acquire(ctx)
+	if err != nil {
+		c.metrics.ObserveConnectionError("acquire")
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	conn := pooled.Hijack()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		conn.Close(context.Background())
+		return nil, fmt.Errorf("failed to listen on channel %q: %w", channel, err)
+	}
+	return conn, nil
+}
+
+// receive forwards notifications from conn until ctx is done, reconnecting on connection loss
+func (c *Client) receive(ctx context.Context, a acquirer, conn *pgx.Conn, channel string, out chan<- Notification) {
+	defer close(out)
+	defer func() {
+		if conn != nil {
+			conn.Close(context.Background())
+		}
+	}()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			c.metrics.ObserveConnectionError("listen")
+			conn.Close(context.Background())
+			if conn = c.reconnect(ctx, a, channel); conn == nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case out <- Notification{Channel: n.Channel, Payload: n.Payload, PID: n.PID}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reconnect re-establishes a listening connection with backoff, returning nil once ctx is done
+func (c *Client) reconnect(ctx context.Context, a acquirer, channel string) *pgx.Conn {
+	for attempt := 0; ; attempt++ {
+		wait := c.getRetryBackoff(attempt)
+		if wait <= 0 {
+			wait = listenReconnectWait
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+
+		conn, err := c.listen(ctx, a, channel)
+		if err == nil {
+			return conn
+		}
+		c.metrics.ObserveConnectionError("listen")
+	}
+}
+
+// Notify sends a notification with the given payload on a Postgres channel
+func (c *Client) Notify(ctx context.Context, channel, payload string) error {
+	if _, err := c.Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload); err != nil {
+		return fmt.Errorf("failed to notify channel %q: %w", channel, err)
+	}
+	return nil
+}
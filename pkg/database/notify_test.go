@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	primary := newFakePool()
+	client := &Client{pool: primary, metrics: NewMetricsReporter(newTestMetricsReporter())}
+
+	require.NoError(t, client.Notify(context.Background(), "cache_invalidation", "module:a@1.0.0"))
+	assert.Equal(t, []string{"SELECT pg_notify($1, $2)"}, primary.sql)
+	assert.Equal(t, []interface{}{"cache_invalidation", "module:a@1.0.0"}, primary.args[0])
+
+	primary.errs = []error{assert.AnError}
+	err := client.Notify(context.Background(), "cache_invalidation", "x")
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestListenValidation(t *testing.T) {
+	client := &Client{pool: newFakePool(), metrics: NewMetricsReporter(newTestMetricsReporter())}
+
+	_, err := client.Listen(context.Background(), "")
+	assert.Error(t, err)
+
+	// The fake pool cannot hand out dedicated connections
+	_, err = client.Listen(context.Background(), "events")
+	assert.Error(t, err)
+}
+
+func TestListen(t *testing.T) {
+	if !isDockerAvailable() {
+		t.Skip("Docker is not available")
+	}
+
+	ctx := context.Background()
+	config := startPostgres(t, ctx)
+
+	listener, err := New(config, newTestMetricsReporter())
+	require.NoError(t, err)
+	defer listener.Close()
+
+	notifier, err := New(config, newTestMetricsReporter())
+	require.NoError(t, err)
+	defer notifier.Close()
+
+	listenCtx, cancel := context.WithCancel(ctx)
+	notifications, err := listener.Listen(listenCtx, "module_events")
+	require.NoError(t, err)
+
+	require.NoError(t, notifier.Notify(ctx, "module_events", "module:a@1.0.0"))
+
+	select {
+	case n := <-notifications:
+		assert.Equal(t, "module_events", n.Channel)
+		assert.Equal(t, "module:a@1.0.0", n.Payload)
+		assert.NotZero(t, n.PID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification")
+	}
+
+	// Cancelling the context closes the channel
+	cancel()
+	select {
+	case _, ok := <-notifications:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification channel was not closed")
+	}
+}
@@ -0,0 +1,2 @@
+ALTER TABLE widgets ADD COLUMN color TEXT;
+CREATE INDEX widgets_name_idx ON widgets (name);
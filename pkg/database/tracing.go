@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// querySpanName is the operation name of spans started for database calls
+const querySpanName = "db.query"
+
+// instrumentationName identifies this package as the creator of its spans
+const instrumentationName = "github.com/StackCatalyst/common-lib/pkg/database"
+
+// stringLiteral matches single-quoted SQL string literals, including escaped quotes
+var stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// finishSpan completes a span started by startQuerySpan with the call's error
+type finishSpan func(err error)
+
+func noopFinishSpan(error) {}
+
+// startQuerySpan starts a db.query child span if ctx carries a span, so
+// database calls show up beneath the request that made them. It is a no-op
+// when tracing isn't in use.
+func startQuerySpan(ctx context.Context, poolLabel, operation, sql string) finishSpan {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.SpanContext().IsValid() {
+		return noopFinishSpan
+	}
+
+	_, span := parent.TracerProvider().Tracer(instrumentationName).Start(ctx, querySpanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.type", "postgresql"),
+			attribute.String("db.statement", sanitizeSQL(sql)),
+			attribute.String("db.operation", operation),
+			attribute.String("db.pool", poolLabel),
+		),
+	)
+
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// sanitizeSQL collapses whitespace and replaces string literals with ? so
+// that statement tags don't carry inlined values
+func sanitizeSQL(sql string) string {
+	sql = stringLiteral.ReplaceAllString(sql, "?")
+	return strings.Join(strings.Fields(sql), " ")
+}
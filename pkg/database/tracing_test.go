@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanAttribute returns the value of a finished span's attribute, or nil
+func spanAttribute(span tracetest.SpanStub, key string) interface{} {
+	for _, kv := range span.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value.AsInterface()
+		}
+	}
+	return nil
+}
+
+func TestQueryTracing(t *testing.T) {
+	primary := newFakePool()
+	client := &Client{pool: primary, metrics: NewMetricsReporter(newTestMetricsReporter())}
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "http.request")
+
+	t.Run("query", func(t *testing.T) {
+		exporter.Reset()
+		_, err := client.Query(ctx, "SELECT *\n\tFROM modules WHERE name = 'vpc' AND id = $1", 1)
+		require.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		span := spans[0]
+		assert.Equal(t, "db.query", span.Name)
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+		assert.Equal(t, "query", spanAttribute(span, "db.operation"))
+		assert.Equal(t, poolPrimary, spanAttribute(span, "db.pool"))
+		assert.Equal(t, "postgresql", spanAttribute(span, "db.type"))
+		assert.Equal(t, "SELECT * FROM modules WHERE name = ? AND id = $1", spanAttribute(span, "db.statement"))
+		assert.Equal(t, codes.Unset, span.Status.Code)
+	})
+
+	t.Run("query row", func(t *testing.T) {
+		exporter.Reset()
+		var n int
+		require.NoError(t, client.QueryRow(ctx, "SELECT 1").Scan(&n))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "query_row", spanAttribute(spans[0], "db.operation"))
+	})
+
+	t.Run("exec error", func(t *testing.T) {
+		exporter.Reset()
+		primary.errs = []error{assert.AnError}
+		_, err := client.Exec(ctx, "DELETE FROM modules")
+		require.Error(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "exec", spanAttribute(spans[0], "db.operation"))
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, assert.AnError.Error(), spans[0].Status.Description)
+	})
+
+	t.Run("prepared", func(t *testing.T) {
+		require.NoError(t, client.Prepare(ctx, "get_module", "SELECT * FROM modules WHERE id = $1"))
+		require.NoError(t, client.Prepare(ctx, "touch_module", "UPDATE modules SET updated_at = now() WHERE id = $1"))
+
+		exporter.Reset()
+		_, err := client.QueryPrepared(ctx, "get_module", 1)
+		require.NoError(t, err)
+		primary.errs = []error{assert.AnError}
+		_, err = client.ExecPrepared(ctx, "touch_module", 1)
+		require.Error(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "query", spanAttribute(spans[0], "db.operation"))
+		assert.Equal(t, "SELECT * FROM modules WHERE id = $1", spanAttribute(spans[0], "db.statement"))
+		assert.Equal(t, "exec", spanAttribute(spans[1], "db.operation"))
+		assert.Equal(t, poolPrimary, spanAttribute(spans[1], "db.pool"))
+		assert.Equal(t, codes.Error, spans[1].Status.Code)
+	})
+
+	t.Run("no span in context", func(t *testing.T) {
+		exporter.Reset()
+		_, err := client.Query(context.Background(), "SELECT 1")
+		require.NoError(t, err)
+		assert.Empty(t, exporter.GetSpans())
+	})
+}
+
+func TestSanitizeSQL(t *testing.T) {
+	assert.Equal(t,
+		"INSERT INTO notes (body) VALUES (?)",
+		sanitizeSQL("INSERT INTO notes (body)\n  VALUES ('it''s secret')"),
+	)
+	assert.Equal(t, "SELECT 1", sanitizeSQL("  SELECT   1  "))
+}
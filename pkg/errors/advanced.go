@@ -128,6 +128,59 @@ func GetErrorContext(err error) (context.Context, bool) {
 	return nil, false
 }
 
+// FieldsError attaches structured key/value fields to an error
+type FieldsError struct {
+	err    error
+	fields map[string]interface{}
+}
+
+// Error implements the error interface
+func (e *FieldsError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *FieldsError) Unwrap() error {
+	return e.err
+}
+
+// WithFields wraps an error with key/value fields, such as a module ID and
+// version, that can later be extracted with Fields
+func WithFields(err error, fields map[string]interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	copied := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		copied[k] = v
+	}
+	return &FieldsError{err: err, fields: copied}
+}
+
+// Fields returns all fields attached anywhere in the error chain. When the same
+// key is attached more than once, the outermost value wins. It returns nil if
+// the chain carries no fields.
+func Fields(err error) map[string]interface{} {
+	var chain []*FieldsError
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if fieldsErr, ok := e.(*FieldsError); ok {
+			chain = append(chain, fieldsErr)
+		}
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]interface{})
+	for i := len(chain) - 1; i >= 0; i-- {
+		for k, v := range chain[i].fields {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
 // StackFrame represents a single stack frame
 type StackFrame struct {
 	File     string
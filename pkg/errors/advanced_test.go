@@ -2,9 +2,11 @@ package errors
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -89,3 +91,27 @@ func TestErrorStack(t *testing.T) {
 	assert.Contains(t, str, "advanced_test.go")
 	assert.Contains(t, str, "TestErrorStack")
 }
+
+func TestWithFields(t *testing.T) {
+	assert.Nil(t, WithFields(nil, map[string]interface{}{"module_id": "vpc"}))
+
+	base := New(ErrNotFound, "module not found")
+	err := WithFields(base, map[string]interface{}{"module_id": "vpc", "version": "1.0.0"})
+	assert.Equal(t, base.Error(), err.Error())
+	assert.True(t, Is(err, ErrNotFound))
+	assert.True(t, errors.Is(err, base))
+
+	// Fields survive further wrapping, and outer fields take precedence
+	wrapped := WithFields(
+		fmt.Errorf("load failed: %w", Wrap(err, ErrInternal, "storage error")),
+		map[string]interface{}{"version": "2.0.0", "backend": "postgres"},
+	)
+	assert.Equal(t, map[string]interface{}{
+		"module_id": "vpc",
+		"version":   "2.0.0",
+		"backend":   "postgres",
+	}, Fields(wrapped))
+
+	assert.Nil(t, Fields(base))
+	assert.Nil(t, Fields(nil))
+}
@@ -13,6 +13,7 @@ type ErrorCode string
 const (
 	ErrNotFound     ErrorCode = "NOT_FOUND"
 	ErrUnauthorized ErrorCode = "UNAUTHORIZED"
+	ErrForbidden    ErrorCode = "FORBIDDEN"
 	ErrValidation   ErrorCode = "VALIDATION"
 	ErrInternal     ErrorCode = "INTERNAL"
 )
@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+// httpStatuses maps error codes to their conventional HTTP status
+var httpStatuses = map[ErrorCode]int{
+	ErrNotFound:     http.StatusNotFound,
+	ErrUnauthorized: http.StatusUnauthorized,
+	ErrForbidden:    http.StatusForbidden,
+	ErrValidation:   http.StatusBadRequest,
+	ErrInternal:     http.StatusInternalServerError,
+}
+
+// grpcCodes maps error codes to their conventional gRPC status code
+var grpcCodes = map[ErrorCode]codes.Code{
+	ErrNotFound:     codes.NotFound,
+	ErrUnauthorized: codes.Unauthenticated,
+	ErrForbidden:    codes.PermissionDenied,
+	ErrValidation:   codes.InvalidArgument,
+	ErrInternal:     codes.Internal,
+}
+
+// GetCode returns the code of the outermost AppError in the error chain
+func GetCode(err error) (ErrorCode, bool) {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code, true
+	}
+	return "", false
+}
+
+// HTTPStatus returns the HTTP status code for an error. A nil error maps to
+// 200, and errors without a known code map to 500.
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if c, ok := GetCode(err); ok {
+		if status, ok := httpStatuses[c]; ok {
+			return status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns the gRPC status code for an error. A nil error maps to
+// OK, and errors without a known code map to Internal.
+func GRPCCode(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if c, ok := GetCode(err); ok {
+		if grpcCode, ok := grpcCodes[c]; ok {
+			return grpcCode
+		}
+	}
+	return codes.Internal
+}
@@ -0,0 +1,67 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, http.StatusOK},
+		{"not found", New(ErrNotFound, "missing"), http.StatusNotFound},
+		{"unauthorized", New(ErrUnauthorized, "no token"), http.StatusUnauthorized},
+		{"forbidden", New(ErrForbidden, "no access"), http.StatusForbidden},
+		{"validation", New(ErrValidation, "bad input"), http.StatusBadRequest},
+		{"internal", New(ErrInternal, "boom"), http.StatusInternalServerError},
+		{"unknown code", New(ErrorCode("TEAPOT"), "short and stout"), http.StatusInternalServerError},
+		{"plain error", fmt.Errorf("plain"), http.StatusInternalServerError},
+		{"wrapped with fmt", fmt.Errorf("handler: %w", New(ErrNotFound, "missing")), http.StatusNotFound},
+		{"outermost code wins", Wrap(New(ErrNotFound, "missing"), ErrValidation, "bad request"), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HTTPStatus(tt.err))
+		})
+	}
+}
+
+func TestGRPCCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"nil", nil, codes.OK},
+		{"not found", New(ErrNotFound, "missing"), codes.NotFound},
+		{"unauthorized", New(ErrUnauthorized, "no token"), codes.Unauthenticated},
+		{"forbidden", New(ErrForbidden, "no access"), codes.PermissionDenied},
+		{"validation", New(ErrValidation, "bad input"), codes.InvalidArgument},
+		{"internal", New(ErrInternal, "boom"), codes.Internal},
+		{"plain error", fmt.Errorf("plain"), codes.Internal},
+		{"wrapped with fmt", fmt.Errorf("handler: %w", New(ErrUnauthorized, "no token")), codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GRPCCode(tt.err))
+		})
+	}
+}
+
+func TestGetCode(t *testing.T) {
+	code, ok := GetCode(fmt.Errorf("handler: %w", New(ErrForbidden, "no access")))
+	assert.True(t, ok)
+	assert.Equal(t, ErrForbidden, code)
+
+	_, ok = GetCode(fmt.Errorf("plain"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,134 @@
+package grpc
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CircuitBreakerConfig holds the client circuit breaker configuration
+type CircuitBreakerConfig struct {
+	// Enabled indicates if the circuit breaker is used
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// FailureThreshold is the number of consecutive failures that opens the breaker
+	FailureThreshold int `json:"failure_threshold" yaml:"failure_threshold"`
+	// Cooldown is how long the breaker stays open before letting a probe request through
+	Cooldown time.Duration `json:"cooldown" yaml:"cooldown"`
+}
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+// Circuit breaker states, in the order they are reported by the state metric
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+// String returns the name of the breaker state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// errBreakerOpen is returned without calling the server while the breaker is open
+var errBreakerOpen = status.Error(codes.Unavailable, "circuit breaker is open")
+
+// circuitBreaker fails calls fast after repeated downstream failures. After
+// the cooldown a single probe call is let through: success closes the
+// breaker, failure opens it for another cooldown.
+type circuitBreaker struct {
+	config   CircuitBreakerConfig
+	onChange func(BreakerState)
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+// newCircuitBreaker creates a closed circuit breaker; onChange is called on every state transition
+func newCircuitBreaker(config CircuitBreakerConfig, onChange func(BreakerState)) *circuitBreaker {
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = 1
+	}
+	return &circuitBreaker{config: config, onChange: onChange}
+}
+
+// State returns the current breaker state
+func (b *circuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow reports whether a call may proceed
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.config.Cooldown {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		return true
+	case BreakerHalfOpen:
+		// A probe is already in flight
+		return false
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of a call
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isBreakerFailure(err) {
+		b.failures = 0
+		b.setState(BreakerClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.openedAt = time.Now()
+		b.setState(BreakerOpen)
+	}
+}
+
+// setState transitions the breaker; the caller must hold b.mu
+func (b *circuitBreaker) setState(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(state)
+	}
+}
+
+// isBreakerFailure reports whether err indicates an unhealthy downstream
+// rather than an application-level error
+func isBreakerFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
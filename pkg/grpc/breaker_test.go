@@ -0,0 +1,109 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reporter := metrics.New(metrics.Options{
+		Namespace: "test",
+		Subsystem: "grpc_client",
+		Registry:  registry,
+	})
+
+	client := &Client{
+		config: Config{
+			Target: "downstream:50051",
+			CircuitBreaker: CircuitBreakerConfig{
+				Enabled:          true,
+				FailureThreshold: 3,
+				Cooldown:         50 * time.Millisecond,
+			},
+		},
+		metrics: NewMetricsReporter(reporter),
+	}
+	client.breaker = client.newCircuitBreaker()
+	interceptor := client.unaryInterceptor()
+
+	stateMetric := func() float64 {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		for _, family := range families {
+			if family.GetName() == "test_grpc_client_grpc_client_circuit_breaker_state" {
+				return family.GetMetric()[0].GetGauge().GetValue()
+			}
+		}
+		t.Fatal("circuit breaker state metric not found")
+		return 0
+	}
+
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	invoker := &flakyInvoker{errs: []error{unavailable, unavailable, unavailable, unavailable}}
+	call := func() error {
+		return interceptor(context.Background(), "/test.Service/Method", nil, nil, nil, invoker.invoke)
+	}
+
+	// Application errors don't count as failures
+	appErr := &flakyInvoker{errs: []error{status.Error(codes.NotFound, "not found")}}
+	err := interceptor(context.Background(), "/test.Service/Method", nil, nil, nil, appErr.invoke)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, BreakerClosed, client.breaker.State())
+
+	// Consecutive failures open the breaker
+	for i := 0; i < 3; i++ {
+		assert.Error(t, call())
+	}
+	assert.Equal(t, BreakerOpen, client.breaker.State())
+	assert.Equal(t, float64(BreakerOpen), stateMetric())
+
+	// Calls fail fast while open
+	err = call()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 3, invoker.calls)
+
+	// A failed probe after the cooldown re-opens the breaker
+	time.Sleep(60 * time.Millisecond)
+	assert.Error(t, call())
+	assert.Equal(t, 4, invoker.calls)
+	assert.Equal(t, BreakerOpen, client.breaker.State())
+
+	// A successful probe closes it
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, call())
+	assert.Equal(t, 5, invoker.calls)
+	assert.Equal(t, BreakerClosed, client.breaker.State())
+	assert.Equal(t, float64(BreakerClosed), stateMetric())
+
+	require.NoError(t, call())
+	assert.Equal(t, 6, invoker.calls)
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	breaker := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Millisecond}, nil)
+
+	breaker.record(status.Error(codes.Unavailable, "unavailable"))
+	assert.False(t, breaker.allow())
+
+	time.Sleep(5 * time.Millisecond)
+	assert.True(t, breaker.allow())
+	assert.Equal(t, BreakerHalfOpen, breaker.State())
+
+	// Only one probe is let through while half-open
+	assert.False(t, breaker.allow())
+}
+
+func TestBreakerStateString(t *testing.T) {
+	assert.Equal(t, "closed", BreakerClosed.String())
+	assert.Equal(t, "half-open", BreakerHalfOpen.String())
+	assert.Equal(t, "open", BreakerOpen.String())
+}
@@ -2,13 +2,21 @@ package grpc
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"math/rand"
+	"os"
 	"time"
 
+	"github.com/StackCatalyst/common-lib/pkg/logging"
 	"github.com/StackCatalyst/common-lib/pkg/metrics"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 )
 
 // Config holds the gRPC client configuration
@@ -27,6 +35,27 @@ type Config struct {
 	RetryWaitMin time.Duration `json:"retry_wait_min" yaml:"retry_wait_min"`
 	// RetryWaitMax is the maximum time to wait between retries
 	RetryWaitMax time.Duration `json:"retry_wait_max" yaml:"retry_wait_max"`
+	// TLS is the transport security configuration; connections are insecure unless enabled
+	TLS TLSConfig `json:"tls" yaml:"tls"`
+	// CircuitBreaker is the circuit breaker configuration; disabled by default
+	CircuitBreaker CircuitBreakerConfig `json:"circuit_breaker" yaml:"circuit_breaker"`
+}
+
+// TLSConfig holds the TLS transport configuration
+type TLSConfig struct {
+	// Enabled indicates if TLS is used for the connection
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// CAFile is the path to a PEM-encoded CA bundle used to verify the server;
+	// the system roots are used when empty
+	CAFile string `json:"ca_file" yaml:"ca_file"`
+	// CertFile is the path to a PEM-encoded client certificate for mutual TLS
+	CertFile string `json:"cert_file" yaml:"cert_file"`
+	// KeyFile is the path to the PEM-encoded private key of the client certificate
+	KeyFile string `json:"key_file" yaml:"key_file"`
+	// ServerName overrides the server name used to verify the server certificate
+	ServerName string `json:"server_name" yaml:"server_name"`
+	// InsecureSkipVerify disables server certificate verification
+	InsecureSkipVerify bool `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
 }
 
 // KeepAliveConfig holds the keepalive configuration
@@ -58,6 +87,7 @@ type Client struct {
 	conn    *grpc.ClientConn
 	config  Config
 	metrics *MetricsReporter
+	breaker *circuitBreaker
 }
 
 // New creates a new gRPC client
@@ -66,8 +96,13 @@ func New(config Config, metricsReporter *metrics.Reporter) (*Client, error) {
 		return nil, fmt.Errorf("target address must be provided")
 	}
 
+	creds, err := transportCredentials(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
 	dialOpts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
 		grpc.WithKeepaliveParams(keepalive.ClientParameters{
 			Time:                config.KeepAlive.Time,
 			Timeout:             config.KeepAlive.Timeout,
@@ -83,11 +118,62 @@ func New(config Config, metricsReporter *metrics.Reporter) (*Client, error) {
 		return nil, fmt.Errorf("failed to dial server: %w", err)
 	}
 
-	return &Client{
+	c := &Client{
 		conn:    conn,
 		config:  config,
 		metrics: NewMetricsReporter(metricsReporter),
-	}, nil
+	}
+	if config.CircuitBreaker.Enabled {
+		c.breaker = c.newCircuitBreaker()
+	}
+
+	return c, nil
+}
+
+// newCircuitBreaker creates a circuit breaker that reports its state as a metric
+func (c *Client) newCircuitBreaker() *circuitBreaker {
+	c.metrics.SetBreakerState(c.config.Target, BreakerClosed)
+	return newCircuitBreaker(c.config.CircuitBreaker, func(state BreakerState) {
+		c.metrics.SetBreakerState(c.config.Target, state)
+	})
+}
+
+// transportCredentials builds the transport credentials for the TLS configuration
+func transportCredentials(config TLSConfig) (credentials.TransportCredentials, error) {
+	if !config.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	if (config.CertFile == "") != (config.KeyFile == "") {
+		return nil, fmt.Errorf("TLS cert file and key file must be provided together")
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CAFile != "" {
+		ca, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse CA file %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
 }
 
 // Close closes the gRPC client connection
@@ -118,19 +204,85 @@ func (c *Client) WithStreamInterceptor() grpc.DialOption {
 	return grpc.WithStreamInterceptor(c.streamInterceptor())
 }
 
-// unaryInterceptor returns a gRPC unary interceptor that adds metrics and error handling
+// unaryInterceptor returns a gRPC unary interceptor that adds metrics, error
+// handling, retries of transient failures and, if enabled, circuit breaking.
+// The caller's request ID is forwarded in the x-request-id metadata.
 func (c *Client) unaryInterceptor() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		start := time.Now()
-		err := invoker(ctx, method, req, reply, cc, opts...)
-		c.metrics.ObserveRequest(method, err, time.Since(start))
-		return err
+		ctx = logging.WithOutgoingRequestID(ctx)
+		var err error
+		for attempt := 0; ; attempt++ {
+			if c.breaker != nil && !c.breaker.allow() {
+				c.metrics.ObserveRequest(method, errBreakerOpen, 0)
+				return errBreakerOpen
+			}
+
+			start := time.Now()
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			c.metrics.ObserveRequest(method, err, time.Since(start))
+			if c.breaker != nil {
+				c.breaker.record(err)
+			}
+
+			if err == nil || attempt >= c.config.MaxRetries || !c.shouldRetry(ctx, err) {
+				return err
+			}
+
+			backoff := c.getRetryBackoff(attempt)
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= backoff {
+				return err
+			}
+
+			c.metrics.ObserveRetry(method)
+
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(backoff):
+			}
+		}
+	}
+}
+
+// shouldRetry returns true if the error has a retryable status code and the
+// call's context has not been cancelled or expired
+func (c *Client) shouldRetry(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// getRetryBackoff returns the backoff duration for a retry attempt: exponential
+// growth from RetryWaitMin, capped at RetryWaitMax, with up to 50% jitter
+func (c *Client) getRetryBackoff(attempt int) time.Duration {
+	backoff := c.config.RetryWaitMin << attempt
+	if backoff <= 0 || backoff > c.config.RetryWaitMax {
+		backoff = c.config.RetryWaitMax
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	// Spread retries over [backoff/2, backoff] without going below RetryWaitMin
+	backoff -= time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	if backoff < c.config.RetryWaitMin {
+		backoff = c.config.RetryWaitMin
 	}
+	return backoff
 }
 
-// streamInterceptor returns a gRPC stream interceptor that adds metrics and error handling
+// streamInterceptor returns a gRPC stream interceptor that adds metrics and error
+// handling, and forwards the caller's request ID in the x-request-id metadata
 func (c *Client) streamInterceptor() grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx = logging.WithOutgoingRequestID(ctx)
 		start := time.Now()
 		stream, err := streamer(ctx, desc, cc, method, opts...)
 		c.metrics.ObserveRequest(method, err, time.Since(start))
@@ -2,17 +2,33 @@ package grpc
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/StackCatalyst/common-lib/pkg/logging"
 	"github.com/StackCatalyst/common-lib/pkg/metrics"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 )
 
@@ -148,3 +164,223 @@ func TestClientInterceptors(t *testing.T) {
 	streamOpt := client.WithStreamInterceptor()
 	assert.NotNil(t, streamOpt)
 }
+
+// flakyInvoker fails with the given errors, in order, before succeeding
+type flakyInvoker struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakyInvoker) invoke(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func TestUnaryInterceptorRetry(t *testing.T) {
+	newClient := func(maxRetries int) *Client {
+		return &Client{
+			config: Config{
+				MaxRetries:   maxRetries,
+				RetryWaitMin: time.Millisecond,
+				RetryWaitMax: 5 * time.Millisecond,
+			},
+			metrics: NewMetricsReporter(newTestMetricsReporter()),
+		}
+	}
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	t.Run("retries unavailable until success", func(t *testing.T) {
+		invoker := &flakyInvoker{errs: []error{unavailable, unavailable}}
+		err := newClient(3).unaryInterceptor()(context.Background(), "/test.Service/Method", nil, nil, nil, invoker.invoke)
+		require.NoError(t, err)
+		assert.Equal(t, 3, invoker.calls)
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		invoker := &flakyInvoker{errs: []error{unavailable, unavailable, unavailable}}
+		err := newClient(1).unaryInterceptor()(context.Background(), "/test.Service/Method", nil, nil, nil, invoker.invoke)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 2, invoker.calls)
+	})
+
+	t.Run("does not retry non-retryable codes", func(t *testing.T) {
+		invoker := &flakyInvoker{errs: []error{status.Error(codes.InvalidArgument, "bad request")}}
+		err := newClient(3).unaryInterceptor()(context.Background(), "/test.Service/Method", nil, nil, nil, invoker.invoke)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, 1, invoker.calls)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		invoker := &flakyInvoker{errs: []error{unavailable, unavailable}}
+		err := newClient(3).unaryInterceptor()(ctx, "/test.Service/Method", nil, nil, nil, invoker.invoke)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 1, invoker.calls)
+	})
+
+	t.Run("does not retry past the context deadline", func(t *testing.T) {
+		client := newClient(3)
+		client.config.RetryWaitMin = time.Second
+		client.config.RetryWaitMax = time.Second
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		invoker := &flakyInvoker{errs: []error{status.Error(codes.DeadlineExceeded, "deadline exceeded")}}
+		err := client.unaryInterceptor()(ctx, "/test.Service/Method", nil, nil, nil, invoker.invoke)
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.Equal(t, 1, invoker.calls)
+	})
+}
+
+func TestGetRetryBackoff(t *testing.T) {
+	client := &Client{config: Config{RetryWaitMin: 10 * time.Millisecond, RetryWaitMax: 50 * time.Millisecond}}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		backoff := client.getRetryBackoff(attempt)
+		assert.GreaterOrEqual(t, backoff, 10*time.Millisecond)
+		assert.LessOrEqual(t, backoff, 50*time.Millisecond)
+	}
+}
+
+// writeSelfSignedCert writes a self-signed certificate for localhost and its key to dir
+func writeSelfSignedCert(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestTLSClient(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t, t.TempDir())
+
+	serverCreds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.Creds(serverCreds))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	config := DefaultConfig()
+	config.Target = lis.Addr().String()
+	config.TLS = TLSConfig{
+		Enabled:    true,
+		CAFile:     certFile,
+		ServerName: "localhost",
+	}
+
+	client, err := New(config, newTestMetricsReporter())
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(client.Connection()).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+func TestTransportCredentials(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t, t.TempDir())
+
+	t.Run("insecure by default", func(t *testing.T) {
+		creds, err := transportCredentials(TLSConfig{})
+		require.NoError(t, err)
+		assert.Equal(t, "insecure", creds.Info().SecurityProtocol)
+	})
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		creds, err := transportCredentials(TLSConfig{
+			Enabled:  true,
+			CAFile:   certFile,
+			CertFile: certFile,
+			KeyFile:  keyFile,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "tls", creds.Info().SecurityProtocol)
+	})
+
+	t.Run("cert without key", func(t *testing.T) {
+		_, err := transportCredentials(TLSConfig{Enabled: true, CertFile: certFile})
+		assert.Error(t, err)
+	})
+
+	t.Run("missing CA file", func(t *testing.T) {
+		_, err := transportCredentials(TLSConfig{Enabled: true, CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid CA file", func(t *testing.T) {
+		_, err := transportCredentials(TLSConfig{Enabled: true, CAFile: keyFile})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewClientInvalidTLS(t *testing.T) {
+	config := DefaultConfig()
+	config.Target = "localhost:50051"
+	config.TLS = TLSConfig{Enabled: true, KeyFile: "key.pem"}
+
+	client, err := New(config, newTestMetricsReporter())
+	assert.Error(t, err)
+	assert.Nil(t, client)
+}
+
+func TestClientInterceptorsForwardRequestID(t *testing.T) {
+	client := &Client{metrics: NewMetricsReporter(newTestMetricsReporter())}
+	ctx := context.WithValue(context.Background(), logging.RequestIDKey, "request-1")
+
+	var unaryID []string
+	err := client.unaryInterceptor()(ctx, "/test.Service/Method", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			unaryID = md.Get(logging.RequestIDMetadataKey)
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"request-1"}, unaryID)
+
+	var streamID []string
+	_, err = client.streamInterceptor()(ctx, &grpc.StreamDesc{}, nil, "/test.Service/Stream",
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			streamID = md.Get(logging.RequestIDMetadataKey)
+			return nil, nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"request-1"}, streamID)
+}
@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	liberrors "github.com/StackCatalyst/common-lib/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorMappingInterceptor returns a gRPC unary server interceptor converting
+// pkg/errors errors returned by handlers into a status with the matching code
+// (see errors.GRPCCode) and an ErrorInfo detail whose reason is the error code.
+// Errors that are already statuses or carry no code are returned unchanged.
+func ErrorMappingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, mapError(err)
+	}
+}
+
+// mapError converts an error carrying a pkg/errors code into a gRPC status error
+func mapError(err error) error {
+	var appErr *liberrors.AppError
+	if err == nil || !errors.As(err, &appErr) {
+		return err
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	st := status.New(liberrors.GRPCCode(err), appErr.Message)
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{Reason: string(appErr.Code)})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
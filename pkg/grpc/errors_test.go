@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	liberrors "github.com/StackCatalyst/common-lib/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorMappingInterceptor(t *testing.T) {
+	interceptor := ErrorMappingInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	call := func(err error) error {
+		_, mapped := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, err
+		})
+		return mapped
+	}
+
+	t.Run("app error", func(t *testing.T) {
+		err := call(liberrors.New(liberrors.ErrValidation, "numbers too large"))
+
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		assert.Equal(t, "numbers too large", st.Message())
+
+		require.Len(t, st.Details(), 1)
+		errorInfo, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, "VALIDATION", errorInfo.Reason)
+	})
+
+	t.Run("wrapped app error", func(t *testing.T) {
+		err := call(fmt.Errorf("lookup: %w", liberrors.New(liberrors.ErrNotFound, "module not found")))
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("status error unchanged", func(t *testing.T) {
+		original := status.Error(codes.DeadlineExceeded, "request timed out")
+		assert.Equal(t, original, call(original))
+	})
+
+	t.Run("plain error unchanged", func(t *testing.T) {
+		original := fmt.Errorf("boom")
+		assert.Equal(t, original, call(original))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		resp, err := interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "resp", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "resp", resp)
+	})
+}
@@ -5,12 +5,15 @@ import (
 
 	"github.com/StackCatalyst/common-lib/pkg/metrics"
 	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/status"
 )
 
 // MetricsReporter handles gRPC client metrics reporting
 type MetricsReporter struct {
 	requestDuration *prometheus.HistogramVec
 	requestErrors   *prometheus.CounterVec
+	retries         *prometheus.CounterVec
+	breakerState    *prometheus.GaugeVec
 }
 
 // NewMetricsReporter creates a new gRPC client metrics reporter
@@ -27,6 +30,16 @@ func NewMetricsReporter(reporter *metrics.Reporter) *MetricsReporter {
 			"Total number of gRPC client errors",
 			[]string{"method"},
 		),
+		retries: reporter.Counter(
+			"grpc_client_retries_total",
+			"Total number of gRPC client request retries",
+			[]string{"method"},
+		),
+		breakerState: reporter.Gauge(
+			"grpc_client_circuit_breaker_state",
+			"gRPC client circuit breaker state (0=closed, 1=half-open, 2=open)",
+			[]string{"target"},
+		),
 	}
 }
 
@@ -39,3 +52,54 @@ func (m *MetricsReporter) ObserveRequest(method string, err error, duration time
 	}
 	m.requestDuration.WithLabelValues(method, status).Observe(duration.Seconds())
 }
+
+// ObserveRetry records a retried request
+func (m *MetricsReporter) ObserveRetry(method string) {
+	m.retries.WithLabelValues(method).Inc()
+}
+
+// SetBreakerState records the circuit breaker state for a target
+func (m *MetricsReporter) SetBreakerState(target string, state BreakerState) {
+	m.breakerState.WithLabelValues(target).Set(float64(state))
+}
+
+// ServerMetricsReporter handles gRPC server metrics reporting
+type ServerMetricsReporter struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	panics          *prometheus.CounterVec
+}
+
+// NewServerMetricsReporter creates a new gRPC server metrics reporter
+func NewServerMetricsReporter(reporter *metrics.Reporter) *ServerMetricsReporter {
+	return &ServerMetricsReporter{
+		requests: reporter.Counter(
+			"grpc_server_requests_total",
+			"Total number of gRPC server requests",
+			[]string{"method", "code"},
+		),
+		requestDuration: reporter.Histogram(
+			"grpc_server_request_duration_seconds",
+			"gRPC server request duration in seconds",
+			[]string{"method", "code"},
+			[]float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10},
+		),
+		panics: reporter.Counter(
+			"grpc_server_panics_total",
+			"Total number of panics recovered in gRPC handlers",
+			[]string{"method"},
+		),
+	}
+}
+
+// ObserveRequest records a handled request
+func (m *ServerMetricsReporter) ObserveRequest(method string, err error, duration time.Duration) {
+	code := status.Code(err).String()
+	m.requests.WithLabelValues(method, code).Inc()
+	m.requestDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+}
+
+// ObservePanic records a recovered handler panic
+func (m *ServerMetricsReporter) ObservePanic(method string) {
+	m.panics.WithLabelValues(method).Inc()
+}
@@ -28,4 +28,23 @@ func TestGRPCMetricsReporter(t *testing.T) {
 		// Test failed request
 		metricsReporter.ObserveRequest("/test.service/Method2", errors.New("request failed"), 50*time.Millisecond)
 	})
+
+	t.Run("ObserveRetry", func(t *testing.T) {
+		metricsReporter.ObserveRetry("/test.service/Method1")
+	})
+}
+
+func TestGRPCServerMetricsReporter(t *testing.T) {
+	reporter := metrics.New(metrics.Options{
+		Namespace: "test",
+		Subsystem: "grpc_server",
+		Registry:  prometheus.NewRegistry(),
+	})
+
+	metricsReporter := NewServerMetricsReporter(reporter)
+	require.NotNil(t, metricsReporter)
+
+	metricsReporter.ObserveRequest("/test.service/Method1", nil, 100*time.Millisecond)
+	metricsReporter.ObserveRequest("/test.service/Method2", errors.New("request failed"), 50*time.Millisecond)
+	metricsReporter.ObservePanic("/test.service/Method2")
 }
@@ -0,0 +1,136 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ServerConfig holds the gRPC server configuration
+type ServerConfig struct {
+	// Address is the address to listen on
+	Address string `json:"address" yaml:"address"`
+	// ShutdownTimeout bounds graceful shutdown when the context has no deadline
+	ShutdownTimeout time.Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+}
+
+// DefaultServerConfig returns the default gRPC server configuration
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Address:         ":50051",
+		ShutdownTimeout: 30 * time.Second,
+	}
+}
+
+// Server is a gRPC server with metrics and panic recovery
+type Server struct {
+	server  *grpc.Server
+	config  ServerConfig
+	metrics *ServerMetricsReporter
+}
+
+// NewServer creates a new gRPC server. Metrics and panic recovery interceptors
+// run before any interceptors passed in opts.
+func NewServer(config ServerConfig, metricsReporter *metrics.Reporter, opts ...grpc.ServerOption) *Server {
+	s := &Server{
+		config:  config,
+		metrics: NewServerMetricsReporter(metricsReporter),
+	}
+
+	serverOpts := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor()),
+		grpc.ChainStreamInterceptor(s.streamInterceptor()),
+	}, opts...)
+	s.server = grpc.NewServer(serverOpts...)
+
+	return s
+}
+
+// RegisterService registers a service implementation with the server
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
+	s.server.RegisterService(desc, impl)
+}
+
+// Server returns the underlying gRPC server
+func (s *Server) Server() *grpc.Server {
+	return s.server
+}
+
+// ListenAndServe listens on the configured address and serves requests
+func (s *Server) ListenAndServe() error {
+	lis, err := net.Listen("tcp", s.config.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.Address, err)
+	}
+	return s.Serve(lis)
+}
+
+// Serve accepts connections on the listener until the server is stopped
+func (s *Server) Serve(lis net.Listener) error {
+	return s.server.Serve(lis)
+}
+
+// GracefulStopWithTimeout stops accepting new requests and waits for in-flight
+// requests to finish. If ctx is done first, remaining requests are cancelled
+// and ctx's error is returned.
+func (s *Server) GracefulStopWithTimeout(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok && s.config.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.ShutdownTimeout)
+		defer cancel()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+// unaryInterceptor returns a gRPC unary interceptor that adds metrics and panic recovery
+func (s *Server) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		defer func() {
+			if recover() != nil {
+				err = s.recovered(info.FullMethod)
+			}
+			s.metrics.ObserveRequest(info.FullMethod, err, time.Since(start))
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// streamInterceptor returns a gRPC stream interceptor that adds metrics and panic recovery
+func (s *Server) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		defer func() {
+			if recover() != nil {
+				err = s.recovered(info.FullMethod)
+			}
+			s.metrics.ObserveRequest(info.FullMethod, err, time.Since(start))
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recovered records a handler panic and converts it to an Internal error
+func (s *Server) recovered(method string) error {
+	s.metrics.ObservePanic(method)
+	return status.Errorf(codes.Internal, "internal error in %s", method)
+}
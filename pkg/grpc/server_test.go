@@ -0,0 +1,124 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testHealthServer answers health checks, panicking for the "panic" service
+type testHealthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (s *testHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.Service == "panic" {
+		panic("handler failure")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// startTestServer serves s over an in-memory listener and returns a connected client
+func startTestServer(t *testing.T, s *Server) healthpb.HealthClient {
+	lis := bufconn.Listen(bufSize)
+	go s.Serve(lis)
+	t.Cleanup(s.Server().Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+// counterValue returns the value of a counter with the given labels, or 0 if absent
+func counterValue(t *testing.T, registry *prometheus.Registry, name string, labels map[string]string) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestServer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reporter := metrics.New(metrics.Options{
+		Namespace: "test",
+		Subsystem: "grpc_server",
+		Registry:  registry,
+	})
+
+	s := NewServer(DefaultServerConfig(), reporter)
+	healthpb.RegisterHealthServer(s, &testHealthServer{})
+	client := startTestServer(t, s)
+
+	const method = "/grpc.health.v1.Health/Check"
+	requests := "test_grpc_server_grpc_server_requests_total"
+
+	t.Run("records handled requests", func(t *testing.T) {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+		assert.Equal(t, 1.0, counterValue(t, registry, requests, map[string]string{"method": method, "code": "OK"}))
+	})
+
+	t.Run("recovers from panics", func(t *testing.T) {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "panic"})
+		require.Error(t, err)
+		assert.Equal(t, codes.Internal, status.Code(err))
+
+		assert.Equal(t, 1.0, counterValue(t, registry, requests, map[string]string{"method": method, "code": "Internal"}))
+		assert.Equal(t, 1.0, counterValue(t, registry, "test_grpc_server_grpc_server_panics_total", map[string]string{"method": method}))
+
+		// The server keeps serving after a panic
+		_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+	})
+}
+
+func TestServerGracefulStopWithTimeout(t *testing.T) {
+	s := NewServer(DefaultServerConfig(), newTestMetricsReporter())
+	healthpb.RegisterHealthServer(s, &testHealthServer{})
+	client := startTestServer(t, s)
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, s.GracefulStopWithTimeout(ctx))
+
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/metrics"
+)
+
+// Check statuses reported in the JSON body
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Default probe paths served by Registry.Handler
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// Config holds the health registry configuration
+type Config struct {
+	// Timeout bounds how long a single check may run
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// Service is the service label reported to ServiceHealth
+	Service string `json:"service" yaml:"service"`
+	// Instance is the instance label reported to ServiceHealth
+	Instance string `json:"instance" yaml:"instance"`
+}
+
+// DefaultConfig returns the default health registry configuration
+func DefaultConfig() *Config {
+	return &Config{
+		Timeout: 5 * time.Second,
+	}
+}
+
+// CheckFunc reports whether a component is healthy
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of a single check
+type CheckResult struct {
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is the outcome of running a set of checks
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Healthy reports whether every check passed
+func (r Report) Healthy() bool {
+	return r.Status == StatusUp
+}
+
+// Registry holds liveness and readiness checks
+type Registry struct {
+	config    *Config
+	metrics   *metrics.ServiceHealth
+	mu        sync.RWMutex
+	liveness  map[string]CheckFunc
+	readiness map[string]CheckFunc
+}
+
+// NewRegistry creates a new health registry. A nil config uses DefaultConfig
+// and a zero Timeout takes its DefaultConfig value. If serviceHealth is
+// non-nil, readiness results are recorded as service health and every check
+// result as dependency health, so they can also be scraped.
+func NewRegistry(config *Config, serviceHealth *metrics.ServiceHealth) *Registry {
+	if config == nil {
+		config = DefaultConfig()
+	} else if config.Timeout <= 0 {
+		cfg := *config
+		cfg.Timeout = DefaultConfig().Timeout
+		config = &cfg
+	}
+	return &Registry{
+		config:    config,
+		metrics:   serviceHealth,
+		liveness:  make(map[string]CheckFunc),
+		readiness: make(map[string]CheckFunc),
+	}
+}
+
+// AddLiveness adds a check that must pass for the process to be considered alive,
+// replacing any liveness check with the same name
+func (r *Registry) AddLiveness(name string, check func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveness[name] = check
+}
+
+// AddReadiness adds a check that must pass for the service to receive traffic,
+// replacing any readiness check with the same name
+func (r *Registry) AddReadiness(name string, check func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readiness[name] = check
+}
+
+// CheckLiveness runs all liveness checks
+func (r *Registry) CheckLiveness(ctx context.Context) Report {
+	return r.run(ctx, r.snapshot(r.liveness))
+}
+
+// CheckReadiness runs all readiness checks and records the result in ServiceHealth
+func (r *Registry) CheckReadiness(ctx context.Context) Report {
+	report := r.run(ctx, r.snapshot(r.readiness))
+	if r.metrics != nil {
+		r.metrics.SetHealth(r.config.Service, r.config.Instance, report.Healthy())
+	}
+	return report
+}
+
+// Handler returns an http.Handler serving liveness on /healthz and readiness on /readyz
+func (r *Registry) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(LivenessPath, r.LivenessHandler())
+	mux.Handle(ReadinessPath, r.ReadinessHandler())
+	return mux
+}
+
+// LivenessHandler returns an http.Handler running the liveness checks
+func (r *Registry) LivenessHandler() http.Handler {
+	return reportHandler(r.CheckLiveness)
+}
+
+// ReadinessHandler returns an http.Handler running the readiness checks
+func (r *Registry) ReadinessHandler() http.Handler {
+	return reportHandler(r.CheckReadiness)
+}
+
+// reportHandler responds 200 when every check passes and 503 otherwise, with
+// the report as the JSON body
+func reportHandler(check func(context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := check(req.Context())
+
+		status := http.StatusOK
+		if !report.Healthy() {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// snapshot copies a check map so checks can run without holding the lock
+func (r *Registry) snapshot(checks map[string]CheckFunc) map[string]CheckFunc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	copied := make(map[string]CheckFunc, len(checks))
+	for name, check := range checks {
+		copied[name] = check
+	}
+	return copied
+}
+
+// run executes checks concurrently, each bounded by the configured timeout
+func (r *Registry) run(ctx context.Context, checks map[string]CheckFunc) Report {
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+			result := r.runCheck(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	if r.metrics != nil {
+		for name, result := range report.Checks {
+			r.metrics.SetDependencyHealth(name, result.Status == StatusUp)
+		}
+	}
+
+	return report
+}
+
+// runCheck runs a single check, abandoning it if it outlives the timeout. A
+// check that panics is reported as down.
+func (r *Registry) runCheck(ctx context.Context, check CheckFunc) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: StatusUp, Duration: time.Since(start)}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ok(ctx context.Context) error { return nil }
+
+func probe(t *testing.T, handler http.Handler, path string) (int, Report) {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var report Report
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	return rec.Code, report
+}
+
+func TestHandler(t *testing.T) {
+	registry := NewRegistry(nil, nil)
+	registry.AddLiveness("process", ok)
+	registry.AddReadiness("db", ok)
+	registry.AddReadiness("cache", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	handler := registry.Handler()
+
+	t.Run("liveness", func(t *testing.T) {
+		code, report := probe(t, handler, LivenessPath)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, StatusUp, report.Status)
+		assert.Equal(t, StatusUp, report.Checks["process"].Status)
+	})
+
+	t.Run("readiness", func(t *testing.T) {
+		code, report := probe(t, handler, ReadinessPath)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, StatusDown, report.Status)
+		assert.Equal(t, StatusUp, report.Checks["db"].Status)
+		assert.Equal(t, StatusDown, report.Checks["cache"].Status)
+		assert.Equal(t, "connection refused", report.Checks["cache"].Error)
+	})
+
+	t.Run("no checks", func(t *testing.T) {
+		code, report := probe(t, NewRegistry(nil, nil).ReadinessHandler(), "/")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, report.Checks)
+	})
+}
+
+func TestCheckTimeout(t *testing.T) {
+	registry := NewRegistry(&Config{Timeout: 50 * time.Millisecond}, nil)
+
+	release := make(chan struct{})
+	defer close(release)
+	registry.AddReadiness("hung", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	registry.AddReadiness("db", ok)
+
+	start := time.Now()
+	report := registry.CheckReadiness(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+
+	assert.False(t, report.Healthy())
+	assert.Equal(t, StatusDown, report.Checks["hung"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["hung"].Error)
+	assert.Equal(t, StatusUp, report.Checks["db"].Status)
+}
+
+func TestZeroTimeout(t *testing.T) {
+	registry := NewRegistry(&Config{}, nil)
+	registry.AddReadiness("db", ok)
+
+	report := registry.CheckReadiness(context.Background())
+	assert.True(t, report.Healthy(), "a zero Timeout uses the default")
+}
+
+func TestCheckPanic(t *testing.T) {
+	registry := NewRegistry(nil, nil)
+	registry.AddReadiness("broken", func(ctx context.Context) error {
+		panic("nil map")
+	})
+	registry.AddReadiness("db", ok)
+
+	report := registry.CheckReadiness(context.Background())
+	assert.False(t, report.Healthy())
+	assert.Equal(t, StatusDown, report.Checks["broken"].Status)
+	assert.Equal(t, "check panicked: nil map", report.Checks["broken"].Error)
+	assert.Equal(t, StatusUp, report.Checks["db"].Status)
+}
+
+func TestServiceHealthIntegration(t *testing.T) {
+	promRegistry := prometheus.NewRegistry()
+	reporter := metrics.New(metrics.Options{Namespace: "test", Registry: promRegistry})
+	serviceHealth := metrics.NewServiceHealth(reporter)
+
+	registry := NewRegistry(&Config{Timeout: time.Second, Service: "orders", Instance: "orders-1"}, serviceHealth)
+	registry.AddReadiness("db", ok)
+	registry.AddReadiness("cache", func(ctx context.Context) error {
+		return errors.New("timeout")
+	})
+
+	registry.CheckReadiness(context.Background())
+
+	families, err := promRegistry.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			key := family.GetName()
+			for _, label := range m.GetLabel() {
+				key += "," + label.GetName() + "=" + label.GetValue()
+			}
+			values[key] = m.GetGauge().GetValue()
+		}
+	}
+
+	assert.Equal(t, 0.0, values["test_service_health_status,instance=orders-1,service=orders"])
+	assert.Equal(t, 1.0, values["test_service_dependency_up,dependency=db"])
+	assert.Equal(t, 0.0, values["test_service_dependency_up,dependency=cache"])
+}
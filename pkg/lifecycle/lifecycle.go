@@ -0,0 +1,154 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// Config holds the shutdown configuration
+type Config struct {
+	// HookTimeout bounds how long a single shutdown hook may run
+	HookTimeout time.Duration `json:"hook_timeout" yaml:"hook_timeout"`
+	// Signals are the signals that trigger shutdown in Wait
+	Signals []os.Signal `json:"-" yaml:"-"`
+}
+
+// DefaultConfig returns the default shutdown configuration
+func DefaultConfig() *Config {
+	return &Config{
+		HookTimeout: 10 * time.Second,
+		Signals:     []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+	}
+}
+
+// Hook releases a component's resources during shutdown
+type Hook func(ctx context.Context) error
+
+// namedHook is a registered shutdown hook
+type namedHook struct {
+	name string
+	fn   Hook
+}
+
+// Shutdowner runs registered shutdown hooks in reverse registration order
+type Shutdowner struct {
+	config *Config
+	logger *logging.Logger
+	mu     sync.Mutex
+	hooks  []namedHook
+}
+
+// New creates a new Shutdowner. A nil config uses DefaultConfig, and a zero
+// HookTimeout or empty Signals take their DefaultConfig values. A nil logger
+// uses the default logger.
+func New(config *Config, logger *logging.Logger) *Shutdowner {
+	defaults := DefaultConfig()
+	if config == nil {
+		config = defaults
+	} else {
+		cfg := *config
+		if cfg.HookTimeout <= 0 {
+			cfg.HookTimeout = defaults.HookTimeout
+		}
+		// signal.Notify with no signals relays every signal, including the
+		// runtime's SIGURG preemption signal
+		if len(cfg.Signals) == 0 {
+			cfg.Signals = defaults.Signals
+		}
+		config = &cfg
+	}
+	if logger == nil {
+		logger = logging.LoggerFromContext(context.Background())
+	}
+	return &Shutdowner{
+		config: config,
+		logger: logger.With(zap.String("component", "lifecycle")),
+	}
+}
+
+// OnShutdown registers a hook. Hooks run last-in, first-out, so components
+// should be registered in the order they are started.
+func (s *Shutdowner) OnShutdown(name string, fn func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, namedHook{name: name, fn: fn})
+}
+
+// Wait blocks until a shutdown signal is received or ctx is done, then runs
+// the shutdown hooks. It returns the joined errors of any failed hooks.
+func (s *Shutdowner) Wait(ctx context.Context) error {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, s.config.Signals...)
+	defer signal.Stop(sigCh)
+
+	select {
+	case sig := <-sigCh:
+		s.logger.Info("Shutdown signal received", zap.String("signal", sig.String()))
+	case <-ctx.Done():
+		s.logger.Info("Shutdown requested", zap.Error(ctx.Err()))
+	}
+
+	// Hooks still need time to run after ctx is cancelled
+	return s.Shutdown(context.WithoutCancel(ctx))
+}
+
+// Shutdown runs all registered hooks in LIFO order, each bounded by the hook
+// timeout, and clears them. Every hook runs even if an earlier one fails.
+func (s *Shutdowner) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	hooks := s.hooks
+	s.hooks = nil
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := s.runHook(ctx, hooks[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runHook runs a single hook, abandoning it if it outlives the hook timeout
+func (s *Shutdowner) runHook(ctx context.Context, hook namedHook) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.HookTimeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	duration := time.Since(start)
+	if err != nil {
+		s.logger.Error("Shutdown hook failed",
+			zap.String("hook", hook.name),
+			zap.Duration("duration", duration),
+			zap.Error(err),
+		)
+		return fmt.Errorf("shutdown hook %s: %w", hook.name, err)
+	}
+
+	s.logger.Info("Shutdown hook completed",
+		zap.String("hook", hook.name),
+		zap.Duration("duration", duration),
+	)
+	return nil
+}
@@ -0,0 +1,162 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	loggingtest "github.com/StackCatalyst/common-lib/pkg/logging/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestShutdowner(t *testing.T, config *Config) (*Shutdowner, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger, err := loggingtest.NewTestLogger(&buf)
+	require.NoError(t, err)
+	return New(config, logger), &buf
+}
+
+func TestShutdown(t *testing.T) {
+	t.Run("runs hooks in LIFO order", func(t *testing.T) {
+		s, buf := newTestShutdowner(t, nil)
+
+		var order []string
+		for _, name := range []string{"metrics", "tracer", "db", "grpc"} {
+			name := name
+			s.OnShutdown(name, func(ctx context.Context) error {
+				order = append(order, name)
+				return nil
+			})
+		}
+
+		require.NoError(t, s.Shutdown(context.Background()))
+		assert.Equal(t, []string{"grpc", "db", "tracer", "metrics"}, order)
+		assert.Contains(t, buf.String(), "Shutdown hook completed")
+
+		// Hooks only run once
+		order = nil
+		require.NoError(t, s.Shutdown(context.Background()))
+		assert.Empty(t, order)
+	})
+
+	t.Run("continues after failures", func(t *testing.T) {
+		s, buf := newTestShutdowner(t, nil)
+
+		errDB := errors.New("pool busy")
+		var ran []string
+		s.OnShutdown("metrics", func(ctx context.Context) error {
+			ran = append(ran, "metrics")
+			return nil
+		})
+		s.OnShutdown("db", func(ctx context.Context) error {
+			ran = append(ran, "db")
+			return errDB
+		})
+
+		err := s.Shutdown(context.Background())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errDB)
+		assert.Contains(t, err.Error(), "shutdown hook db")
+		assert.Equal(t, []string{"db", "metrics"}, ran)
+		assert.Contains(t, buf.String(), "Shutdown hook failed")
+	})
+
+	t.Run("times out hung hooks", func(t *testing.T) {
+		s, _ := newTestShutdowner(t, &Config{HookTimeout: 50 * time.Millisecond})
+
+		release := make(chan struct{})
+		defer close(release)
+		s.OnShutdown("hung", func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+		ran := false
+		s.OnShutdown("first", func(ctx context.Context) error {
+			ran = true
+			return nil
+		})
+
+		start := time.Now()
+		err := s.Shutdown(context.Background())
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.True(t, ran)
+	})
+}
+
+func TestNewDefaults(t *testing.T) {
+	config := &Config{}
+	s, _ := newTestShutdowner(t, config)
+
+	defaults := DefaultConfig()
+	assert.Equal(t, defaults.HookTimeout, s.config.HookTimeout)
+	assert.Equal(t, defaults.Signals, s.config.Signals)
+	assert.Equal(t, &Config{}, config, "the caller's config is not modified")
+
+	var hookErr error
+	s.OnShutdown("server", func(ctx context.Context) error {
+		hookErr = ctx.Err()
+		return nil
+	})
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.NoError(t, hookErr, "hooks get a live context with a zero HookTimeout")
+}
+
+func TestWait(t *testing.T) {
+	t.Run("signal", func(t *testing.T) {
+		s, buf := newTestShutdowner(t, &Config{
+			HookTimeout: time.Second,
+			Signals:     []os.Signal{syscall.SIGUSR1},
+		})
+
+		stopped := make(chan struct{})
+		s.OnShutdown("server", func(ctx context.Context) error {
+			close(stopped)
+			return nil
+		})
+
+		// Catch the signal in the test too, so it never reaches the default
+		// handler before Wait has registered
+		guard := make(chan os.Signal, 16)
+		signal.Notify(guard, syscall.SIGUSR1)
+		defer signal.Stop(guard)
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- s.Wait(context.Background()) }()
+
+		// Keep signalling until Wait has registered for the signal and returned
+		require.Eventually(t, func() bool {
+			require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+			select {
+			case <-stopped:
+				return true
+			default:
+				return false
+			}
+		}, 5*time.Second, 20*time.Millisecond)
+
+		require.NoError(t, <-errCh)
+		assert.Contains(t, buf.String(), "Shutdown signal received")
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		s, _ := newTestShutdowner(t, nil)
+
+		var hookErr error
+		s.OnShutdown("server", func(ctx context.Context) error {
+			hookErr = ctx.Err()
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.NoError(t, s.Wait(ctx))
+		assert.NoError(t, hookErr, "hooks get a live context after cancellation")
+	})
+}
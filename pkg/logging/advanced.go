@@ -2,15 +2,22 @@ package logging
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	commonerrors "github.com/StackCatalyst/common-lib/pkg/errors"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
@@ -27,19 +34,53 @@ const (
 	UserIDKey ContextKey = "user_id"
 	// RequestIDKey is the key used to store request IDs in context
 	RequestIDKey ContextKey = "request_id"
+	// LoggerKey is the key used to store the request-scoped logger in context
+	LoggerKey ContextKey = "logger"
 )
 
+var (
+	defaultLogger     *Logger
+	defaultLoggerOnce sync.Once
+)
+
+// ContextWithLogger returns a copy of ctx carrying logger
+func ContextWithLogger(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, LoggerKey, logger)
+}
+
+// LoggerFromContext returns the logger stored in ctx by HTTPMiddleware or
+// ContextWithLogger, falling back to a default stdout logger
+func LoggerFromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(LoggerKey).(*Logger); ok && logger != nil {
+		return logger
+	}
+
+	defaultLoggerOnce.Do(func() {
+		logger, err := New(DefaultConfig())
+		if err != nil {
+			logger = &Logger{zap: zap.NewNop()}
+		}
+		defaultLogger = logger
+	})
+	return defaultLogger
+}
+
 // AdvancedConfig extends the basic Config with additional options
 type AdvancedConfig struct {
 	Config
+	// OutputPaths writes to several outputs at once ("stdout", "stderr" or file
+	// paths, which are rotated); it takes precedence over Config.OutputPath
+	OutputPaths []string `json:"output_paths"`
 	// Rotation settings
 	MaxSize    int  `json:"max_size"`    // Maximum size in megabytes before rotation
 	MaxBackups int  `json:"max_backups"` // Maximum number of old log files to retain
 	MaxAge     int  `json:"max_age"`     // Maximum number of days to retain old log files
 	Compress   bool `json:"compress"`    // Compress rotated files
 	// Sampling settings
-	SampleInitial    int `json:"sample_initial"`    // Sample up to n entries per second
+	SampleInitial    int `json:"sample_initial"`    // Sample up to n debug/info entries per second; warnings and errors are never sampled
 	SampleThereafter int `json:"sample_thereafter"` // Sample every nth entry after initial
+	// Redaction settings
+	RedactKeys []string `json:"redact_keys"` // Field keys whose values are masked, matched case-insensitively
 }
 
 // DefaultAdvancedConfig returns default advanced logger configuration
@@ -52,40 +93,65 @@ func DefaultAdvancedConfig() AdvancedConfig {
 		Compress:         true,
 		SampleInitial:    100,
 		SampleThereafter: 100,
+		RedactKeys:       []string{"password", "secret", "token", "authorization"},
 	}
 }
 
 // NewAdvanced creates a new logger with advanced features
 func NewAdvanced(cfg AdvancedConfig) (*Logger, error) {
-	level, err := parseLevel(cfg.Level)
-	if err != nil {
-		return nil, err
+	paths := cfg.OutputPaths
+	if len(paths) == 0 {
+		paths = []string{cfg.OutputPath}
 	}
 
-	// Create the log directory if it doesn't exist
-	if cfg.OutputPath != "stdout" && cfg.OutputPath != "stderr" {
-		dir := filepath.Dir(cfg.OutputPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create log directory: %w", err)
+	outputs := make([]zapcore.WriteSyncer, 0, len(paths))
+	for _, path := range paths {
+		output, err := openOutput(cfg, path)
+		if err != nil {
+			return nil, err
 		}
+		outputs = append(outputs, output)
 	}
 
-	// Configure log rotation if not writing to stdout/stderr
-	var output zapcore.WriteSyncer
-	if cfg.OutputPath == "stdout" {
-		output = zapcore.AddSync(os.Stdout)
-	} else if cfg.OutputPath == "stderr" {
-		output = zapcore.AddSync(os.Stderr)
-	} else {
-		output = zapcore.AddSync(&lumberjack.Logger{
-			Filename:   cfg.OutputPath,
-			MaxSize:    cfg.MaxSize,
-			MaxBackups: cfg.MaxBackups,
-			MaxAge:     cfg.MaxAge,
-			Compress:   cfg.Compress,
-		})
+	output := outputs[0]
+	if len(outputs) > 1 {
+		output = zapcore.NewMultiWriteSyncer(outputs...)
+	}
+
+	return newAdvanced(cfg, output)
+}
+
+// openOutput returns a write syncer for stdout, stderr or a rotated log file
+func openOutput(cfg AdvancedConfig, path string) (zapcore.WriteSyncer, error) {
+	switch path {
+	case "stdout":
+		return zapcore.AddSync(os.Stdout), nil
+	case "stderr":
+		return zapcore.AddSync(os.Stderr), nil
+	}
+
+	// Create the log directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
+	return zapcore.AddSync(&lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    cfg.MaxSize,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAge,
+		Compress:   cfg.Compress,
+	}), nil
+}
+
+// newAdvanced creates an advanced logger writing to output
+func newAdvanced(cfg AdvancedConfig, output zapcore.WriteSyncer) (*Logger, error) {
+	level, err := parseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	atomicLevel := zap.NewAtomicLevelAt(level)
+
 	encoderConfig := defaultEncoderConfig()
 	var encoder zapcore.Encoder
 	if cfg.Encoding == "json" {
@@ -93,28 +159,154 @@ func NewAdvanced(cfg AdvancedConfig) (*Logger, error) {
 	} else {
 		encoder = zapcore.NewConsoleEncoder(encoderConfig)
 	}
+	if len(cfg.RedactKeys) > 0 {
+		encoder = NewRedactingEncoder(encoder, cfg.RedactKeys...)
+	}
 
-	core := zapcore.NewSamplerWithOptions(
-		zapcore.NewCore(encoder, output, level),
-		time.Second,
-		cfg.SampleInitial,
-		cfg.SampleThereafter,
-	)
+	var base zapcore.Core = zapcore.NewCore(encoder, output, atomicLevel)
+
+	core := &samplingCore{
+		Core:    base,
+		sampler: &atomic.Pointer[zapcore.Core]{},
+	}
+	core.sampler.Store(newSampler(cfg.SampleInitial, cfg.SampleThereafter))
 
 	logger := zap.New(core,
 		zap.AddCaller(),
 		zap.AddStacktrace(zapcore.ErrorLevel),
 	)
 
-	return &Logger{zap: logger}, nil
+	return &Logger{zap: logger, level: &atomicLevel, sampler: core.sampler}, nil
+}
+
+// SetSampling replaces the sampling policy of the logger and all loggers
+// derived from it: the first initial entries with the same level and message
+// are logged each second, then every thereafter-th one. An initial value of 0
+// or less disables sampling so every entry is logged. Warnings and errors are
+// never sampled.
+func (l *Logger) SetSampling(initial, thereafter int) error {
+	if l.sampler == nil {
+		return errors.New("logger does not support changing sampling")
+	}
+	l.sampler.Store(newSampler(initial, thereafter))
+	return nil
+}
+
+// unsampledLevel is the lowest level that is never sampled, so warnings and
+// errors are not dropped during an incident
+const unsampledLevel = zapcore.WarnLevel
+
+// samplingCore is a zapcore.Core whose sampling policy can be swapped at
+// runtime. The sampler only makes the keep/drop decision for entries below
+// unsampledLevel; kept entries are written by the wrapped core.
+type samplingCore struct {
+	zapcore.Core
+	sampler *atomic.Pointer[zapcore.Core]
+}
+
+// With adds structured context to the core, sharing the sampling policy
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplingCore{Core: c.Core.With(fields), sampler: c.sampler}
+}
+
+// Check drops a low-level entry if the current sampler rejects it
+func (c *samplingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if ent.Level >= unsampledLevel {
+		return c.Core.Check(ent, ce)
+	}
+	if sampler := c.sampler.Load(); sampler != nil && (*sampler).Check(ent, nil) == nil {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// newSampler returns a sampler for the given policy, or nil if sampling is disabled
+func newSampler(initial, thereafter int) *zapcore.Core {
+	if initial <= 0 {
+		return nil
+	}
+	sampler := zapcore.NewSamplerWithOptions(keepCore{}, time.Second, initial, thereafter)
+	return &sampler
+}
+
+// redactingCore masks the values of fields with sensitive keys before they
+// reach the encoder, so every encoding is covered
+type redactingCore struct {
+	zapcore.Core
+	r redactor
+}
+
+// newRedactingCore wraps core so that fields whose key matches one of keys are masked
+func newRedactingCore(core zapcore.Core, keys []string) *redactingCore {
+	return &redactingCore{Core: core, r: newRedactor(keys)}
+}
+
+// With adds structured context to the core, masking sensitive fields
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.r.fields(fields)), r: c.r}
 }
 
+// Check lets the wrapped core decide whether the entry is logged, so sampling
+// below the redacting core still applies, and then writes it through c
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Core.Check(ent, nil) == nil {
+		return ce
+	}
+	return ce.AddCore(ent, c)
+}
+
+// Write masks sensitive fields and writes the entry to the wrapped core
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.r.fields(fields))
+}
+
+// WithRedaction returns a logger that masks the values of fields whose key
+// matches one of keys, case-insensitively, in addition to any configured keys
+func (l *Logger) WithRedaction(keys ...string) *Logger {
+	if len(keys) == 0 {
+		return l
+	}
+	zapLogger := l.zap.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newRedactingCore(core, keys)
+	}))
+	return &Logger{zap: zapLogger, level: l.level, sampler: l.sampler}
+}
+
+// keepEntry is the non-nil result keepCore uses to signal that the sampler kept an entry
+var keepEntry = &zapcore.CheckedEntry{}
+
+// keepCore is the core beneath a decision-only sampler; it never writes
+type keepCore struct{}
+
+func (keepCore) Enabled(zapcore.Level) bool                                       { return true }
+func (keepCore) With([]zapcore.Field) zapcore.Core                                { return keepCore{} }
+func (keepCore) Check(zapcore.Entry, *zapcore.CheckedEntry) *zapcore.CheckedEntry { return keepEntry }
+func (keepCore) Write(zapcore.Entry, []zapcore.Field) error                       { return nil }
+func (keepCore) Sync() error                                                      { return nil }
+
 // WithTracing adds request tracing to the logger
 func (l *Logger) WithTracing() *Logger {
 	traceID := TraceID(uuid.New().String())
 	return l.With(zap.String("trace_id", string(traceID)))
 }
 
+// WithSpanContext adds trace_id and span_id fields from the span in ctx so log
+// lines can be correlated with traces. The logger is returned unchanged if ctx
+// carries no span.
+func (l *Logger) WithSpanContext(ctx context.Context) *Logger {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return l.With(
+			zap.String("trace_id", sc.TraceID().String()),
+			zap.String("span_id", sc.SpanID().String()),
+		)
+	}
+
+	return l
+}
+
 // FromContext extracts logging fields from context
 func (l *Logger) FromContext(ctx context.Context) *Logger {
 	logger := l
@@ -137,18 +329,44 @@ func (l *Logger) FromContext(ctx context.Context) *Logger {
 	return logger
 }
 
-// HTTPMiddleware creates a middleware that adds request information to the logger
+// WithError returns a logger carrying the error, its error code, and any
+// structured fields attached to it with errors.WithFields
+func (l *Logger) WithError(err error) *Logger {
+	if err == nil {
+		return l
+	}
+
+	errFields := commonerrors.Fields(err)
+	fields := make([]zapcore.Field, 0, len(errFields)+2)
+	fields = append(fields, zap.Error(err))
+	if code, ok := commonerrors.GetCode(err); ok {
+		fields = append(fields, zap.String("error_code", string(code)))
+	}
+	for k, v := range errFields {
+		fields = append(fields, zap.Any(k, v))
+	}
+
+	return l.With(fields...)
+}
+
+// HTTPMiddleware creates a middleware that adds request information to the logger.
+// The trace ID is taken from an incoming X-Trace-ID header when present so
+// traces continue across services; otherwise a new one is generated. The
+// request ID is handled as by RequestIDMiddleware and echoed in the response.
 func (l *Logger) HTTPMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		// Generate trace ID and request ID
-		traceID := TraceID(uuid.New().String())
-		requestID := uuid.New().String()
+		// Reuse the caller's trace and request IDs
+		traceID := TraceID(r.Header.Get(TraceIDHeader))
+		if traceID == "" {
+			traceID = TraceID(uuid.New().String())
+		}
+		ctx, requestID := httpRequestID(r)
+		w.Header().Set(RequestIDHeader, requestID)
 
 		// Add IDs to context
-		ctx := context.WithValue(r.Context(), TraceIDKey, traceID)
-		ctx = context.WithValue(ctx, RequestIDKey, requestID)
+		ctx = context.WithValue(ctx, TraceIDKey, traceID)
 
 		// Create request-scoped logger
 		reqLogger := l.With(
@@ -160,19 +378,101 @@ func (l *Logger) HTTPMiddleware(next http.Handler) http.Handler {
 			zap.String("user_agent", r.UserAgent()),
 		)
 
+		ctx = ContextWithLogger(ctx, reqLogger)
+
 		// Log request
 		reqLogger.Info("Request started")
 
 		// Call next handler with updated context
-		next.ServeHTTP(w, r.WithContext(ctx))
+		rw := wrapResponseWriter(w)
+		next.ServeHTTP(rw, r.WithContext(ctx))
 
 		// Log request completion
 		reqLogger.Info("Request completed",
 			zap.Duration("duration", time.Since(start)),
+			zap.Int("status", rw.Status()),
+			zap.Int64("bytes", rw.BytesWritten()),
+			zap.Bool("canceled", ctx.Err() != nil),
 		)
 	})
 }
 
+// responseWriter wraps http.ResponseWriter to capture the status code and response size
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (rw *responseWriter) Status() int {
+	return rw.status
+}
+
+func (rw *responseWriter) BytesWritten() int64 {
+	return rw.bytes
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.status = code
+		rw.wroteHeader = true
+		rw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor that logs each unary call
+// with its method, duration and status code, plus the trace and request IDs
+// from the call context. Failed calls are logged at error level.
+func (l *Logger) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		l.logGRPCCall(ctx, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a gRPC interceptor that logs each streaming
+// call once the stream completes, with the same fields as UnaryServerInterceptor
+func (l *Logger) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		l.logGRPCCall(ss.Context(), info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+// logGRPCCall logs the outcome of a gRPC call
+func (l *Logger) logGRPCCall(ctx context.Context, method string, err error, duration time.Duration) {
+	logger := l.FromContext(ctx)
+	fields := []zapcore.Field{
+		zap.String("method", method),
+		zap.String("code", status.Code(err).String()),
+		zap.Duration("duration", duration),
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("Request failed", fields...)
+		return
+	}
+	logger.Info("Request completed", fields...)
+}
+
 // Audit logs an audit event with user and action information
 func (l *Logger) Audit(ctx context.Context, action string, details map[string]interface{}) {
 	logger := l.FromContext(ctx)
@@ -4,15 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	commonerrors "github.com/StackCatalyst/common-lib/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestAdvancedLogger(t *testing.T) {
@@ -77,6 +85,209 @@ func TestAdvancedLogger(t *testing.T) {
 	assert.True(t, hasCompressedBackup, "Expected at least one compressed backup file")
 }
 
+func TestMultipleOutputs(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "logs", "app.log")
+
+	// Capture stdout, which the logger binds at construction
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	cfg := DefaultAdvancedConfig()
+	cfg.OutputPaths = []string{"stdout", logPath}
+	logger, err := NewAdvanced(cfg)
+	os.Stdout = stdout
+	require.NoError(t, err)
+
+	// Writes are unbuffered; Sync is skipped because pipes can't be fsynced
+	logger.Info("fan out entry")
+	require.NoError(t, writer.Close())
+
+	captured, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(captured), "fan out entry")
+
+	written, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(written), "fan out entry")
+}
+
+func TestSetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newAdvanced(DefaultAdvancedConfig(), zapcore.AddSync(&buf))
+	require.NoError(t, err)
+	child := logger.With(zapcore.Field{Key: "component", Type: zapcore.StringType, String: "test"})
+
+	logger.Debug("hidden debug line")
+	assert.Empty(t, buf.String())
+
+	require.NoError(t, logger.SetLevel(Debug))
+	child.Debug("visible debug line")
+	assert.Contains(t, buf.String(), "visible debug line")
+	assert.NotContains(t, buf.String(), "hidden debug line")
+
+	require.NoError(t, logger.SetLevel(Warn))
+	assert.Equal(t, Warn, child.GetLevel())
+	buf.Reset()
+	logger.Info("hidden info line")
+	assert.Empty(t, buf.String())
+
+	assert.Error(t, logger.SetLevel("verbose"))
+	assert.Equal(t, Warn, logger.GetLevel())
+
+	// Loggers without an atomic level can't be changed
+	plain, err := createTestLogger(&buf)
+	require.NoError(t, err)
+	assert.Error(t, plain.SetLevel(Debug))
+}
+
+func TestSetSampling(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := DefaultAdvancedConfig()
+	cfg.SampleInitial = 1
+	cfg.SampleThereafter = 100
+	logger, err := newAdvanced(cfg, zapcore.AddSync(&buf))
+	require.NoError(t, err)
+
+	countLines := func() int {
+		return bytes.Count(buf.Bytes(), []byte("\n"))
+	}
+
+	for i := 0; i < 5; i++ {
+		logger.Info("repeated line")
+	}
+	assert.Equal(t, 1, countLines())
+
+	// Disabling sampling logs every line
+	require.NoError(t, logger.SetSampling(0, 0))
+	buf.Reset()
+	for i := 0; i < 5; i++ {
+		logger.Info("repeated line")
+	}
+	assert.Equal(t, 5, countLines())
+
+	// Re-enabling starts a fresh sampling window
+	require.NoError(t, logger.SetSampling(2, 100))
+	buf.Reset()
+	for i := 0; i < 5; i++ {
+		logger.Info("repeated line")
+	}
+	assert.Equal(t, 2, countLines())
+
+	plain, err := createTestLogger(&buf)
+	require.NoError(t, err)
+	assert.Error(t, plain.SetSampling(0, 0))
+}
+
+func TestSamplingKeepsErrors(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := DefaultAdvancedConfig()
+	cfg.SampleInitial = 1
+	cfg.SampleThereafter = 100
+	logger, err := newAdvanced(cfg, zapcore.AddSync(&buf))
+	require.NoError(t, err)
+
+	for i := 0; i < 50; i++ {
+		logger.Info("repeated info")
+		logger.Warn("repeated warning")
+		logger.Error("repeated error")
+	}
+
+	out := buf.String()
+	assert.Equal(t, 1, strings.Count(out, "repeated info"))
+	assert.Equal(t, 50, strings.Count(out, "repeated warning"))
+	assert.Equal(t, 50, strings.Count(out, "repeated error"))
+}
+
+func TestRedaction(t *testing.T) {
+	for _, encoding := range []string{"json", "console"} {
+		t.Run(encoding, func(t *testing.T) {
+			var buf bytes.Buffer
+			cfg := DefaultAdvancedConfig()
+			cfg.Encoding = encoding
+			cfg.RedactKeys = []string{"password", "Authorization"}
+			logger, err := newAdvanced(cfg, zapcore.AddSync(&buf))
+			require.NoError(t, err)
+
+			logger.With(zapcore.Field{Key: "authorization", Type: zapcore.StringType, String: "Bearer abc123"}).
+				Info("User login",
+					zapcore.Field{Key: "user", Type: zapcore.StringType, String: "alice"},
+					zapcore.Field{Key: "Password", Type: zapcore.StringType, String: "hunter2"},
+				)
+
+			out := buf.String()
+			assert.Contains(t, out, "alice")
+			assert.Contains(t, out, "***")
+			assert.NotContains(t, out, "hunter2")
+			assert.NotContains(t, out, "abc123")
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		cfg := DefaultAdvancedConfig()
+		cfg.RedactKeys = nil
+		logger, err := newAdvanced(cfg, zapcore.AddSync(&buf))
+		require.NoError(t, err)
+
+		logger.Info("User login", zapcore.Field{Key: "password", Type: zapcore.StringType, String: "hunter2"})
+		assert.Contains(t, buf.String(), "hunter2")
+	})
+}
+
+func TestWithRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	plain, err := createTestLogger(&buf)
+	require.NoError(t, err)
+	logger := plain.WithRedaction("password", "TOKEN")
+
+	t.Run("audit", func(t *testing.T) {
+		buf.Reset()
+		logger.Audit(context.Background(), "user.update", map[string]interface{}{
+			"user":     "alice",
+			"Password": "hunter2",
+		})
+
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "alice", entry["user"])
+		assert.Equal(t, "***", entry["Password"])
+		assert.Equal(t, "user.update", entry["action"])
+	})
+
+	t.Run("metric", func(t *testing.T) {
+		buf.Reset()
+		logger.LogMetric("api_calls", 3, map[string]string{"token": "abc123", "region": "eu"})
+
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "***", entry["token"])
+		assert.Equal(t, "eu", entry["region"])
+	})
+
+	assert.Same(t, plain, plain.WithRedaction())
+}
+
+func TestWithRedactionKeepsSampling(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := DefaultAdvancedConfig()
+	cfg.SampleInitial = 1
+	cfg.SampleThereafter = 100
+	sampled, err := newAdvanced(cfg, zapcore.AddSync(&buf))
+	require.NoError(t, err)
+	logger := sampled.WithRedaction("password")
+
+	for i := 0; i < 10; i++ {
+		logger.Info("repeated line", zapcore.Field{Key: "password", Type: zapcore.StringType, String: "hunter2"})
+	}
+
+	out := buf.String()
+	assert.Equal(t, 1, strings.Count(out, "repeated line"))
+	assert.NotContains(t, out, "hunter2")
+}
+
 func TestWithTracing(t *testing.T) {
 	var buf bytes.Buffer
 	logger, err := createTestLogger(&buf)
@@ -95,6 +306,41 @@ func TestWithTracing(t *testing.T) {
 	assert.NotEmpty(t, logEntry["trace_id"])
 }
 
+func TestWithSpanContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := createTestLogger(&buf)
+	require.NoError(t, err)
+
+	decode := func() map[string]interface{} {
+		var logEntry map[string]interface{}
+		require.NoError(t, json.NewDecoder(&buf).Decode(&logEntry))
+		return logEntry
+	}
+
+	t.Run("opentelemetry span", func(t *testing.T) {
+		traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		require.NoError(t, err)
+		spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+		require.NoError(t, err)
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}))
+
+		logger.WithSpanContext(ctx).Info("traced")
+		logEntry := decode()
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", logEntry["trace_id"])
+		assert.Equal(t, "00f067aa0ba902b7", logEntry["span_id"])
+	})
+
+	t.Run("no span", func(t *testing.T) {
+		logger.WithSpanContext(context.Background()).Info("untraced")
+		logEntry := decode()
+		assert.NotContains(t, logEntry, "trace_id")
+		assert.NotContains(t, logEntry, "span_id")
+	})
+}
+
 func TestFromContext(t *testing.T) {
 	var buf bytes.Buffer
 	logger, err := createTestLogger(&buf)
@@ -164,6 +410,168 @@ func TestHTTPMiddleware(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "Request completed", completeLog["msg"])
 	assert.NotEmpty(t, completeLog["duration"])
+	assert.Equal(t, float64(http.StatusOK), completeLog["status"])
+	assert.Equal(t, float64(0), completeLog["bytes"])
+	assert.Equal(t, false, completeLog["canceled"])
+}
+
+func TestHTTPMiddlewareResponseFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := createTestLogger(&buf)
+	require.NoError(t, err)
+
+	t.Run("status and size", func(t *testing.T) {
+		buf.Reset()
+		handler := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+		completeLog := lastLogEntry(t, &buf)
+		assert.Equal(t, float64(http.StatusNotFound), completeLog["status"])
+		assert.Equal(t, float64(len("not found")), completeLog["bytes"])
+		assert.Equal(t, false, completeLog["canceled"])
+	})
+
+	t.Run("implicit status", func(t *testing.T) {
+		buf.Reset()
+		handler := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		completeLog := lastLogEntry(t, &buf)
+		assert.Equal(t, float64(http.StatusOK), completeLog["status"])
+		assert.Equal(t, float64(len("ok")), completeLog["bytes"])
+	})
+
+	t.Run("canceled request", func(t *testing.T) {
+		buf.Reset()
+		ctx, cancel := context.WithCancel(context.Background())
+		handler := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Simulate the client disconnecting while the handler runs
+			cancel()
+			<-r.Context().Done()
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil).WithContext(ctx))
+
+		completeLog := lastLogEntry(t, &buf)
+		assert.Equal(t, true, completeLog["canceled"])
+	})
+}
+
+func TestHTTPMiddlewareContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := createTestLogger(&buf)
+	require.NoError(t, err)
+
+	handler := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		LoggerFromContext(r.Context()).Info("Handling order")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders", nil))
+
+	decoder := json.NewDecoder(&buf)
+	var startLog, handlerLog map[string]interface{}
+	require.NoError(t, decoder.Decode(&startLog))
+	require.NoError(t, decoder.Decode(&handlerLog))
+
+	assert.Equal(t, "Handling order", handlerLog["msg"])
+	assert.NotEmpty(t, handlerLog["trace_id"])
+	assert.Equal(t, startLog["trace_id"], handlerLog["trace_id"])
+	assert.Equal(t, startLog["request_id"], handlerLog["request_id"])
+
+	// Without a stored logger the default is returned
+	fallback := LoggerFromContext(context.Background())
+	require.NotNil(t, fallback)
+	assert.Same(t, fallback, LoggerFromContext(context.Background()))
+}
+
+// lastLogEntry decodes the final JSON log entry written to buf
+func lastLogEntry(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.NotEmpty(t, lines)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[len(lines)-1], &entry))
+	return entry
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := createTestLogger(&buf)
+	require.NoError(t, err)
+
+	interceptor := logger.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	ctx := context.WithValue(context.Background(), TraceIDKey, TraceID("test-trace-id"))
+	ctx = context.WithValue(ctx, RequestIDKey, "test-request-id")
+
+	// Successful call
+	resp, err := interceptor(ctx, "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "response", resp)
+
+	var logEntry map[string]interface{}
+	require.NoError(t, json.NewDecoder(&buf).Decode(&logEntry))
+	assert.Equal(t, "info", logEntry["level"])
+	assert.Equal(t, "Request completed", logEntry["msg"])
+	assert.Equal(t, "/test.Service/Method", logEntry["method"])
+	assert.Equal(t, "OK", logEntry["code"])
+	assert.Equal(t, "test-trace-id", logEntry["trace_id"])
+	assert.Equal(t, "test-request-id", logEntry["request_id"])
+	assert.NotEmpty(t, logEntry["duration"])
+
+	// Failed call
+	buf.Reset()
+	_, err = interceptor(ctx, "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "module not found")
+	})
+	require.Error(t, err)
+
+	logEntry = nil
+	require.NoError(t, json.NewDecoder(&buf).Decode(&logEntry))
+	assert.Equal(t, "error", logEntry["level"])
+	assert.Equal(t, "Request failed", logEntry["msg"])
+	assert.Equal(t, "NotFound", logEntry["code"])
+	assert.Contains(t, logEntry["error"], "module not found")
+}
+
+// testServerStream is a grpc.ServerStream carrying a fixed context
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := createTestLogger(&buf)
+	require.NoError(t, err)
+
+	interceptor := logger.StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+	stream := &testServerStream{
+		ctx: context.WithValue(context.Background(), TraceIDKey, TraceID("test-trace-id")),
+	}
+
+	err = interceptor(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return status.Error(codes.Unavailable, "downstream unavailable")
+	})
+	require.Error(t, err)
+
+	var logEntry map[string]interface{}
+	require.NoError(t, json.NewDecoder(&buf).Decode(&logEntry))
+	assert.Equal(t, "error", logEntry["level"])
+	assert.Equal(t, "/test.Service/Stream", logEntry["method"])
+	assert.Equal(t, "Unavailable", logEntry["code"])
+	assert.Equal(t, "test-trace-id", logEntry["trace_id"])
 }
 
 func TestAudit(t *testing.T) {
@@ -219,3 +627,38 @@ func TestLogMetric(t *testing.T) {
 	assert.Equal(t, "test-service", logEntry["service"])
 	assert.Equal(t, "test", logEntry["env"])
 }
+
+func TestWithError(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := createTestLogger(&buf)
+	require.NoError(t, err)
+
+	moduleErr := commonerrors.WithFields(
+		commonerrors.New(commonerrors.ErrNotFound, "module not found"),
+		map[string]interface{}{"module_id": "vpc", "version": "1.0.0"},
+	)
+	logger.WithError(moduleErr).Error("Failed to load module")
+
+	var logEntry map[string]interface{}
+	err = json.NewDecoder(&buf).Decode(&logEntry)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Failed to load module", logEntry["msg"])
+	assert.Equal(t, "NOT_FOUND: module not found", logEntry["error"])
+	assert.Equal(t, "NOT_FOUND", logEntry["error_code"])
+	assert.Equal(t, "vpc", logEntry["module_id"])
+	assert.Equal(t, "1.0.0", logEntry["version"])
+
+	// Plain errors are logged without a code
+	buf.Reset()
+	logger.WithError(fmt.Errorf("connection refused")).Error("Failed to connect")
+
+	logEntry = nil
+	err = json.NewDecoder(&buf).Decode(&logEntry)
+	require.NoError(t, err)
+	assert.Equal(t, "connection refused", logEntry["error"])
+	assert.NotContains(t, logEntry, "error_code")
+
+	// A nil error leaves the logger unchanged
+	assert.Same(t, logger, logger.WithError(nil))
+}
@@ -2,7 +2,11 @@ package logging
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -25,6 +29,10 @@ const (
 // Logger wraps zap logger with additional functionality
 type Logger struct {
 	zap *zap.Logger
+	// level is shared with child loggers; nil if the level can't be changed at runtime
+	level *zap.AtomicLevel
+	// sampler is shared with child loggers; nil if sampling can't be changed at runtime
+	sampler *atomic.Pointer[zapcore.Core]
 }
 
 // Config holds logger configuration
@@ -68,7 +76,7 @@ func New(cfg Config) (*Logger, error) {
 		return nil, err
 	}
 
-	return &Logger{zap: logger}, nil
+	return &Logger{zap: logger, level: &zapCfg.Level}, nil
 }
 
 // NewFromZap creates a new logger instance from an existing zap logger
@@ -81,7 +89,66 @@ func NewFromZap(zapLogger *zap.Logger) (*Logger, error) {
 
 // With creates a child logger with additional fields
 func (l *Logger) With(fields ...zapcore.Field) *Logger {
-	return &Logger{zap: l.zap.With(fields...)}
+	return &Logger{zap: l.zap.With(fields...), level: l.level, sampler: l.sampler}
+}
+
+// SetLevel changes the minimum enabled level of the logger and all loggers
+// derived from it, without locking
+func (l *Logger) SetLevel(level Level) error {
+	if l.level == nil {
+		return errors.New("logger does not support changing the level")
+	}
+	switch level {
+	case Debug, Info, Warn, Error:
+	default:
+		return fmt.Errorf("unknown log level %q", level)
+	}
+	zapLevel, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.level.SetLevel(zapLevel)
+	return nil
+}
+
+// GetLevel returns the minimum enabled level of the logger
+func (l *Logger) GetLevel() Level {
+	if l.level != nil {
+		return Level(l.level.Level().String())
+	}
+	return Level(l.zap.Level().String())
+}
+
+// levelPayload is the request and response body of LevelHandler
+type levelPayload struct {
+	Level Level `json:"level"`
+}
+
+// LevelHandler returns an HTTP handler that reports the current level on GET
+// and changes it on PUT with a body like {"level":"debug"}
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var payload levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+				return
+			}
+			if err := l.SetLevel(payload.Level); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(levelPayload{Level: l.GetLevel()})
+	})
 }
 
 // Debug logs a message at debug level
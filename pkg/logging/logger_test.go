@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -132,3 +135,36 @@ func TestMain(m *testing.M) {
 
 	os.Exit(code)
 }
+
+func TestLevelHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newAdvanced(DefaultAdvancedConfig(), zapcore.AddSync(&buf))
+	require.NoError(t, err)
+	handler := logger.LevelHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"info"}`, rec.Body.String())
+
+	logger.Debug("hidden debug line")
+	assert.Empty(t, buf.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"debug"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"debug"}`, rec.Body.String())
+	assert.Equal(t, Debug, logger.GetLevel())
+
+	logger.Debug("visible debug line")
+	assert.Contains(t, buf.String(), "visible debug line")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"verbose"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, Debug, logger.GetLevel())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/log/level", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
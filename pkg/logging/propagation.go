@@ -0,0 +1,142 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// TraceIDHeader carries the trace ID between services
+	TraceIDHeader = "X-Trace-ID"
+	// RequestIDHeader carries the ID of the calling request between services
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDMetadataKey carries the request ID in gRPC metadata
+	RequestIDMetadataKey = "x-request-id"
+)
+
+// RequestIDMiddleware stores a request ID in the request context under
+// RequestIDKey and echoes it in the X-Request-ID response header. An ID already
+// in the context or an incoming X-Request-ID header is reused; otherwise a new
+// one is generated.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, requestID := httpRequestID(r)
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// httpRequestID returns the request's context carrying its request ID
+func httpRequestID(r *http.Request) (context.Context, string) {
+	ctx := r.Context()
+	if requestID, ok := ctx.Value(RequestIDKey).(string); ok && requestID != "" {
+		return ctx, requestID
+	}
+
+	requestID := r.Header.Get(RequestIDHeader)
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	return context.WithValue(ctx, RequestIDKey, requestID), requestID
+}
+
+// grpcRequestID returns ctx carrying the call's request ID, reusing the
+// x-request-id metadata sent by the client when present
+func grpcRequestID(ctx context.Context) (context.Context, string) {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	return context.WithValue(ctx, RequestIDKey, requestID), requestID
+}
+
+// RequestIDUnaryServerInterceptor returns a gRPC interceptor that stores the
+// call's request ID in the context under RequestIDKey and sends it back in the
+// x-request-id response header. Incoming x-request-id metadata is reused;
+// otherwise a new ID is generated. Install it before the logging interceptors
+// so their entries include the ID.
+func RequestIDUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, requestID := grpcRequestID(ctx)
+		// Failing to send the header must not fail the call
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadataKey, requestID))
+		return handler(ctx, req)
+	}
+}
+
+// RequestIDStreamServerInterceptor is the stream variant of RequestIDUnaryServerInterceptor
+func RequestIDStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, requestID := grpcRequestID(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(RequestIDMetadataKey, requestID))
+		return handler(srv, &requestIDServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// requestIDServerStream overrides a server stream's context
+type requestIDServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *requestIDServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// WithOutgoingRequestID returns ctx with the request ID stored under
+// RequestIDKey added to the outgoing gRPC metadata, unless the metadata
+// already carries one
+func WithOutgoingRequestID(ctx context.Context) context.Context {
+	requestID, _ := ctx.Value(RequestIDKey).(string)
+	if requestID == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(RequestIDMetadataKey)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, requestID)
+}
+
+// TracePropagationTransport is an http.RoundTripper that forwards the trace and
+// request IDs stored in the request context by HTTPMiddleware as X-Trace-ID and
+// X-Request-ID headers. Headers already set on the request are left untouched.
+type TracePropagationTransport struct {
+	// Base is the underlying transport; http.DefaultTransport is used if nil
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *TracePropagationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx := req.Context()
+	traceID, _ := ctx.Value(TraceIDKey).(TraceID)
+	requestID, _ := ctx.Value(RequestIDKey).(string)
+	setTrace := traceID != "" && req.Header.Get(TraceIDHeader) == ""
+	setRequest := requestID != "" && req.Header.Get(RequestIDHeader) == ""
+	if !setTrace && !setRequest {
+		return base.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(ctx)
+	if setTrace {
+		req.Header.Set(TraceIDHeader, string(traceID))
+	}
+	if setRequest {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,227 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestHTTPMiddlewareReusesTraceID(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := createTestLogger(&buf)
+	require.NoError(t, err)
+
+	var ctxTraceID TraceID
+	handler := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxTraceID, _ = r.Context().Value(TraceIDKey).(TraceID)
+	}))
+
+	t.Run("incoming header", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set(TraceIDHeader, "upstream-trace")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, TraceID("upstream-trace"), ctxTraceID)
+		var startLog map[string]interface{}
+		require.NoError(t, json.NewDecoder(&buf).Decode(&startLog))
+		assert.Equal(t, "upstream-trace", startLog["trace_id"])
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+		assert.NotEmpty(t, ctxTraceID)
+		assert.NotEqual(t, TraceID("upstream-trace"), ctxTraceID)
+	})
+}
+
+func TestTracePropagationTransport(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &TracePropagationTransport{}}
+
+	t.Run("injects ids from context", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), TraceIDKey, TraceID("trace-1"))
+		ctx = context.WithValue(ctx, RequestIDKey, "request-1")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, "trace-1", received.Get(TraceIDHeader))
+		assert.Equal(t, "request-1", received.Get(RequestIDHeader))
+		assert.Empty(t, req.Header.Get(TraceIDHeader), "caller's request must not be modified")
+	})
+
+	t.Run("keeps explicit headers", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), TraceIDKey, TraceID("trace-1"))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set(TraceIDHeader, "explicit")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, "explicit", received.Get(TraceIDHeader))
+	})
+
+	t.Run("no ids in context", func(t *testing.T) {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Empty(t, received.Get(TraceIDHeader))
+		assert.Empty(t, received.Get(RequestIDHeader))
+	})
+
+	t.Run("end to end", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := createTestLogger(&buf)
+		require.NoError(t, err)
+
+		// A downstream service continues the upstream trace
+		var downstreamTrace TraceID
+		downstream := httptest.NewServer(logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			downstreamTrace, _ = r.Context().Value(TraceIDKey).(TraceID)
+		})))
+		defer downstream.Close()
+
+		var upstreamTrace TraceID
+		upstream := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upstreamTrace, _ = r.Context().Value(TraceIDKey).(TraceID)
+			req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, downstream.URL, nil)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+		}))
+		upstream.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.NotEmpty(t, upstreamTrace)
+		assert.Equal(t, upstreamTrace, downstreamTrace)
+	})
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = r.Context().Value(RequestIDKey).(string)
+	}))
+
+	t.Run("reuses incoming ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(RequestIDHeader, "request-1")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, "request-1", seen)
+		assert.Equal(t, "request-1", w.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("generates missing ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.NotEmpty(t, seen)
+		assert.Equal(t, seen, w.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("logging middleware keeps the ID", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := createTestLogger(&buf)
+		require.NoError(t, err)
+
+		var logged string
+		chained := RequestIDMiddleware(logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logged, _ = r.Context().Value(RequestIDKey).(string)
+		})))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(RequestIDHeader, "request-2")
+		w := httptest.NewRecorder()
+		chained.ServeHTTP(w, req)
+
+		assert.Equal(t, "request-2", logged)
+		assert.Equal(t, "request-2", w.Header().Get(RequestIDHeader))
+
+		var logEntry map[string]interface{}
+		require.NoError(t, json.NewDecoder(&buf).Decode(&logEntry))
+		assert.Equal(t, "request-2", logEntry["request_id"])
+	})
+}
+
+// headerServerStream records the header sent on a server stream
+type headerServerStream struct {
+	testServerStream
+	header metadata.MD
+}
+
+func (s *headerServerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestRequestIDServerInterceptors(t *testing.T) {
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDMetadataKey, "request-1"))
+
+	t.Run("unary reuses incoming ID", func(t *testing.T) {
+		var seen string
+		_, err := RequestIDUnaryServerInterceptor()(incoming, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			seen, _ = ctx.Value(RequestIDKey).(string)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "request-1", seen)
+	})
+
+	t.Run("unary generates missing ID", func(t *testing.T) {
+		var seen string
+		_, err := RequestIDUnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			seen, _ = ctx.Value(RequestIDKey).(string)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, seen)
+	})
+
+	t.Run("stream reuses and echoes ID", func(t *testing.T) {
+		stream := &headerServerStream{testServerStream: testServerStream{ctx: incoming}}
+		var seen string
+		err := RequestIDStreamServerInterceptor()(nil, stream, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+			seen, _ = ss.Context().Value(RequestIDKey).(string)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "request-1", seen)
+		assert.Equal(t, []string{"request-1"}, stream.header.Get(RequestIDMetadataKey))
+	})
+}
+
+func TestWithOutgoingRequestID(t *testing.T) {
+	ctx := WithOutgoingRequestID(context.Background())
+	_, ok := metadata.FromOutgoingContext(ctx)
+	assert.False(t, ok, "no request ID means no metadata")
+
+	ctx = WithOutgoingRequestID(context.WithValue(context.Background(), RequestIDKey, "request-1"))
+	md, _ := metadata.FromOutgoingContext(ctx)
+	assert.Equal(t, []string{"request-1"}, md.Get(RequestIDMetadataKey))
+
+	// An explicitly set ID is kept
+	ctx = metadata.AppendToOutgoingContext(context.WithValue(context.Background(), RequestIDKey, "request-1"), RequestIDMetadataKey, "explicit")
+	md, _ = metadata.FromOutgoingContext(WithOutgoingRequestID(ctx))
+	assert.Equal(t, []string{"explicit"}, md.Get(RequestIDMetadataKey))
+}
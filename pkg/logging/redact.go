@@ -0,0 +1,361 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue replaces the value of redacted fields
+const redactedValue = "***"
+
+// redactor masks the values of sensitive keys, matched case-insensitively, at
+// any depth of a field: nested objects, arrays and reflected values included
+type redactor map[string]struct{}
+
+// newRedactor builds a redactor for keys
+func newRedactor(keys []string) redactor {
+	r := make(redactor, len(keys))
+	for _, key := range keys {
+		r[strings.ToLower(key)] = struct{}{}
+	}
+	return r
+}
+
+// sensitive reports whether the value stored under key must be masked
+func (r redactor) sensitive(key string) bool {
+	_, ok := r[strings.ToLower(key)]
+	return ok
+}
+
+// fields returns fields with sensitive values masked, copying only when needed
+func (r redactor) fields(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, field := range fields {
+		masked, changed := r.field(field)
+		if !changed {
+			continue
+		}
+		if redacted == nil {
+			redacted = make([]zapcore.Field, len(fields))
+			copy(redacted, fields)
+		}
+		redacted[i] = masked
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+// field masks a single field and reports whether it had to be replaced.
+// Objects and arrays are wrapped so their nested keys are masked as they are
+// encoded; reflected values are masked up front.
+func (r redactor) field(field zapcore.Field) (zapcore.Field, bool) {
+	if field.Type != zapcore.NamespaceType && r.sensitive(field.Key) {
+		return zap.String(field.Key, redactedValue), true
+	}
+	switch field.Type {
+	case zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType:
+		field.Interface = redactingObject{ObjectMarshaler: field.Interface.(zapcore.ObjectMarshaler), r: r}
+	case zapcore.ArrayMarshalerType:
+		field.Interface = redactingArray{ArrayMarshaler: field.Interface.(zapcore.ArrayMarshaler), r: r}
+	case zapcore.ReflectType:
+		value, changed := r.reflected(field.Interface)
+		if !changed {
+			return field, false
+		}
+		field.Interface = value
+	default:
+		return field, false
+	}
+	return field, true
+}
+
+// reflected masks sensitive keys inside a value the encoder would otherwise
+// serialize by reflection. The value is round-tripped through JSON so struct
+// fields are matched by their encoded names; it is returned unchanged when
+// nothing had to be masked or it cannot be marshaled.
+func (r redactor) reflected(value interface{}) (interface{}, bool) {
+	if value == nil {
+		return value, false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var decoded interface{}
+	if err := dec.Decode(&decoded); err != nil {
+		return value, false
+	}
+	if !r.mask(decoded) {
+		return value, false
+	}
+	return decoded, true
+}
+
+// mask replaces sensitive values in a decoded JSON value in place and reports
+// whether anything was replaced
+func (r redactor) mask(value interface{}) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if r.sensitive(key) {
+				v[key] = redactedValue
+				changed = true
+				continue
+			}
+			if r.mask(nested) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, nested := range v {
+			if r.mask(nested) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// redactingObject encodes an object through a redacting encoder
+type redactingObject struct {
+	zapcore.ObjectMarshaler
+	r redactor
+}
+
+func (o redactingObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.ObjectMarshaler.MarshalLogObject(&redactingObjectEncoder{ObjectEncoder: enc, r: o.r})
+}
+
+// redactingArray encodes an array through a redacting encoder
+type redactingArray struct {
+	zapcore.ArrayMarshaler
+	r redactor
+}
+
+func (a redactingArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.ArrayMarshaler.MarshalLogArray(&redactingArrayEncoder{ArrayEncoder: enc, r: a.r})
+}
+
+// redactingObjectEncoder masks sensitive keys added to the wrapped encoder
+type redactingObjectEncoder struct {
+	zapcore.ObjectEncoder
+	r redactor
+}
+
+func (e *redactingObjectEncoder) mask(key string) bool {
+	if e.r.sensitive(key) {
+		e.ObjectEncoder.AddString(key, redactedValue)
+		return true
+	}
+	return false
+}
+
+func (e *redactingObjectEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	if e.mask(key) {
+		return nil
+	}
+	return e.ObjectEncoder.AddArray(key, redactingArray{ArrayMarshaler: arr, r: e.r})
+}
+
+func (e *redactingObjectEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	if e.mask(key) {
+		return nil
+	}
+	return e.ObjectEncoder.AddObject(key, redactingObject{ObjectMarshaler: obj, r: e.r})
+}
+
+func (e *redactingObjectEncoder) AddReflected(key string, value interface{}) error {
+	if e.mask(key) {
+		return nil
+	}
+	value, _ = e.r.reflected(value)
+	return e.ObjectEncoder.AddReflected(key, value)
+}
+
+func (e *redactingObjectEncoder) AddBinary(key string, value []byte) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddBinary(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddByteString(key string, value []byte) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddByteString(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddBool(key string, value bool) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddBool(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddComplex128(key string, value complex128) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddComplex128(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddComplex64(key string, value complex64) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddComplex64(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddDuration(key string, value time.Duration) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddDuration(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddFloat64(key string, value float64) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddFloat64(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddFloat32(key string, value float32) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddFloat32(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddInt(key string, value int) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddInt(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddInt64(key string, value int64) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddInt64(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddInt32(key string, value int32) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddInt32(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddInt16(key string, value int16) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddInt16(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddInt8(key string, value int8) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddInt8(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddString(key, value string) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddString(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddTime(key string, value time.Time) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddTime(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddUint(key string, value uint) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddUint(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddUint64(key string, value uint64) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddUint64(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddUint32(key string, value uint32) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddUint32(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddUint16(key string, value uint16) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddUint16(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddUint8(key string, value uint8) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddUint8(key, value)
+	}
+}
+
+func (e *redactingObjectEncoder) AddUintptr(key string, value uintptr) {
+	if !e.mask(key) {
+		e.ObjectEncoder.AddUintptr(key, value)
+	}
+}
+
+// redactingArrayEncoder masks sensitive keys inside elements appended to the
+// wrapped encoder
+type redactingArrayEncoder struct {
+	zapcore.ArrayEncoder
+	r redactor
+}
+
+func (e *redactingArrayEncoder) AppendArray(arr zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(redactingArray{ArrayMarshaler: arr, r: e.r})
+}
+
+func (e *redactingArrayEncoder) AppendObject(obj zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(redactingObject{ObjectMarshaler: obj, r: e.r})
+}
+
+func (e *redactingArrayEncoder) AppendReflected(value interface{}) error {
+	value, _ = e.r.reflected(value)
+	return e.ArrayEncoder.AppendReflected(value)
+}
+
+// redactingEncoder is a zapcore.Encoder that masks the values of sensitive
+// keys wherever they appear, including keys of nested objects and of values
+// logged by reflection such as structs passed to zap.Any
+type redactingEncoder struct {
+	redactingObjectEncoder
+	enc zapcore.Encoder
+}
+
+// NewRedactingEncoder wraps enc so that the values of keys matching one of
+// keys, case-insensitively, are masked at any depth
+func NewRedactingEncoder(enc zapcore.Encoder, keys ...string) zapcore.Encoder {
+	return newRedactingEncoder(enc, newRedactor(keys))
+}
+
+func newRedactingEncoder(enc zapcore.Encoder, r redactor) *redactingEncoder {
+	return &redactingEncoder{
+		redactingObjectEncoder: redactingObjectEncoder{ObjectEncoder: enc, r: r},
+		enc:                    enc,
+	}
+}
+
+// Clone copies the encoder, keeping redaction
+func (e *redactingEncoder) Clone() zapcore.Encoder {
+	return newRedactingEncoder(e.enc.Clone(), e.r)
+}
+
+// EncodeEntry masks sensitive fields and encodes the entry with the wrapped encoder
+func (e *redactingEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	return e.enc.EncodeEntry(ent, e.r.fields(fields))
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type testCredentials struct {
+	User     string
+	Password string
+}
+
+type testAccount struct {
+	Name        string
+	Credentials testCredentials
+}
+
+func (a testAccount) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", a.Name)
+	return enc.AddObject("credentials", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("user", a.Credentials.User)
+		enc.AddString("password", a.Credentials.Password)
+		return nil
+	}))
+}
+
+type testAccounts []testAccount
+
+func (a testAccounts) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, account := range a {
+		if err := enc.AppendObject(account); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assertNestedRedaction logs sensitive values nested in every supported way
+func assertNestedRedaction(t *testing.T, logger *Logger, buf *bytes.Buffer) {
+	t.Helper()
+	account := testAccount{Name: "alice", Credentials: testCredentials{User: "alice", Password: "hunter2"}}
+
+	logger.With(zap.Any("context", account.Credentials)).Info("nested",
+		zap.Any("reflected", account.Credentials),
+		zap.Any("reflected_map", map[string]interface{}{"inner": map[string]string{"token": "abc123"}}),
+		zap.Object("object", account),
+		zap.Array("array", testAccounts{account}),
+		zap.Inline(account),
+	)
+
+	out := buf.String()
+	assert.Contains(t, out, "alice")
+	assert.Contains(t, out, "***")
+	assert.NotContains(t, out, "hunter2")
+	assert.NotContains(t, out, "abc123")
+}
+
+func TestNestedRedaction(t *testing.T) {
+	keys := []string{"password", "token"}
+
+	for _, encoding := range []string{"json", "console"} {
+		t.Run(encoding, func(t *testing.T) {
+			var buf bytes.Buffer
+			cfg := DefaultAdvancedConfig()
+			cfg.Encoding = encoding
+			cfg.RedactKeys = keys
+			logger, err := newAdvanced(cfg, zapcore.AddSync(&buf))
+			require.NoError(t, err)
+
+			assertNestedRedaction(t, logger, &buf)
+		})
+	}
+
+	t.Run("with redaction", func(t *testing.T) {
+		var buf bytes.Buffer
+		plain, err := createTestLogger(&buf)
+		require.NoError(t, err)
+
+		assertNestedRedaction(t, plain.WithRedaction(keys...), &buf)
+	})
+}
+
+func TestRedactingEncoderReflected(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := NewRedactingEncoder(zapcore.NewJSONEncoder(defaultEncoderConfig()), "Password")
+	logger := zap.New(zapcore.NewCore(encoder, zapcore.AddSync(&buf), zapcore.DebugLevel))
+
+	logger.Info("login", zap.Any("credentials", testCredentials{User: "alice", Password: "hunter2"}))
+
+	var entry struct {
+		Credentials testCredentials `json:"credentials"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, testCredentials{User: "alice", Password: "***"}, entry.Credentials)
+
+	// Values without sensitive keys are encoded unchanged
+	buf.Reset()
+	logger.Info("count", zap.Any("ids", []int{1, 2, 3}))
+	assert.Contains(t, buf.String(), `"ids":[1,2,3]`)
+}
@@ -13,10 +13,11 @@ import (
 
 // ServiceHealth represents service health metrics
 type ServiceHealth struct {
-	reporter    *Reporter
-	status      *prometheus.GaugeVec
-	uptime      prometheus.Gauge
-	lastChecked prometheus.Gauge
+	reporter     *Reporter
+	status       *prometheus.GaugeVec
+	uptime       prometheus.Gauge
+	lastChecked  prometheus.Gauge
+	dependencyUp *prometheus.GaugeVec
 }
 
 // ResourceMetrics represents system resource metrics
@@ -26,6 +27,30 @@ type ResourceMetrics struct {
 	memoryUsage  *prometheus.GaugeVec
 	goroutines   prometheus.Gauge
 	allocatedMem prometheus.Gauge
+	gcDuration   *prometheus.SummaryVec
+	heapSize     *prometheus.GaugeVec
+	lastNumGC    uint32
+	cgroup       *cgroupMetrics
+}
+
+// ResourceConfig configures resource metrics collection
+type ResourceConfig struct {
+	// UseCgroup reports memory and CPU relative to the container's cgroup
+	// limits, falling back to host metrics when no cgroup is found
+	UseCgroup bool
+	// CgroupRoot is the cgroup filesystem mount; defaults to DefaultCgroupRoot
+	CgroupRoot string
+}
+
+// cgroupMetrics holds the container-specific resource metrics
+type cgroupMetrics struct {
+	root             string
+	cpuLimit         prometheus.Gauge
+	cpuQuotaUsage    prometheus.Gauge
+	throttledRatio   prometheus.Gauge
+	throttledSeconds prometheus.Gauge
+	lastUsage        time.Duration
+	lastSample       time.Time
 }
 
 // NewServiceHealth creates a new service health metrics collector
@@ -49,10 +74,11 @@ func NewServiceHealth(r *Reporter) *ServiceHealth {
 	)
 
 	return &ServiceHealth{
-		reporter:    r,
-		status:      status,
-		uptime:      uptimeVec.WithLabelValues(),
-		lastChecked: lastCheckedVec.WithLabelValues(),
+		reporter:     r,
+		status:       status,
+		uptime:       uptimeVec.WithLabelValues(),
+		lastChecked:  lastCheckedVec.WithLabelValues(),
+		dependencyUp: newDependencyUpGauge(r),
 	}
 }
 
@@ -66,13 +92,27 @@ func (h *ServiceHealth) SetHealth(service, instance string, healthy bool) {
 	h.lastChecked.Set(float64(time.Now().Unix()))
 }
 
+// SetDependencyHealth updates the reachability of a named dependency
+func (h *ServiceHealth) SetDependencyHealth(name string, up bool) {
+	value := 0.0
+	if up {
+		value = 1.0
+	}
+	h.dependencyUp.WithLabelValues(name).Set(value)
+}
+
 // UpdateUptime updates the service uptime
 func (h *ServiceHealth) UpdateUptime(startTime time.Time) {
 	h.uptime.Set(time.Since(startTime).Seconds())
 }
 
-// NewResourceMetrics creates a new resource metrics collector
+// NewResourceMetrics creates a new resource metrics collector using host metrics
 func NewResourceMetrics(r *Reporter) *ResourceMetrics {
+	return NewResourceMetricsWithConfig(r, ResourceConfig{})
+}
+
+// NewResourceMetricsWithConfig creates a new resource metrics collector
+func NewResourceMetricsWithConfig(r *Reporter, cfg ResourceConfig) *ResourceMetrics {
 	cpuUsage := r.Gauge(
 		"system_cpu_usage",
 		"CPU usage percentage per core",
@@ -97,13 +137,110 @@ func NewResourceMetrics(r *Reporter) *ResourceMetrics {
 		[]string{},
 	)
 
-	return &ResourceMetrics{
+	gcDuration := r.SummaryWithOpts(
+		MetricGCDurationSeconds,
+		"Garbage collection pause durations in seconds",
+		[]string{},
+		nil,
+		0,
+	)
+
+	heapSize := r.Gauge(
+		MetricHeapSizeBytes,
+		"Heap memory statistics in bytes",
+		[]string{"type"},
+	)
+
+	rm := &ResourceMetrics{
 		reporter:     r,
 		cpuUsage:     cpuUsage,
 		memoryUsage:  memoryUsage,
 		goroutines:   goroutinesVec.WithLabelValues(),
 		allocatedMem: allocatedMemVec.WithLabelValues(),
+		gcDuration:   gcDuration,
+		heapSize:     heapSize,
+	}
+
+	if cfg.UseCgroup {
+		if cfg.CgroupRoot == "" {
+			cfg.CgroupRoot = DefaultCgroupRoot
+		}
+		rm.cgroup = newCgroupMetrics(r, cfg.CgroupRoot)
+	}
+
+	return rm
+}
+
+// newCgroupMetrics registers the container CPU metrics
+func newCgroupMetrics(r *Reporter, root string) *cgroupMetrics {
+	cpuLimit := r.Gauge(
+		"system_cpu_limit_cores",
+		"CPU quota of the container in cores (0: unlimited)",
+		[]string{},
+	)
+
+	cpuQuotaUsage := r.Gauge(
+		"system_cpu_quota_usage_percent",
+		"CPU usage as a percentage of the container's quota",
+		[]string{},
+	)
+
+	throttledRatio := r.Gauge(
+		"system_cpu_throttled_ratio",
+		"Fraction of CPU enforcement periods in which the container was throttled",
+		[]string{},
+	)
+
+	throttledSeconds := r.Gauge(
+		"system_cpu_throttled_seconds",
+		"Cumulative time the container was throttled in seconds",
+		[]string{},
+	)
+
+	return &cgroupMetrics{
+		root:             root,
+		cpuLimit:         cpuLimit.WithLabelValues(),
+		cpuQuotaUsage:    cpuQuotaUsage.WithLabelValues(),
+		throttledRatio:   throttledRatio.WithLabelValues(),
+		throttledSeconds: throttledSeconds.WithLabelValues(),
+	}
+}
+
+// collect overrides host memory usage with the container's and updates the CPU
+// quota metrics. Host metrics are left untouched if no cgroup can be read.
+func (c *cgroupMetrics) collect(memoryUsage *prometheus.GaugeVec) {
+	stats, err := readCgroupStats(c.root)
+	if err != nil {
+		return
 	}
+
+	if stats.MemoryLimit > 0 {
+		memoryUsage.WithLabelValues("limit").Set(float64(stats.MemoryLimit))
+		memoryUsage.WithLabelValues("used").Set(float64(stats.MemoryUsage))
+		free := 0.0
+		if stats.MemoryLimit > stats.MemoryUsage {
+			free = float64(stats.MemoryLimit - stats.MemoryUsage)
+		}
+		memoryUsage.WithLabelValues("free").Set(free)
+	}
+
+	c.cpuLimit.Set(stats.CPULimit)
+	c.throttledSeconds.Set(stats.ThrottledTime.Seconds())
+	if stats.Periods > 0 {
+		c.throttledRatio.Set(float64(stats.ThrottledPeriods) / float64(stats.Periods))
+	}
+
+	// Usage relative to quota needs two samples to compute a rate
+	now := time.Now()
+	if !c.lastSample.IsZero() && stats.CPULimit > 0 {
+		elapsed := now.Sub(c.lastSample)
+		used := stats.CPUUsage - c.lastUsage
+		if elapsed > 0 && used >= 0 {
+			c.cpuQuotaUsage.Set(used.Seconds() / elapsed.Seconds() / stats.CPULimit * 100)
+		}
+	}
+	c.lastUsage = stats.CPUUsage
+	c.lastSample = now
 }
 
 // CollectMetrics gathers all resource metrics
@@ -124,15 +261,38 @@ func (rm *ResourceMetrics) CollectMetrics(ctx context.Context) error {
 		rm.memoryUsage.WithLabelValues("cached").Set(float64(vmStat.Cached))
 	}
 
+	// Prefer the container's limits when running in a cgroup
+	if rm.cgroup != nil {
+		rm.cgroup.collect(rm.memoryUsage)
+	}
+
 	// Collect Go runtime metrics
 	rm.goroutines.Set(float64(runtime.NumGoroutine()))
 	var memStats runtime.MemStats
 	runtime.ReadMemStats(&memStats)
 	rm.allocatedMem.Set(float64(memStats.Alloc))
+	rm.heapSize.WithLabelValues("alloc").Set(float64(memStats.HeapAlloc))
+	rm.heapSize.WithLabelValues("sys").Set(float64(memStats.HeapSys))
+	rm.observeGCPauses(&memStats)
 
 	return nil
 }
 
+// observeGCPauses records the pauses of collections completed since the last call.
+// PauseNs is a circular buffer, so at most its length of recent pauses are available.
+func (rm *ResourceMetrics) observeGCPauses(memStats *runtime.MemStats) {
+	bufLen := uint32(len(memStats.PauseNs))
+	first := rm.lastNumGC + 1
+	if memStats.NumGC >= bufLen && first <= memStats.NumGC-bufLen {
+		first = memStats.NumGC - bufLen + 1
+	}
+	for n := first; n <= memStats.NumGC; n++ {
+		pause := time.Duration(memStats.PauseNs[(n+bufLen-1)%bufLen])
+		rm.gcDuration.WithLabelValues().Observe(pause.Seconds())
+	}
+	rm.lastNumGC = memStats.NumGC
+}
+
 // CustomCollector represents a custom metrics collector
 type CustomCollector struct {
 	metrics []prometheus.Collector
@@ -173,6 +333,7 @@ func (h *ServiceHealth) Describe(ch chan<- *prometheus.Desc) {
 	h.status.Describe(ch)
 	ch <- h.uptime.Desc()
 	ch <- h.lastChecked.Desc()
+	h.dependencyUp.Describe(ch)
 }
 
 // Collect implements prometheus.Collector
@@ -180,6 +341,7 @@ func (h *ServiceHealth) Collect(ch chan<- prometheus.Metric) {
 	h.status.Collect(ch)
 	ch <- h.uptime
 	ch <- h.lastChecked
+	h.dependencyUp.Collect(ch)
 }
 
 // Register registers the collector with a registry
@@ -193,6 +355,8 @@ func (rm *ResourceMetrics) Describe(ch chan<- *prometheus.Desc) {
 	rm.memoryUsage.Describe(ch)
 	ch <- rm.goroutines.Desc()
 	ch <- rm.allocatedMem.Desc()
+	rm.gcDuration.Describe(ch)
+	rm.heapSize.Describe(ch)
 }
 
 // Collect implements prometheus.Collector
@@ -201,6 +365,8 @@ func (rm *ResourceMetrics) Collect(ch chan<- prometheus.Metric) {
 	rm.memoryUsage.Collect(ch)
 	ch <- rm.goroutines
 	ch <- rm.allocatedMem
+	rm.gcDuration.Collect(ch)
+	rm.heapSize.Collect(ch)
 }
 
 // Register registers the collector with a registry
@@ -2,10 +2,12 @@ package metrics
 
 import (
 	"context"
+	"runtime"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -67,6 +69,46 @@ func TestServiceHealth(t *testing.T) {
 	assert.True(t, found["last_checked"], "last checked metric not found")
 }
 
+func TestServiceHealthDependencies(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reporter := New(Options{
+		Namespace: "test",
+		Registry:  registry,
+	})
+
+	health := NewServiceHealth(reporter)
+	health.SetDependencyHealth("db", true)
+	health.SetDependencyHealth("cache", false)
+
+	assert.Equal(t, 1.0, metricValue(t, health.dependencyUp.WithLabelValues("db")))
+	assert.Equal(t, 0.0, metricValue(t, health.dependencyUp.WithLabelValues("cache")))
+
+	// The gauge is shared with DependencyHealth on the same reporter
+	deps := NewDependencyHealth(reporter)
+	assert.Same(t, health.dependencyUp, deps.up)
+
+	// ServiceHealth exposes the dependency gauge as a collector
+	own := prometheus.NewRegistry()
+	require.NoError(t, health.Register(own))
+	metrics, err := own.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, m := range metrics {
+		if m.GetName() != "test_service_dependency_up" {
+			continue
+		}
+		for _, metric := range m.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "dependency" {
+					values[label.GetValue()] = metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"db": 1, "cache": 0}, values)
+}
+
 func TestResourceMetrics(t *testing.T) {
 	// Create a new registry for this test
 	registry := prometheus.NewRegistry()
@@ -171,3 +213,59 @@ func TestCustomCollector(t *testing.T) {
 	}
 	assert.True(t, found, "custom metric not found")
 }
+
+func TestResourceMetricsGCAndHeap(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reporter := New(Options{
+		Namespace: "test",
+		Registry:  registry,
+	})
+
+	resources := NewResourceMetrics(reporter)
+	runtime.GC()
+	require.NoError(t, resources.CollectMetrics(context.Background()))
+
+	metrics, err := registry.Gather()
+	require.NoError(t, err)
+
+	found := make(map[string]bool)
+	for _, m := range metrics {
+		switch m.GetName() {
+		case "test_gc_duration_seconds":
+			found["gc"] = true
+			require.Len(t, m.GetMetric(), 1)
+			assert.GreaterOrEqual(t, m.GetMetric()[0].GetSummary().GetSampleCount(), uint64(1))
+		case "test_heap_size_bytes":
+			found["heap"] = true
+			heapTypes := make(map[string]float64)
+			for _, metric := range m.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "type" {
+						heapTypes[label.GetValue()] = metric.GetGauge().GetValue()
+					}
+				}
+			}
+			assert.Greater(t, heapTypes["alloc"], 0.0)
+			assert.Greater(t, heapTypes["sys"], 0.0)
+		}
+	}
+
+	assert.True(t, found["gc"], "GC duration metrics not found")
+	assert.True(t, found["heap"], "heap size metrics not found")
+
+	// A second collection only observes collections completed since the first
+	sampleCount := func() uint64 {
+		var m dto.Metric
+		require.NoError(t, resources.gcDuration.WithLabelValues().(prometheus.Metric).Write(&m))
+		return m.GetSummary().GetSampleCount()
+	}
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	before := sampleCount()
+	runtime.GC()
+	require.NoError(t, resources.CollectMetrics(context.Background()))
+	after := sampleCount()
+	assert.GreaterOrEqual(t, after-before, uint64(1))
+	assert.LessOrEqual(t, after, uint64(resources.lastNumGC))
+	assert.Greater(t, resources.lastNumGC, stats.NumGC)
+}
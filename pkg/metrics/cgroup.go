@@ -0,0 +1,193 @@
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCgroupRoot is where the cgroup filesystem is mounted in a container
+const DefaultCgroupRoot = "/sys/fs/cgroup"
+
+// cgroupV1Unlimited is the threshold above which a cgroup v1 memory limit means unlimited
+const cgroupV1Unlimited = 1 << 60
+
+// errNoCgroup is returned when no cgroup hierarchy is found
+var errNoCgroup = errors.New("no cgroup hierarchy found")
+
+// cgroupStats holds resource limits and usage read from the cgroup filesystem
+type cgroupStats struct {
+	// MemoryUsage is the current memory usage in bytes
+	MemoryUsage uint64
+	// MemoryLimit is the memory limit in bytes, or 0 if unlimited
+	MemoryLimit uint64
+	// CPULimit is the CPU quota in cores, or 0 if unlimited
+	CPULimit float64
+	// CPUUsage is the cumulative CPU time consumed
+	CPUUsage time.Duration
+	// Periods is the number of elapsed enforcement periods
+	Periods uint64
+	// ThrottledPeriods is the number of periods in which the cgroup was throttled
+	ThrottledPeriods uint64
+	// ThrottledTime is the cumulative time the cgroup was throttled
+	ThrottledTime time.Duration
+}
+
+// readCgroupStats reads limits and usage from a cgroup v2 or v1 hierarchy at root
+func readCgroupStats(root string) (*cgroupStats, error) {
+	if fileExists(filepath.Join(root, "cgroup.controllers")) {
+		return readCgroupV2(root)
+	}
+	if fileExists(filepath.Join(root, "memory", "memory.limit_in_bytes")) {
+		return readCgroupV1(root)
+	}
+	return nil, errNoCgroup
+}
+
+// readCgroupV2 reads stats from the unified cgroup v2 hierarchy
+func readCgroupV2(root string) (*cgroupStats, error) {
+	stats := &cgroupStats{}
+	var err error
+
+	if stats.MemoryUsage, err = readCgroupUint(filepath.Join(root, "memory.current")); err != nil {
+		return nil, err
+	}
+	if stats.MemoryLimit, err = readCgroupUint(filepath.Join(root, "memory.max")); err != nil {
+		return nil, err
+	}
+
+	// cpu.max holds "<quota> <period>", with a quota of "max" when unlimited
+	cpuMax, err := readCgroupFile(filepath.Join(root, "cpu.max"))
+	if err != nil {
+		return nil, err
+	}
+	if fields := strings.Fields(cpuMax); len(fields) == 2 && fields[0] != "max" {
+		quota, qerr := strconv.ParseFloat(fields[0], 64)
+		period, perr := strconv.ParseFloat(fields[1], 64)
+		if qerr != nil || perr != nil || period == 0 {
+			return nil, fmt.Errorf("invalid cpu.max %q", cpuMax)
+		}
+		stats.CPULimit = quota / period
+	}
+
+	cpuStat, err := readCgroupKeyValues(filepath.Join(root, "cpu.stat"))
+	if err != nil {
+		return nil, err
+	}
+	stats.CPUUsage = time.Duration(cpuStat["usage_usec"]) * time.Microsecond
+	stats.Periods = cpuStat["nr_periods"]
+	stats.ThrottledPeriods = cpuStat["nr_throttled"]
+	stats.ThrottledTime = time.Duration(cpuStat["throttled_usec"]) * time.Microsecond
+
+	return stats, nil
+}
+
+// readCgroupV1 reads stats from the per-controller cgroup v1 hierarchies
+func readCgroupV1(root string) (*cgroupStats, error) {
+	stats := &cgroupStats{}
+	var err error
+
+	if stats.MemoryUsage, err = readCgroupUint(filepath.Join(root, "memory", "memory.usage_in_bytes")); err != nil {
+		return nil, err
+	}
+	if stats.MemoryLimit, err = readCgroupUint(filepath.Join(root, "memory", "memory.limit_in_bytes")); err != nil {
+		return nil, err
+	}
+	if stats.MemoryLimit >= cgroupV1Unlimited {
+		stats.MemoryLimit = 0
+	}
+
+	// A quota of -1 means unlimited
+	quota, err := readCgroupFile(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return nil, err
+	}
+	if quota != "-1" {
+		period, err := readCgroupUint(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+		if err != nil {
+			return nil, err
+		}
+		q, err := strconv.ParseFloat(quota, 64)
+		if err != nil || period == 0 {
+			return nil, fmt.Errorf("invalid cpu.cfs_quota_us %q", quota)
+		}
+		stats.CPULimit = q / float64(period)
+	}
+
+	cpuStat, err := readCgroupKeyValues(filepath.Join(root, "cpu", "cpu.stat"))
+	if err != nil {
+		return nil, err
+	}
+	stats.Periods = cpuStat["nr_periods"]
+	stats.ThrottledPeriods = cpuStat["nr_throttled"]
+	stats.ThrottledTime = time.Duration(cpuStat["throttled_time"])
+
+	usage, err := readCgroupUint(filepath.Join(root, "cpuacct", "cpuacct.usage"))
+	if err != nil {
+		return nil, err
+	}
+	stats.CPUUsage = time.Duration(usage)
+
+	return stats, nil
+}
+
+// readCgroupFile returns the trimmed contents of a cgroup file
+func readCgroupFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read cgroup file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readCgroupUint reads a single unsigned value, treating "max" as 0 (unlimited)
+func readCgroupUint(path string) (uint64, error) {
+	value, err := readCgroupFile(path)
+	if err != nil {
+		return 0, err
+	}
+	if value == "max" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value in %s: %w", path, err)
+	}
+	return n, nil
+}
+
+// readCgroupKeyValues reads a flat keyed file such as cpu.stat
+func readCgroupKeyValues(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cgroup file: %w", err)
+	}
+	defer f.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if n, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = n
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cgroup file: %w", err)
+	}
+	return values, nil
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCgroupFiles creates a fake cgroup hierarchy under root
+func writeCgroupFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestReadCgroupStats(t *testing.T) {
+	t.Run("v2", func(t *testing.T) {
+		root := t.TempDir()
+		writeCgroupFiles(t, root, map[string]string{
+			"cgroup.controllers": "cpu memory\n",
+			"memory.current":     "104857600\n",
+			"memory.max":         "536870912\n",
+			"cpu.max":            "50000 100000\n",
+			"cpu.stat":           "usage_usec 2000000\nnr_periods 100\nnr_throttled 25\nthrottled_usec 1500000\n",
+		})
+
+		stats, err := readCgroupStats(root)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(104857600), stats.MemoryUsage)
+		assert.Equal(t, uint64(536870912), stats.MemoryLimit)
+		assert.Equal(t, 0.5, stats.CPULimit)
+		assert.Equal(t, 2*time.Second, stats.CPUUsage)
+		assert.Equal(t, uint64(25), stats.ThrottledPeriods)
+		assert.Equal(t, 1500*time.Millisecond, stats.ThrottledTime)
+	})
+
+	t.Run("v2 unlimited", func(t *testing.T) {
+		root := t.TempDir()
+		writeCgroupFiles(t, root, map[string]string{
+			"cgroup.controllers": "cpu memory\n",
+			"memory.current":     "1024\n",
+			"memory.max":         "max\n",
+			"cpu.max":            "max 100000\n",
+			"cpu.stat":           "usage_usec 10\n",
+		})
+
+		stats, err := readCgroupStats(root)
+		require.NoError(t, err)
+		assert.Zero(t, stats.MemoryLimit)
+		assert.Zero(t, stats.CPULimit)
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		root := t.TempDir()
+		writeCgroupFiles(t, root, map[string]string{
+			"memory/memory.usage_in_bytes": "2048\n",
+			"memory/memory.limit_in_bytes": "9223372036854771712\n",
+			"cpu/cpu.cfs_quota_us":         "200000\n",
+			"cpu/cpu.cfs_period_us":        "100000\n",
+			"cpu/cpu.stat":                 "nr_periods 10\nnr_throttled 1\nthrottled_time 5000000\n",
+			"cpuacct/cpuacct.usage":        "3000000000\n",
+		})
+
+		stats, err := readCgroupStats(root)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2048), stats.MemoryUsage)
+		assert.Zero(t, stats.MemoryLimit, "huge v1 limits mean unlimited")
+		assert.Equal(t, 2.0, stats.CPULimit)
+		assert.Equal(t, 3*time.Second, stats.CPUUsage)
+		assert.Equal(t, 5*time.Millisecond, stats.ThrottledTime)
+	})
+
+	t.Run("no cgroup", func(t *testing.T) {
+		_, err := readCgroupStats(t.TempDir())
+		assert.ErrorIs(t, err, errNoCgroup)
+	})
+}
+
+func TestResourceMetricsCgroup(t *testing.T) {
+	root := t.TempDir()
+	writeCgroupFiles(t, root, map[string]string{
+		"cgroup.controllers": "cpu memory\n",
+		"memory.current":     "100\n",
+		"memory.max":         "400\n",
+		"cpu.max":            "100000 100000\n",
+		"cpu.stat":           "usage_usec 0\nnr_periods 4\nnr_throttled 1\nthrottled_usec 0\n",
+	})
+
+	registry := prometheus.NewRegistry()
+	reporter := New(Options{
+		Namespace: "test",
+		Registry:  registry,
+	})
+	resources := NewResourceMetricsWithConfig(reporter, ResourceConfig{UseCgroup: true, CgroupRoot: root})
+	require.NoError(t, resources.CollectMetrics(context.Background()))
+
+	gauges := make(map[string]float64)
+	metrics, err := registry.Gather()
+	require.NoError(t, err)
+	for _, m := range metrics {
+		for _, metric := range m.GetMetric() {
+			name := m.GetName()
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "type" {
+					name += "/" + label.GetValue()
+				}
+			}
+			gauges[name] = metric.GetGauge().GetValue()
+		}
+	}
+
+	assert.Equal(t, 400.0, gauges["test_system_memory_usage/limit"])
+	assert.Equal(t, 100.0, gauges["test_system_memory_usage/used"])
+	assert.Equal(t, 300.0, gauges["test_system_memory_usage/free"])
+	assert.Equal(t, 1.0, gauges["test_system_cpu_limit_cores"])
+	assert.Equal(t, 0.25, gauges["test_system_cpu_throttled_ratio"])
+
+	t.Run("falls back to host metrics", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		reporter := New(Options{Registry: registry})
+		resources := NewResourceMetricsWithConfig(reporter, ResourceConfig{UseCgroup: true, CgroupRoot: t.TempDir()})
+		require.NoError(t, resources.CollectMetrics(context.Background()))
+
+		metrics, err := registry.Gather()
+		require.NoError(t, err)
+		for _, m := range metrics {
+			if m.GetName() != "system_memory_usage" {
+				continue
+			}
+			for _, metric := range m.GetMetric() {
+				assert.NotEqual(t, "limit", metric.GetLabel()[0].GetValue())
+			}
+		}
+	})
+}
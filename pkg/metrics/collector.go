@@ -22,6 +22,8 @@ type CollectorConfig struct {
 	CollectionInterval time.Duration
 	// Labels are the default labels to be added to all metrics
 	Labels map[string]string
+	// UseCgroupLimits reports resource metrics relative to the container's cgroup limits
+	UseCgroupLimits bool
 }
 
 // DefaultCollectorConfig returns the default collector configuration
@@ -44,6 +46,7 @@ type MetricsCollector struct {
 	reporter  *Reporter
 	health    *ServiceHealth
 	resources *ResourceMetrics
+	deps      *DependencyHealth
 	custom    []*CustomCollector
 	server    *http.Server
 	mu        sync.RWMutex
@@ -62,7 +65,8 @@ func NewCollector(config CollectorConfig) (*MetricsCollector, error) {
 		registry:  registry,
 		reporter:  reporter,
 		health:    NewServiceHealth(reporter),
-		resources: NewResourceMetrics(reporter),
+		resources: NewResourceMetricsWithConfig(reporter, ResourceConfig{UseCgroup: config.UseCgroupLimits}),
+		deps:      NewDependencyHealth(reporter),
 		custom:    make([]*CustomCollector, 0),
 	}
 
@@ -135,6 +139,8 @@ func (c *MetricsCollector) collect(ctx context.Context) {
 				fmt.Printf("Error collecting resource metrics: %v\n", err)
 			}
 
+			c.deps.CheckAll(ctx)
+
 			c.mu.RLock()
 			for _, collector := range c.custom {
 				if err := collector.collect(ctx); err != nil {
@@ -165,3 +171,8 @@ func (c *MetricsCollector) GetHealth() *ServiceHealth {
 func (c *MetricsCollector) GetResources() *ResourceMetrics {
 	return c.resources
 }
+
+// GetDependencies returns the dependency health metrics
+func (c *MetricsCollector) GetDependencies() *DependencyHealth {
+	return c.deps
+}
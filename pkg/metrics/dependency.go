@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dependencyCheckTimeout bounds a single dependency check
+const dependencyCheckTimeout = 5 * time.Second
+
+// DependencyCheck reports whether a dependency is reachable
+type DependencyCheck func(ctx context.Context) error
+
+// DependencyHealth tracks the reachability of downstream dependencies
+type DependencyHealth struct {
+	reporter *Reporter
+	up       *prometheus.GaugeVec
+	checks   map[string]DependencyCheck
+	mu       sync.RWMutex
+}
+
+// NewDependencyHealth creates a new dependency health collector
+func NewDependencyHealth(r *Reporter) *DependencyHealth {
+	return &DependencyHealth{
+		reporter: r,
+		up:       newDependencyUpGauge(r),
+		checks:   make(map[string]DependencyCheck),
+	}
+}
+
+// newDependencyUpGauge registers the dependency up gauge, reusing it if
+// DependencyHealth or ServiceHealth already registered it on this reporter
+func newDependencyUpGauge(r *Reporter) *prometheus.GaugeVec {
+	return registerOrExisting(r, prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: r.namespace,
+			Subsystem: r.subsystem,
+			Name:      MetricServiceDependencyUp,
+			Help:      "Whether a dependency is reachable (0: down, 1: up)",
+		},
+		[]string{"dependency"},
+	))
+}
+
+// Register adds a dependency check, replacing any with the same name
+func (d *DependencyHealth) Register(name string, check DependencyCheck) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.checks[name] = check
+}
+
+// CheckAll runs every registered check concurrently and updates the up gauge
+func (d *DependencyHealth) CheckAll(ctx context.Context) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for name, check := range d.checks {
+		wg.Add(1)
+		go func(name string, check DependencyCheck) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+			defer cancel()
+
+			value := 1.0
+			if err := check(checkCtx); err != nil {
+				value = 0.0
+			}
+			d.up.WithLabelValues(name).Set(value)
+		}(name, check)
+	}
+	wg.Wait()
+}
+
+// Run checks all dependencies immediately and then every interval until ctx is done
+func (d *DependencyHealth) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	d.CheckAll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.CheckAll(ctx)
+		}
+	}
+}
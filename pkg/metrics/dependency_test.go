@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dependencyValues returns the service_dependency_up value for each dependency
+func dependencyValues(t *testing.T, registry prometheus.Gatherer, name string) map[string]float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "dependency" {
+					values[label.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return values
+}
+
+func TestDependencyHealth(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reporter := New(Options{
+		Namespace: "test",
+		Registry:  registry,
+	})
+
+	deps := NewDependencyHealth(reporter)
+	deps.Register("database", func(ctx context.Context) error { return nil })
+	deps.Register("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+	deps.Register("slow", func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok, "checks should run with a timeout")
+		return nil
+	})
+
+	deps.CheckAll(context.Background())
+
+	values := dependencyValues(t, registry, "test_service_dependency_up")
+	assert.Equal(t, map[string]float64{"database": 1, "redis": 0, "slow": 1}, values)
+
+	// A recovered dependency is reported up on the next check
+	deps.Register("redis", func(ctx context.Context) error { return nil })
+	deps.CheckAll(context.Background())
+	assert.Equal(t, 1.0, dependencyValues(t, registry, "test_service_dependency_up")["redis"])
+}
+
+func TestCollectorDependencies(t *testing.T) {
+	collector, err := NewCollector(CollectorConfig{
+		ListenAddress:      "127.0.0.1:0",
+		Path:               "/metrics",
+		CollectionInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	collector.GetDependencies().Register("grpc-upstream", func(ctx context.Context) error { return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, collector.Start(ctx))
+	defer collector.Stop(context.Background())
+
+	assert.Eventually(t, func() bool {
+		return dependencyValues(t, collector.GetRegistry(), "service_dependency_up")["grpc-upstream"] == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
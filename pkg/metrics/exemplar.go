@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ExemplarTraceIDLabel is the exemplar label holding the trace ID
+const ExemplarTraceIDLabel = "trace_id"
+
+// observeWithExemplar records value, attaching traceID as an exemplar when it
+// is non-empty and the observer supports exemplars
+func observeWithExemplar(o prometheus.Observer, value float64, traceID string) {
+	if eo, ok := o.(prometheus.ExemplarObserver); ok && traceID != "" {
+		eo.ObserveWithExemplar(value, prometheus.Labels{ExemplarTraceIDLabel: traceID})
+		return
+	}
+	o.Observe(value)
+}
+
+// traceIDFromContext returns the trace ID of the span in ctx, or "" if there is none
+func traceIDFromContext(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
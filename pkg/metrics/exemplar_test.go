@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// exemplarTraceIDs returns the trace IDs of the exemplars attached to a histogram series
+func exemplarTraceIDs(t *testing.T, o prometheus.Observer) []string {
+	var m dto.Metric
+	require.NoError(t, o.(prometheus.Metric).Write(&m))
+	var ids []string
+	for _, bucket := range m.GetHistogram().GetBucket() {
+		for _, label := range bucket.GetExemplar().GetLabel() {
+			if label.GetName() == ExemplarTraceIDLabel {
+				ids = append(ids, label.GetValue())
+			}
+		}
+	}
+	return ids
+}
+
+// otelContext returns a context carrying an OpenTelemetry span context
+func otelContext(t *testing.T) (context.Context, string) {
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+	return trace.ContextWithSpanContext(context.Background(), sc), traceID.String()
+}
+
+func TestObserveWithExemplar(t *testing.T) {
+	reporter := New(Options{Namespace: "test", Registry: prometheus.NewRegistry()})
+	histogram := reporter.Histogram("query_seconds", "Query duration", []string{"table"}, DurationBuckets)
+
+	require.NoError(t, reporter.ObserveWithExemplar("query_seconds", 0.2, "abc123", "orders"))
+	assert.Equal(t, []string{"abc123"}, exemplarTraceIDs(t, histogram.WithLabelValues("orders")))
+
+	// Without a trace ID the sample is recorded without an exemplar
+	require.NoError(t, reporter.ObserveWithExemplar("query_seconds", 0.2, "", "users"))
+	assert.Equal(t, uint64(1), histogramCount(t, histogram.WithLabelValues("users")))
+	assert.Empty(t, exemplarTraceIDs(t, histogram.WithLabelValues("users")))
+
+	assert.EqualError(t, reporter.ObserveWithExemplar("unknown_seconds", 1, "abc123"), `unknown histogram "unknown_seconds"`)
+}
+
+func TestTraceIDFromContext(t *testing.T) {
+	t.Run("none", func(t *testing.T) {
+		assert.Empty(t, traceIDFromContext(context.Background()))
+	})
+
+	t.Run("opentelemetry", func(t *testing.T) {
+		ctx, traceID := otelContext(t)
+		assert.Equal(t, traceID, traceIDFromContext(ctx))
+	})
+}
+
+func TestHTTPMiddlewareExemplar(t *testing.T) {
+	reporter := NewStandardReporter(Options{Namespace: "test", Registry: prometheus.NewRegistry()}, StandardLabels{Service: "api"})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	handler := HTTPMiddleware(reporter)(mux)
+
+	ctx, traceID := otelContext(t)
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil).WithContext(ctx)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	m := newHTTPMetrics(reporter)
+	assert.Equal(t, []string{traceID}, exemplarTraceIDs(t, m.duration.WithLabelValues("api", "GET /items/{id}", "GET")))
+}
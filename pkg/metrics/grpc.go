@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Component label values for gRPC metrics
+const (
+	grpcServerComponent = "server"
+	grpcClientComponent = "client"
+)
+
+// grpcMetrics holds the standard gRPC metrics populated by the interceptors
+type grpcMetrics struct {
+	service  string
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// newGRPCMetrics registers the standard gRPC metrics, reusing any already
+// registered by NewStandardReporter or another interceptor
+func newGRPCMetrics(r *Reporter) *grpcMetrics {
+	defs := StandardMetrics()
+	requests := defs[MetricGRPCRequestsTotal]
+	duration := defs[MetricGRPCRequestDurationSeconds]
+
+	return &grpcMetrics{
+		service: r.labels.Service,
+		requests: registerOrExisting(r, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: r.namespace,
+				Subsystem: r.subsystem,
+				Name:      requests.Name,
+				Help:      requests.Help,
+			},
+			requests.Labels,
+		)),
+		duration: registerOrExisting(r, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: r.namespace,
+				Subsystem: r.subsystem,
+				Name:      duration.Name,
+				Help:      duration.Help,
+				Buckets:   duration.Buckets,
+			},
+			duration.Labels,
+		)),
+	}
+}
+
+// observe records a finished call labeled with its gRPC status code. The
+// duration carries the trace ID of the span in ctx as an exemplar.
+func (m *grpcMetrics) observe(ctx context.Context, component, method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	m.requests.WithLabelValues(m.service, component, method, code).Inc()
+	observeWithExemplar(m.duration.WithLabelValues(m.service, component, method), time.Since(start).Seconds(), traceIDFromContext(ctx))
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor recording request count
+// and duration for unary calls handled by the server
+func UnaryServerInterceptor(reporter *Reporter) grpc.UnaryServerInterceptor {
+	m := newGRPCMetrics(reporter)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe(ctx, grpcServerComponent, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a gRPC interceptor recording request count
+// and duration for streaming calls handled by the server
+func StreamServerInterceptor(reporter *Reporter) grpc.StreamServerInterceptor {
+	m := newGRPCMetrics(reporter)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observe(ss.Context(), grpcServerComponent, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns a gRPC interceptor recording request count
+// and duration for unary calls made by the client
+func UnaryClientInterceptor(reporter *Reporter) grpc.UnaryClientInterceptor {
+	m := newGRPCMetrics(reporter)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.observe(ctx, grpcClientComponent, method, start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a gRPC interceptor recording request count
+// and duration for streaming calls made by the client. A call is recorded
+// when the stream ends.
+func StreamClientInterceptor(reporter *Reporter) grpc.StreamClientInterceptor {
+	m := newGRPCMetrics(reporter)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			m.observe(ctx, grpcClientComponent, method, start, err)
+			return nil, err
+		}
+
+		return &measuredClientStream{ClientStream: cs, finish: func(err error) {
+			m.observe(ctx, grpcClientComponent, method, start, err)
+		}}, nil
+	}
+}
+
+// measuredClientStream records the call once the stream ends
+type measuredClientStream struct {
+	grpc.ClientStream
+	finish func(error)
+	once   sync.Once
+}
+
+func (s *measuredClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == io.EOF {
+		s.once.Do(func() { s.finish(nil) })
+	} else if err != nil {
+		s.once.Do(func() { s.finish(err) })
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCInterceptors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reporter := NewStandardReporter(Options{Namespace: "test", Registry: registry}, StandardLabels{Service: "api"})
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(reporter)),
+		grpc.StreamInterceptor(StreamServerInterceptor(reporter)),
+	)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(reporter)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(reporter)),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	m := newGRPCMetrics(reporter)
+	const check = "/grpc.health.v1.Health/Check"
+
+	t.Run("unary", func(t *testing.T) {
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "orders"})
+		require.NoError(t, err)
+		_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		for _, component := range []string{"server", "client"} {
+			assert.Equal(t, 1.0, metricValue(t, m.requests.WithLabelValues("api", component, check, "OK")))
+			assert.Equal(t, 1.0, metricValue(t, m.requests.WithLabelValues("api", component, check, "NotFound")))
+		}
+		assert.Equal(t, uint64(2), histogramCount(t, m.duration.WithLabelValues("api", "server", check)))
+	})
+
+	t.Run("exemplar", func(t *testing.T) {
+		ctx, traceID := otelContext(t)
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "orders"})
+		require.NoError(t, err)
+
+		assert.Contains(t, exemplarTraceIDs(t, m.duration.WithLabelValues("api", "client", check)), traceID)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		const watch = "/grpc.health.v1.Health/Watch"
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "orders"})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.NoError(t, err)
+
+		cancel()
+		_, err = stream.Recv()
+		require.Equal(t, codes.Canceled, status.Code(err))
+
+		assert.Equal(t, 1.0, metricValue(t, m.requests.WithLabelValues("api", "client", watch, "Canceled")))
+		assert.Eventually(t, func() bool {
+			return histogramCount(t, m.duration.WithLabelValues("api", "server", watch)) == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -1,6 +1,11 @@
 package metrics
 
 import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -11,6 +16,11 @@ type Reporter struct {
 	factory   promauto.Factory
 	namespace string
 	subsystem string
+	// labels are the standard labels set by NewStandardReporter
+	labels StandardLabels
+	// histograms are the histograms created by this reporter, by name
+	histograms map[string]*prometheus.HistogramVec
+	mu         sync.Mutex
 }
 
 // Options configures the metrics reporter
@@ -75,7 +85,7 @@ func (r *Reporter) Gauge(name, help string, labels []string) *prometheus.GaugeVe
 
 // Histogram creates a new histogram metric
 func (r *Reporter) Histogram(name, help string, labels []string, buckets []float64) *prometheus.HistogramVec {
-	return r.factory.NewHistogramVec(
+	histogram := r.factory.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: r.namespace,
 			Subsystem: r.subsystem,
@@ -85,6 +95,18 @@ func (r *Reporter) Histogram(name, help string, labels []string, buckets []float
 		},
 		labels,
 	)
+	r.rememberHistogram(name, histogram)
+	return histogram
+}
+
+// rememberHistogram records a histogram so Timer and ObserveWithExemplar can find it by name
+func (r *Reporter) rememberHistogram(name string, histogram *prometheus.HistogramVec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.histograms == nil {
+		r.histograms = make(map[string]*prometheus.HistogramVec)
+	}
+	r.histograms[name] = histogram
 }
 
 // Summary creates a new summary metric
@@ -100,3 +122,121 @@ func (r *Reporter) Summary(name, help string, labels []string, objectives map[fl
 		labels,
 	)
 }
+
+// DefaultObjectives returns the default summary quantiles (p50, p90, p99) and their allowed errors
+func DefaultObjectives() map[float64]float64 {
+	return map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}
+}
+
+// SummaryWithOpts creates a new summary metric with the given quantile objectives
+// and observation window. Nil objectives use DefaultObjectives and a zero maxAge
+// uses the Prometheus default of ten minutes.
+func (r *Reporter) SummaryWithOpts(name, help string, labels []string, objectives map[float64]float64, maxAge time.Duration) *prometheus.SummaryVec {
+	if objectives == nil {
+		objectives = DefaultObjectives()
+	}
+	return r.factory.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace:  r.namespace,
+			Subsystem:  r.subsystem,
+			Name:       name,
+			Help:       help,
+			Objectives: objectives,
+			MaxAge:     maxAge,
+		},
+		labels,
+	)
+}
+
+// Timer measures elapsed time for a histogram series
+type Timer struct {
+	observer prometheus.Observer
+	start    time.Time
+}
+
+// ObserveDuration records the seconds elapsed since the timer started and
+// returns the duration
+func (t *Timer) ObserveDuration() time.Duration {
+	d := time.Since(t.start)
+	t.observer.Observe(d.Seconds())
+	return d
+}
+
+// NewTimer starts timing and returns a function that observes the elapsed
+// seconds into histogram with the given label values, e.g.
+// defer reporter.NewTimer(h, "GET", "/x")()
+func (r *Reporter) NewTimer(histogram *prometheus.HistogramVec, labels ...string) func() {
+	timer := r.StartTimer(histogram, labels...)
+	return func() {
+		timer.ObserveDuration()
+	}
+}
+
+// StartTimer starts a timer for histogram with the given label values, e.g.
+// defer reporter.StartTimer(h, "GET", "/x").ObserveDuration()
+func (r *Reporter) StartTimer(histogram *prometheus.HistogramVec, labels ...string) *Timer {
+	return &Timer{observer: histogram.WithLabelValues(labels...), start: time.Now()}
+}
+
+// Timer starts a timer for a histogram previously created by this reporter,
+// looked up by name. It returns an error if no such histogram exists.
+func (r *Reporter) Timer(histogramName string, labels ...string) (*Timer, error) {
+	histogram, err := r.histogram(histogramName)
+	if err != nil {
+		return nil, err
+	}
+	return r.StartTimer(histogram, labels...), nil
+}
+
+// ObserveWithExemplar records value in a histogram previously created by this
+// reporter, attaching traceID as an exemplar when it is non-empty. It returns
+// an error if no such histogram exists.
+func (r *Reporter) ObserveWithExemplar(histogramName string, value float64, traceID string, labels ...string) error {
+	histogram, err := r.histogram(histogramName)
+	if err != nil {
+		return err
+	}
+	observeWithExemplar(histogram.WithLabelValues(labels...), value, traceID)
+	return nil
+}
+
+// histogram returns a histogram previously created by this reporter, by name
+func (r *Reporter) histogram(name string) (*prometheus.HistogramVec, error) {
+	r.mu.Lock()
+	histogram, ok := r.histograms[name]
+	r.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown histogram %q", name)
+	}
+	return histogram, nil
+}
+
+// Time runs fn and records its duration in a histogram named name with
+// DurationBuckets, creating the histogram on first use. It returns fn's error.
+func (r *Reporter) Time(name string, labels map[string]string, fn func() error) error {
+	names := make([]string, 0, len(labels))
+	for label := range labels {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+
+	histogram := registerOrExisting(r, prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: r.namespace,
+			Subsystem: r.subsystem,
+			Name:      name,
+			Help:      fmt.Sprintf("Duration of %s in seconds", name),
+			Buckets:   DurationBuckets,
+		},
+		names,
+	))
+
+	values := make([]string, len(names))
+	for i, label := range names {
+		values[i] = labels[label]
+	}
+
+	r.rememberHistogram(name, histogram)
+	defer r.NewTimer(histogram, values...)()
+	return fn()
+}
@@ -1,7 +1,9 @@
 package metrics
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
@@ -110,3 +112,83 @@ func TestMetricCreation(t *testing.T) {
 		assert.NotEmpty(t, metrics)
 	})
 }
+
+func TestTimers(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reporter := New(Options{
+		Namespace: "test",
+		Registry:  registry,
+	})
+
+	// histogramSample returns the sample count and sum of the only series in a histogram
+	histogramSample := func(t *testing.T, name string) (uint64, float64) {
+		metrics, err := registry.Gather()
+		require.NoError(t, err)
+		for _, m := range metrics {
+			if m.GetName() == name {
+				require.Len(t, m.GetMetric(), 1)
+				h := m.GetMetric()[0].GetHistogram()
+				return h.GetSampleCount(), h.GetSampleSum()
+			}
+		}
+		t.Fatalf("metric %s not found", name)
+		return 0, 0
+	}
+
+	t.Run("NewTimer", func(t *testing.T) {
+		histogram := reporter.Histogram("handler_seconds", "Handler duration", []string{"method", "path"}, DurationBuckets)
+
+		func() {
+			defer reporter.NewTimer(histogram, "GET", "/x")()
+			time.Sleep(10 * time.Millisecond)
+		}()
+
+		count, sum := histogramSample(t, "test_handler_seconds")
+		assert.Equal(t, uint64(1), count)
+		assert.GreaterOrEqual(t, sum, 0.01)
+	})
+
+	t.Run("StartTimer", func(t *testing.T) {
+		histogram := reporter.Histogram("request_seconds", "Request duration", []string{"method"}, DurationBuckets)
+
+		func() {
+			defer reporter.StartTimer(histogram, "GET").ObserveDuration()
+			time.Sleep(20 * time.Millisecond)
+		}()
+
+		count, sum := histogramSample(t, "test_request_seconds")
+		assert.Equal(t, uint64(1), count)
+		assert.GreaterOrEqual(t, sum, 0.02)
+		assert.Less(t, sum, 0.5)
+	})
+
+	t.Run("Timer", func(t *testing.T) {
+		reporter.Histogram("query_seconds", "Query duration", []string{"table"}, DurationBuckets)
+
+		timer, err := reporter.Timer("query_seconds", "orders")
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+		elapsed := timer.ObserveDuration()
+
+		count, sum := histogramSample(t, "test_query_seconds")
+		assert.Equal(t, uint64(1), count)
+		assert.InDelta(t, elapsed.Seconds(), sum, 1e-9)
+		assert.GreaterOrEqual(t, sum, 0.02)
+		assert.Less(t, sum, 0.5)
+
+		_, err = reporter.Timer("unknown_seconds")
+		assert.EqualError(t, err, `unknown histogram "unknown_seconds"`)
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		labels := map[string]string{"job": "sync", "step": "fetch"}
+		require.NoError(t, reporter.Time("job_seconds", labels, func() error { return nil }))
+
+		// Errors are returned and the duration is still recorded
+		err := reporter.Time("job_seconds", labels, func() error { return errors.New("failed") })
+		assert.EqualError(t, err, "failed")
+
+		count, _ := histogramSample(t, "test_job_seconds")
+		assert.Equal(t, uint64(2), count)
+	})
+}
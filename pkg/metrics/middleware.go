@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// httpMetrics holds the standard HTTP metrics populated by the middleware
+type httpMetrics struct {
+	service  string
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// newHTTPMetrics registers the standard HTTP metrics, reusing any already
+// registered by NewStandardReporter
+func newHTTPMetrics(r *Reporter) *httpMetrics {
+	defs := StandardMetrics()
+	requests := defs[MetricHTTPRequestsTotal]
+	duration := defs[MetricHTTPRequestDurationSeconds]
+	inFlight := defs[MetricHTTPRequestsInFlight]
+
+	return &httpMetrics{
+		service: r.labels.Service,
+		requests: registerOrExisting(r, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: r.namespace,
+				Subsystem: r.subsystem,
+				Name:      requests.Name,
+				Help:      requests.Help,
+			},
+			requests.Labels,
+		)),
+		duration: registerOrExisting(r, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: r.namespace,
+				Subsystem: r.subsystem,
+				Name:      duration.Name,
+				Help:      duration.Help,
+				Buckets:   duration.Buckets,
+			},
+			duration.Labels,
+		)),
+		inFlight: registerOrExisting(r, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: r.namespace,
+				Subsystem: r.subsystem,
+				Name:      inFlight.Name,
+				Help:      inFlight.Help,
+			},
+			inFlight.Labels,
+		)),
+	}
+}
+
+// registerOrExisting registers a collector, returning the existing one if an
+// identical collector is already registered
+func registerOrExisting[T prometheus.Collector](r *Reporter, c T) T {
+	if err := r.registry.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+// start marks a request as in flight and returns a function recording its
+// outcome. The duration carries the trace ID of the span in ctx as an exemplar.
+func (m *httpMetrics) start(ctx context.Context, method string) func(endpoint string, status int) {
+	start := time.Now()
+	traceID := traceIDFromContext(ctx)
+	inFlight := m.inFlight.WithLabelValues(m.service)
+	inFlight.Inc()
+
+	return func(endpoint string, status int) {
+		inFlight.Dec()
+		m.requests.WithLabelValues(m.service, endpoint, method, strconv.Itoa(status)).Inc()
+		observeWithExemplar(m.duration.WithLabelValues(m.service, endpoint, method), time.Since(start).Seconds(), traceID)
+	}
+}
+
+// HTTPMiddleware creates net/http middleware that records the standard HTTP
+// metrics. Requests are labeled with the matched ServeMux pattern when
+// available, falling back to the URL path.
+func HTTPMiddleware(reporter *Reporter) func(http.Handler) http.Handler {
+	m := newHTTPMetrics(reporter)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			done := m.start(r.Context(), r.Method)
+			wrapped := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			defer func() {
+				endpoint := r.Pattern
+				if endpoint == "" {
+					endpoint = r.URL.Path
+				}
+				done(endpoint, wrapped.status)
+			}()
+
+			next.ServeHTTP(wrapped, r)
+		})
+	}
+}
+
+// GinMiddleware creates Gin middleware that records the standard HTTP metrics.
+// Requests are labeled with the matched route template, or "unmatched".
+func GinMiddleware(reporter *Reporter) gin.HandlerFunc {
+	m := newHTTPMetrics(reporter)
+	return func(c *gin.Context) {
+		done := m.start(c.Request.Context(), c.Request.Method)
+		defer func() {
+			endpoint := c.FullPath()
+			if endpoint == "" {
+				endpoint = "unmatched"
+			}
+			done(endpoint, c.Writer.Status())
+		}()
+
+		c.Next()
+	}
+}
+
+// statusRecorder wraps http.ResponseWriter to capture the status code
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
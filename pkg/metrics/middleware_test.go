@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metricValue returns the current value of a counter or gauge
+func metricValue(t *testing.T, c prometheus.Collector) float64 {
+	ch := make(chan prometheus.Metric, 1)
+	c.Collect(ch)
+	var m dto.Metric
+	require.NoError(t, (<-ch).Write(&m))
+	if m.Counter != nil {
+		return m.GetCounter().GetValue()
+	}
+	return m.GetGauge().GetValue()
+}
+
+// histogramCount returns the number of observations of a histogram series
+func histogramCount(t *testing.T, o prometheus.Observer) uint64 {
+	var m dto.Metric
+	require.NoError(t, o.(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reporter := NewStandardReporter(Options{Namespace: "test", Registry: registry}, StandardLabels{Service: "api"})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := HTTPMiddleware(reporter)(mux)
+
+	for _, path := range []string{"/items/1", "/items/2", "/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	m := newHTTPMetrics(reporter)
+	assert.Equal(t, 2.0, metricValue(t, m.requests.WithLabelValues("api", "GET /items/{id}", "GET", "201")))
+	assert.Equal(t, 1.0, metricValue(t, m.requests.WithLabelValues("api", "/missing", "GET", "404")))
+	assert.Equal(t, 0.0, metricValue(t, m.inFlight.WithLabelValues("api")))
+	assert.Equal(t, uint64(2), histogramCount(t, m.duration.WithLabelValues("api", "GET /items/{id}", "GET")))
+}
+
+func TestHTTPMiddlewareInFlight(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reporter := New(Options{Namespace: "test", Registry: registry})
+	m := newHTTPMetrics(reporter)
+
+	var inFlight float64
+	handler := HTTPMiddleware(reporter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight = metricValue(t, m.inFlight.WithLabelValues(""))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, 1.0, inFlight)
+	assert.Equal(t, 0.0, metricValue(t, m.inFlight.WithLabelValues("")))
+	assert.Equal(t, 1.0, metricValue(t, m.requests.WithLabelValues("", "/", "GET", "200")))
+}
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+	reporter := NewStandardReporter(Options{Namespace: "test", Registry: registry}, StandardLabels{Service: "api"})
+
+	router := gin.New()
+	router.Use(GinMiddleware(reporter))
+	router.GET("/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	m := newHTTPMetrics(reporter)
+	assert.Equal(t, 1.0, metricValue(t, m.requests.WithLabelValues("api", "/items/:id", "GET", "202")))
+	assert.Equal(t, 1.0, metricValue(t, m.requests.WithLabelValues("api", "unmatched", "GET", "404")))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	names := make([]string, 0, len(families))
+	for _, f := range families {
+		names = append(names, f.GetName())
+	}
+	assert.Contains(t, names, "test_http_request_duration_seconds")
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// pushTimeout bounds the final push made when a pusher is stopped
+const pushTimeout = 10 * time.Second
+
+// PushTo pushes all collected metrics to a Prometheus Pushgateway, replacing
+// any previously pushed for the same job. CollectorConfig.Labels are used as
+// grouping labels, so pushed metrics must not already carry those labels.
+func (c *MetricsCollector) PushTo(ctx context.Context, gatewayURL, job string) error {
+	return pushMetrics(ctx, c.registry, gatewayURL, job, c.config.Labels)
+}
+
+// StartPushing pushes metrics to a Pushgateway every interval until ctx is done
+// or the returned stop function is called. Stop waits for the loop to exit and
+// makes a final push so metrics recorded just before a job exits are not lost.
+func (c *MetricsCollector) StartPushing(ctx context.Context, gatewayURL, job string, interval time.Duration) (stop func(context.Context) error) {
+	return startPushing(ctx, interval, func(ctx context.Context) error {
+		return c.PushTo(ctx, gatewayURL, job)
+	})
+}
+
+// Push pushes the reporter's registry to a Prometheus Pushgateway, replacing
+// any metrics previously pushed for the same job. Non-empty standard labels
+// are used as grouping labels.
+func (r *Reporter) Push(ctx context.Context, gatewayURL, job string) error {
+	gatherer, ok := r.registry.(prometheus.Gatherer)
+	if !ok {
+		return fmt.Errorf("failed to push metrics: registry does not support gathering")
+	}
+
+	grouping := make(map[string]string)
+	for name, value := range r.labels.ToMap() {
+		if value != "" {
+			grouping[name] = value
+		}
+	}
+	return pushMetrics(ctx, gatherer, gatewayURL, job, grouping)
+}
+
+// PushPeriodically pushes the reporter's metrics every interval until ctx is
+// done or the returned stop function is called, which makes a final push
+func (r *Reporter) PushPeriodically(ctx context.Context, gatewayURL, job string, interval time.Duration) (stop func(context.Context) error) {
+	return startPushing(ctx, interval, func(ctx context.Context) error {
+		return r.Push(ctx, gatewayURL, job)
+	})
+}
+
+// pushMetrics pushes everything gathered from g with the given grouping labels
+func pushMetrics(ctx context.Context, g prometheus.Gatherer, gatewayURL, job string, grouping map[string]string) error {
+	pusher := push.New(gatewayURL, job).Gatherer(g)
+	for name, value := range grouping {
+		pusher = pusher.Grouping(name, value)
+	}
+
+	if err := pusher.PushContext(ctx); err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	return nil
+}
+
+// startPushing calls push every interval in the background. The returned stop
+// function ends the loop, waits for it to exit and pushes one final time.
+func startPushing(ctx context.Context, interval time.Duration, push func(context.Context) error) func(context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := push(ctx); err != nil {
+					fmt.Printf("Error pushing metrics: %v\n", err)
+				}
+			}
+		}
+	}()
+
+	return func(stopCtx context.Context) error {
+		cancel()
+		<-done
+
+		if _, ok := stopCtx.Deadline(); !ok {
+			var stopCancel context.CancelFunc
+			stopCtx, stopCancel = context.WithTimeout(stopCtx, pushTimeout)
+			defer stopCancel()
+		}
+		return push(stopCtx)
+	}
+}
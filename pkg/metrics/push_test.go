@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGateway records pushes received by a test Pushgateway
+type fakeGateway struct {
+	mu     sync.Mutex
+	pushes []*http.Request
+	bodies []string
+}
+
+func (g *fakeGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	g.mu.Lock()
+	g.pushes = append(g.pushes, r)
+	g.bodies = append(g.bodies, string(body))
+	g.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (g *fakeGateway) count() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.pushes)
+}
+
+func newPushCollector(t *testing.T) *MetricsCollector {
+	collector, err := NewCollector(CollectorConfig{
+		CollectionInterval: time.Second,
+		Labels:             map[string]string{"env": "test"},
+	})
+	require.NoError(t, err)
+
+	collector.GetReporter().Counter("migrations_total", "Applied migrations", nil).WithLabelValues().Add(3)
+	return collector
+}
+
+func TestPushTo(t *testing.T) {
+	gateway := &fakeGateway{}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	collector := newPushCollector(t)
+	require.NoError(t, collector.PushTo(context.Background(), server.URL, "migrate"))
+
+	require.Equal(t, 1, gateway.count())
+	assert.Equal(t, http.MethodPut, gateway.pushes[0].Method)
+	assert.Equal(t, "/metrics/job/migrate/env/test", gateway.pushes[0].URL.Path)
+	assert.True(t, strings.Contains(gateway.bodies[0], "migrations_total"))
+
+	t.Run("gateway error", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer failing.Close()
+
+		err := collector.PushTo(context.Background(), failing.URL, "migrate")
+		assert.Error(t, err)
+	})
+}
+
+func TestStartPushing(t *testing.T) {
+	gateway := &fakeGateway{}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	collector := newPushCollector(t)
+	stop := collector.StartPushing(context.Background(), server.URL, "batch", 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool { return gateway.count() >= 2 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, stop(context.Background()))
+	pushed := gateway.count()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, pushed, gateway.count(), "no pushes after stop")
+}
+
+func TestReporterPush(t *testing.T) {
+	gateway := &fakeGateway{}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	reporter := NewStandardReporter(Options{Namespace: "batch", Registry: registry}, StandardLabels{
+		Service:     "importer",
+		Environment: "test",
+	})
+	reporter.Counter("rows_imported_total", "Imported rows", nil).WithLabelValues().Add(42)
+
+	require.NoError(t, reporter.Push(context.Background(), server.URL, "import"))
+	require.Equal(t, 1, gateway.count())
+
+	path := gateway.pushes[0].URL.Path
+	assert.True(t, strings.HasPrefix(path, "/metrics/job/import/"))
+	assert.Contains(t, path, "/service/importer")
+	assert.Contains(t, path, "/environment/test")
+	assert.NotContains(t, path, "/version/")
+	assert.Contains(t, gateway.bodies[0], "batch_rows_imported_total")
+
+	t.Run("gateway error", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer failing.Close()
+
+		assert.Error(t, reporter.Push(context.Background(), failing.URL, "import"))
+	})
+
+	t.Run("registry without gatherer", func(t *testing.T) {
+		reporter := New(Options{Registry: registererOnly{registry}})
+		assert.Error(t, reporter.Push(context.Background(), server.URL, "import"))
+	})
+
+	t.Run("periodically", func(t *testing.T) {
+		before := gateway.count()
+		stop := reporter.PushPeriodically(context.Background(), server.URL, "import", 10*time.Millisecond)
+		assert.Eventually(t, func() bool { return gateway.count() > before+1 }, time.Second, 5*time.Millisecond)
+		require.NoError(t, stop(context.Background()))
+	})
+}
+
+// registererOnly hides the Gatherer implementation of a registry
+type registererOnly struct {
+	prometheus.Registerer
+}
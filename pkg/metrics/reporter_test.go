@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
@@ -85,3 +86,39 @@ func TestReporterMetricCreation(t *testing.T) {
 		assert.NotEmpty(t, metrics)
 	})
 }
+
+func TestSummaryWithOpts(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reporter := New(Options{
+		Namespace: "test",
+		Subsystem: "summary",
+		Registry:  registry,
+	})
+
+	summary := reporter.SummaryWithOpts(
+		"latency_seconds",
+		"Test latency",
+		[]string{"route"},
+		nil,
+		time.Minute,
+	)
+	for i := 1; i <= 100; i++ {
+		summary.WithLabelValues("/").Observe(float64(i))
+	}
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "test_summary_latency_seconds", families[0].GetName())
+
+	metric := families[0].GetMetric()[0].GetSummary()
+	assert.Equal(t, uint64(100), metric.GetSampleCount())
+
+	quantiles := make(map[float64]float64)
+	for _, q := range metric.GetQuantile() {
+		quantiles[q.GetQuantile()] = q.GetValue()
+	}
+	assert.Len(t, quantiles, len(DefaultObjectives()))
+	assert.InDelta(t, 99, quantiles[0.99], 1)
+	assert.InDelta(t, 50, quantiles[0.5], 5)
+}
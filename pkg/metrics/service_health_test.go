@@ -39,11 +39,21 @@ func TestServiceHealthMetrics(t *testing.T) {
 		},
 	)
 
+	dependencyUp := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "test",
+			Name:      "service_dependency_up",
+			Help:      "Whether a dependency is reachable (0: down, 1: up)",
+		},
+		[]string{"dependency"},
+	)
+
 	// Create ServiceHealth with the metrics
 	sh := &ServiceHealth{
-		status:      status,
-		uptime:      uptime,
-		lastChecked: lastChecked,
+		status:       status,
+		uptime:       uptime,
+		lastChecked:  lastChecked,
+		dependencyUp: dependencyUp,
 	}
 
 	// Register the collector
@@ -24,6 +24,10 @@ const (
 	MetricHTTPResponseSizeBytes      = "http_response_size_bytes"
 	MetricHTTPRequestsInFlight       = "http_requests_in_flight"
 
+	// gRPC metrics
+	MetricGRPCRequestsTotal          = "grpc_requests_total"
+	MetricGRPCRequestDurationSeconds = "grpc_request_duration_seconds"
+
 	// Database metrics
 	MetricDBConnectionsTotal     = "db_connections_total"
 	MetricDBConnectionsInUse     = "db_connections_in_use"
@@ -112,6 +116,25 @@ func StandardMetrics() map[string]MetricDefinition {
 			Labels:  []string{LabelService, LabelEndpoint, LabelMethod},
 			Buckets: DurationBuckets,
 		},
+		MetricHTTPRequestsInFlight: {
+			Name:   MetricHTTPRequestsInFlight,
+			Help:   "Current number of HTTP requests being served",
+			Type:   "gauge",
+			Labels: []string{LabelService},
+		},
+		MetricGRPCRequestsTotal: {
+			Name:   MetricGRPCRequestsTotal,
+			Help:   "Total number of gRPC requests",
+			Type:   "counter",
+			Labels: []string{LabelService, LabelComponent, LabelMethod, LabelStatusCode},
+		},
+		MetricGRPCRequestDurationSeconds: {
+			Name:    MetricGRPCRequestDurationSeconds,
+			Help:    "gRPC request duration in seconds",
+			Type:    "histogram",
+			Labels:  []string{LabelService, LabelComponent, LabelMethod},
+			Buckets: DurationBuckets,
+		},
 		MetricDBQueryDurationSeconds: {
 			Name:    MetricDBQueryDurationSeconds,
 			Help:    "Database query duration in seconds",
@@ -137,6 +160,7 @@ func StandardMetrics() map[string]MetricDefinition {
 // NewStandardReporter creates a Reporter with standard metrics pre-registered
 func NewStandardReporter(opts Options, labels StandardLabels) *Reporter {
 	reporter := New(opts)
+	reporter.labels = labels
 	metrics := StandardMetrics()
 
 	// Pre-register standard metrics
@@ -150,7 +174,7 @@ func NewStandardReporter(opts Options, labels StandardLabels) *Reporter {
 		case "histogram":
 			reporter.Histogram(metric.Name, metric.Help, allLabels, metric.Buckets)
 		case "summary":
-			reporter.Summary(metric.Name, metric.Help, allLabels, metric.Objectives)
+			reporter.SummaryWithOpts(metric.Name, metric.Help, allLabels, metric.Objectives, metric.MaxAge)
 		}
 	}
 
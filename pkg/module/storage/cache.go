@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/cache"
+	"github.com/StackCatalyst/common-lib/pkg/metrics"
+	"github.com/StackCatalyst/common-lib/pkg/module"
+)
+
+// CachingStorage wraps a Storage and caches module lookups in memory
+type CachingStorage struct {
+	Storage
+	cache *cache.Cache
+
+	// Cumulative latency of cached lookups, used for Stats
+	lookups        atomic.Int64
+	lookupDuration atomic.Int64
+}
+
+// NewCachingStorage creates a caching decorator around the given storage.
+// When config is nil or disabled, all calls pass straight through to the backend.
+func NewCachingStorage(backend Storage, config *CacheConfig, metricsReporter *metrics.Reporter) (*CachingStorage, error) {
+	cacheConfig := cache.DefaultConfig()
+	cacheConfig.Enabled = config != nil && config.Enabled
+
+	if config != nil {
+		if config.TTL != "" {
+			ttl, err := time.ParseDuration(config.TTL)
+			if err != nil {
+				return nil, &Error{Code: ErrInvalidInput, Message: "invalid cache TTL", Err: err}
+			}
+			cacheConfig.TTL = ttl
+		}
+		if config.MaxSize > 0 {
+			cacheConfig.MaxSize = config.MaxSize
+		}
+	}
+
+	return &CachingStorage{
+		Storage: backend,
+		cache:   cache.New(cacheConfig, metricsReporter),
+	}, nil
+}
+
+// Store saves a module and invalidates any cached copies
+func (s *CachingStorage) Store(ctx context.Context, mod *module.Module) error {
+	if err := s.Storage.Store(ctx, mod); err != nil {
+		return err
+	}
+	s.invalidate(ctx, mod.ID, mod.Version)
+	return nil
+}
+
+// StoreBatch saves modules and invalidates any cached copies
+func (s *CachingStorage) StoreBatch(ctx context.Context, modules []*module.Module) error {
+	if err := s.Storage.StoreBatch(ctx, modules); err != nil {
+		return err
+	}
+	for _, mod := range modules {
+		s.invalidate(ctx, mod.ID, mod.Version)
+	}
+	return nil
+}
+
+// Get retrieves a module, serving from cache when possible
+func (s *CachingStorage) Get(ctx context.Context, id, version string) (*module.Module, error) {
+	defer s.observeLookup(time.Now())
+	key := moduleKey(id, version)
+
+	var mod module.Module
+	if s.cache.Get(ctx, key, &mod) {
+		return &mod, nil
+	}
+
+	result, err := s.Storage.Get(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	s.set(ctx, key, result)
+	return result, nil
+}
+
+// Delete removes a module and evicts it from the cache
+func (s *CachingStorage) Delete(ctx context.Context, id, version string) error {
+	if err := s.Storage.Delete(ctx, id, version); err != nil {
+		return err
+	}
+	s.invalidate(ctx, id, version)
+	return nil
+}
+
+// DeleteBatch removes modules and evicts them from the cache
+func (s *CachingStorage) DeleteBatch(ctx context.Context, modules []*module.Module) error {
+	if err := s.Storage.DeleteBatch(ctx, modules); err != nil {
+		return err
+	}
+	for _, mod := range modules {
+		s.invalidate(ctx, mod.ID, mod.Version)
+	}
+	return nil
+}
+
+// Restore undoes a soft delete and invalidates any cached lookups
+func (s *CachingStorage) Restore(ctx context.Context, id, version string) error {
+	if err := s.Storage.Restore(ctx, id, version); err != nil {
+		return err
+	}
+	s.invalidate(ctx, id, version)
+	return nil
+}
+
+// GetLatestVersion returns the latest version of a module, serving from cache when possible
+func (s *CachingStorage) GetLatestVersion(ctx context.Context, id string) (string, error) {
+	defer s.observeLookup(time.Now())
+	key := latestKey(id)
+
+	var version string
+	if s.cache.Get(ctx, key, &version) {
+		return version, nil
+	}
+
+	version, err := s.Storage.GetLatestVersion(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	s.set(ctx, key, version)
+	return version, nil
+}
+
+// Lock marks a version as immutable and invalidates any cached copies
+func (s *CachingStorage) Lock(ctx context.Context, id, version string) error {
+	if err := s.Storage.Lock(ctx, id, version); err != nil {
+		return err
+	}
+	s.invalidate(ctx, id, version)
+	return nil
+}
+
+// GetMetadata retrieves module metadata, serving from cache when possible
+func (s *CachingStorage) GetMetadata(ctx context.Context, id, version string) (*module.Module, error) {
+	defer s.observeLookup(time.Now())
+	key := metadataKey(id, version)
+
+	var mod module.Module
+	if s.cache.Get(ctx, key, &mod) {
+		return &mod, nil
+	}
+
+	result, err := s.Storage.GetMetadata(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	s.set(ctx, key, result)
+	return result, nil
+}
+
+// UpdateMetadata updates module metadata and invalidates any cached copies
+func (s *CachingStorage) UpdateMetadata(ctx context.Context, id, version string, metadata map[string]interface{}) error {
+	if err := s.Storage.UpdateMetadata(ctx, id, version, metadata); err != nil {
+		return err
+	}
+	s.invalidate(ctx, id, version)
+	return nil
+}
+
+// UpdateMetadataIfUnchanged conditionally updates module metadata and invalidates any cached copies
+func (s *CachingStorage) UpdateMetadataIfUnchanged(ctx context.Context, id, version string, expectedRev int, metadata map[string]interface{}) (int, error) {
+	newRev, err := s.Storage.UpdateMetadataIfUnchanged(ctx, id, version, expectedRev, metadata)
+	if err != nil {
+		return 0, err
+	}
+	s.invalidate(ctx, id, version)
+	return newRev, nil
+}
+
+// StoreContent saves module content and invalidates any cached copies, since
+// content writes bump the module's revision
+func (s *CachingStorage) StoreContent(ctx context.Context, id, version string, content []byte) error {
+	if err := s.Storage.StoreContent(ctx, id, version, content); err != nil {
+		return err
+	}
+	s.invalidate(ctx, id, version)
+	return nil
+}
+
+// StoreContentStream saves module content read from r and invalidates any cached copies
+func (s *CachingStorage) StoreContentStream(ctx context.Context, id, version string, r io.Reader) error {
+	if err := s.Storage.StoreContentStream(ctx, id, version, r); err != nil {
+		return err
+	}
+	s.invalidate(ctx, id, version)
+	return nil
+}
+
+// Stats returns backend statistics enriched with cache hit rates and lookup latency.
+// Backend fields are left at zero when the wrapped storage cannot report them.
+func (s *CachingStorage) Stats(ctx context.Context) (*Stats, error) {
+	stats := &Stats{}
+	if provider, ok := s.Storage.(StatsProvider); ok {
+		backendStats, err := provider.Stats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		*stats = *backendStats
+	}
+
+	cacheStats := s.cache.Stats()
+	if total := cacheStats.Hits + cacheStats.Misses; total > 0 {
+		stats.CacheHitRate = cacheStats.HitRate
+		stats.CacheMissRate = 1 - cacheStats.HitRate
+	}
+
+	if lookups := s.lookups.Load(); lookups > 0 {
+		avg := time.Duration(s.lookupDuration.Load() / lookups)
+		stats.AvgResponseTime = float64(avg) / float64(time.Millisecond)
+	}
+
+	return stats, nil
+}
+
+// Close stops the cache and releases the wrapped storage
+func (s *CachingStorage) Close() error {
+	s.cache.Close()
+	return s.Storage.Close()
+}
+
+// observeLookup records the latency of a cached lookup started at start
+func (s *CachingStorage) observeLookup(start time.Time) {
+	s.lookups.Add(1)
+	s.lookupDuration.Add(int64(time.Since(start)))
+}
+
+// set stores a value in the cache, ignoring failures since the cache is best-effort
+func (s *CachingStorage) set(ctx context.Context, key string, value interface{}) {
+	_ = s.cache.Set(ctx, key, value)
+}
+
+// invalidate evicts every cache entry derived from the given module version
+func (s *CachingStorage) invalidate(ctx context.Context, id, version string) {
+	s.cache.Delete(ctx, moduleKey(id, version))
+	s.cache.Delete(ctx, metadataKey(id, version))
+	s.cache.Delete(ctx, latestKey(id))
+}
+
+func moduleKey(id, version string) string {
+	return fmt.Sprintf("module:%s@%s", id, version)
+}
+
+func metadataKey(id, version string) string {
+	return fmt.Sprintf("metadata:%s@%s", id, version)
+}
+
+func latestKey(id string) string {
+	return fmt.Sprintf("latest:%s", id)
+}
@@ -0,0 +1,309 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/metrics"
+	"github.com/StackCatalyst/common-lib/pkg/module"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStorage implements the Storage interface and records backend calls
+type countingStorage struct {
+	Storage
+	modules map[string]*module.Module
+	calls   map[string]int
+}
+
+func newCountingStorage() *countingStorage {
+	return &countingStorage{
+		modules: make(map[string]*module.Module),
+		calls:   make(map[string]int),
+	}
+}
+
+func (s *countingStorage) Store(ctx context.Context, mod *module.Module) error {
+	s.calls["Store"]++
+	s.modules[mod.ID+"@"+mod.Version] = mod
+	return nil
+}
+
+func (s *countingStorage) StoreBatch(ctx context.Context, mods []*module.Module) error {
+	s.calls["StoreBatch"]++
+	for _, mod := range mods {
+		s.modules[mod.ID+"@"+mod.Version] = mod
+	}
+	return nil
+}
+
+func (s *countingStorage) Get(ctx context.Context, id, version string) (*module.Module, error) {
+	s.calls["Get"]++
+	mod, exists := s.modules[id+"@"+version]
+	if !exists {
+		return nil, &Error{Code: ErrNotFound, Message: "module not found"}
+	}
+	return mod, nil
+}
+
+func (s *countingStorage) Delete(ctx context.Context, id, version string) error {
+	s.calls["Delete"]++
+	delete(s.modules, id+"@"+version)
+	return nil
+}
+
+func (s *countingStorage) DeleteBatch(ctx context.Context, mods []*module.Module) error {
+	s.calls["DeleteBatch"]++
+	for _, mod := range mods {
+		delete(s.modules, mod.ID+"@"+mod.Version)
+	}
+	return nil
+}
+
+func (s *countingStorage) Restore(ctx context.Context, id, version string) error {
+	s.calls["Restore"]++
+	return nil
+}
+
+func (s *countingStorage) GetLatestVersion(ctx context.Context, id string) (string, error) {
+	s.calls["GetLatestVersion"]++
+	return "1.0.0", nil
+}
+
+func (s *countingStorage) UpdateMetadataIfUnchanged(ctx context.Context, id, version string, expectedRev int, metadata map[string]interface{}) (int, error) {
+	s.calls["UpdateMetadataIfUnchanged"]++
+	mod, exists := s.modules[id+"@"+version]
+	if !exists {
+		return 0, &Error{Code: ErrNotFound, Message: "module not found"}
+	}
+	if mod.Revision != expectedRev {
+		return 0, &Error{Code: ErrConflict, Message: "revision mismatch"}
+	}
+	updated := *mod
+	updated.Metadata = metadata
+	updated.Revision++
+	s.modules[id+"@"+version] = &updated
+	return updated.Revision, nil
+}
+
+func newTestCachingStorage(t *testing.T, backend Storage, config *CacheConfig) *CachingStorage {
+	reporter := metrics.New(metrics.Options{
+		Namespace: "test",
+		Subsystem: "storage",
+		Registry:  prometheus.NewRegistry(),
+	})
+	s, err := NewCachingStorage(backend, config, reporter)
+	require.NoError(t, err)
+	return s
+}
+
+func TestCachingStorageGet(t *testing.T) {
+	ctx := context.Background()
+	backend := newCountingStorage()
+	s := newTestCachingStorage(t, backend, &CacheConfig{Enabled: true, TTL: "1h", MaxSize: 1024 * 1024})
+
+	mod := &module.Module{ID: "test-module", Name: "Test Module", Version: "1.0.0"}
+	require.NoError(t, s.Store(ctx, mod))
+
+	first, err := s.Get(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, mod.Name, first.Name)
+
+	second, err := s.Get(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, mod.Name, second.Name)
+
+	assert.Equal(t, 1, backend.calls["Get"])
+
+	// Latest version lookups are cached as well
+	for i := 0; i < 2; i++ {
+		version, err := s.GetLatestVersion(ctx, mod.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", version)
+	}
+	assert.Equal(t, 1, backend.calls["GetLatestVersion"])
+}
+
+func TestCachingStorageDeleteEvicts(t *testing.T) {
+	ctx := context.Background()
+	backend := newCountingStorage()
+	s := newTestCachingStorage(t, backend, &CacheConfig{Enabled: true, TTL: "1h", MaxSize: 1024 * 1024})
+
+	mod := &module.Module{ID: "test-module", Version: "1.0.0"}
+	require.NoError(t, s.Store(ctx, mod))
+
+	_, err := s.Get(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Delete(ctx, mod.ID, mod.Version))
+
+	_, err = s.Get(ctx, mod.ID, mod.Version)
+	require.Error(t, err)
+	assert.Equal(t, ErrNotFound, err.(*Error).Code)
+	assert.Equal(t, 2, backend.calls["Get"])
+}
+
+func TestCachingStorageUpdateMetadataIfUnchanged(t *testing.T) {
+	ctx := context.Background()
+	backend := newCountingStorage()
+	s := newTestCachingStorage(t, backend, &CacheConfig{Enabled: true, TTL: "1h", MaxSize: 1024 * 1024})
+
+	mod := &module.Module{ID: "test-module", Version: "1.0.0"}
+	require.NoError(t, s.Store(ctx, mod))
+	_, err := s.Get(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+
+	newRev, err := s.UpdateMetadataIfUnchanged(ctx, mod.ID, mod.Version, 0, map[string]interface{}{"owner": "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, newRev)
+
+	// The cached copy was evicted, so the new revision is visible
+	got, err := s.Get(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Revision)
+	assert.Equal(t, "alice", got.Metadata["owner"])
+
+	// A stale revision is rejected
+	_, err = s.UpdateMetadataIfUnchanged(ctx, mod.ID, mod.Version, 0, map[string]interface{}{"owner": "bob"})
+	require.Error(t, err)
+	assert.Equal(t, ErrConflict, err.(*Error).Code)
+}
+
+func TestCachingStorageContentWriteEvicts(t *testing.T) {
+	ctx := context.Background()
+	s := newTestCachingStorage(t, NewMemory(), &CacheConfig{Enabled: true, TTL: "1h", MaxSize: 1024 * 1024})
+
+	mod := newTestModule("test-module", "1.0.0", time.Now())
+	require.NoError(t, s.Store(ctx, mod))
+
+	for _, write := range []func() error{
+		func() error { return s.StoreContent(ctx, mod.ID, mod.Version, []byte("content")) },
+		func() error { return s.StoreContentStream(ctx, mod.ID, mod.Version, strings.NewReader("streamed")) },
+	} {
+		cached, err := s.Get(ctx, mod.ID, mod.Version)
+		require.NoError(t, err)
+		_, err = s.GetMetadata(ctx, mod.ID, mod.Version)
+		require.NoError(t, err)
+
+		// Content writes bump the revision, so the cached copy must be evicted
+		require.NoError(t, write())
+		got, err := s.GetMetadata(ctx, mod.ID, mod.Version)
+		require.NoError(t, err)
+		assert.Equal(t, cached.Revision+1, got.Revision)
+
+		got, err = s.Get(ctx, mod.ID, mod.Version)
+		require.NoError(t, err)
+		_, err = s.UpdateMetadataIfUnchanged(ctx, mod.ID, mod.Version, got.Revision, map[string]interface{}{"owner": "alice"})
+		require.NoError(t, err)
+	}
+}
+
+func TestCachingStorageBatchEvicts(t *testing.T) {
+	ctx := context.Background()
+	backend := newCountingStorage()
+	s := newTestCachingStorage(t, backend, &CacheConfig{Enabled: true, TTL: "1h", MaxSize: 1024 * 1024})
+
+	mods := []*module.Module{
+		{ID: "test-module", Version: "1.0.0", Description: "old"},
+		{ID: "test-module", Version: "1.1.0", Description: "old"},
+	}
+	require.NoError(t, s.StoreBatch(ctx, mods))
+	for _, mod := range mods {
+		_, err := s.Get(ctx, mod.ID, mod.Version)
+		require.NoError(t, err)
+	}
+
+	updated := []*module.Module{
+		{ID: "test-module", Version: "1.0.0", Description: "new"},
+		{ID: "test-module", Version: "1.1.0", Description: "new"},
+	}
+	require.NoError(t, s.StoreBatch(ctx, updated))
+	got, err := s.Get(ctx, "test-module", "1.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "new", got.Description)
+
+	require.NoError(t, s.DeleteBatch(ctx, updated))
+	_, err = s.Get(ctx, "test-module", "1.0.0")
+	require.Error(t, err)
+	assert.Equal(t, ErrNotFound, err.(*Error).Code)
+}
+
+func TestCachingStorageRestoreEvicts(t *testing.T) {
+	ctx := context.Background()
+	backend := newCountingStorage()
+	s := newTestCachingStorage(t, backend, &CacheConfig{Enabled: true, TTL: "1h", MaxSize: 1024 * 1024})
+
+	_, err := s.GetLatestVersion(ctx, "test-module")
+	require.NoError(t, err)
+
+	require.NoError(t, s.Restore(ctx, "test-module", "1.0.0"))
+
+	_, err = s.GetLatestVersion(ctx, "test-module")
+	require.NoError(t, err)
+	assert.Equal(t, 2, backend.calls["GetLatestVersion"])
+}
+
+func TestCachingStorageDisabled(t *testing.T) {
+	ctx := context.Background()
+	backend := newCountingStorage()
+	s := newTestCachingStorage(t, backend, &CacheConfig{Enabled: false})
+
+	mod := &module.Module{ID: "test-module", Version: "1.0.0"}
+	require.NoError(t, s.Store(ctx, mod))
+
+	for i := 0; i < 3; i++ {
+		_, err := s.Get(ctx, mod.ID, mod.Version)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, backend.calls["Get"])
+}
+
+func TestNewCachingStorageInvalidTTL(t *testing.T) {
+	_, err := NewCachingStorage(newCountingStorage(), &CacheConfig{Enabled: true, TTL: "soon"}, nil)
+	require.Error(t, err)
+	assert.Equal(t, ErrInvalidInput, err.(*Error).Code)
+}
+
+// statsStorage is a countingStorage that also reports backend statistics
+type statsStorage struct {
+	*countingStorage
+}
+
+func (s *statsStorage) Stats(ctx context.Context) (*Stats, error) {
+	return &Stats{TotalModules: len(s.modules), TotalVersions: len(s.modules)}, nil
+}
+
+func TestCachingStorageStats(t *testing.T) {
+	ctx := context.Background()
+	backend := &statsStorage{countingStorage: newCountingStorage()}
+	s := newTestCachingStorage(t, backend, &CacheConfig{Enabled: true, TTL: "1h", MaxSize: 1024 * 1024})
+
+	mod := &module.Module{ID: "test-module", Version: "1.0.0"}
+	require.NoError(t, s.Store(ctx, mod))
+
+	// One miss followed by three hits
+	for i := 0; i < 4; i++ {
+		_, err := s.Get(ctx, mod.ID, mod.Version)
+		require.NoError(t, err)
+	}
+
+	stats, err := s.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.TotalModules)
+	assert.Equal(t, 1, stats.TotalVersions)
+	assert.InDelta(t, 0.75, stats.CacheHitRate, 0.0001)
+	assert.InDelta(t, 0.25, stats.CacheMissRate, 0.0001)
+	assert.GreaterOrEqual(t, stats.AvgResponseTime, 0.0)
+}
+
+func TestCachingStorageStatsWithoutProvider(t *testing.T) {
+	s := newTestCachingStorage(t, newCountingStorage(), &CacheConfig{Enabled: true})
+
+	stats, err := s.Stats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{}, stats)
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// Checksum returns the hex-encoded SHA-256 of content
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// ChecksumReader hashes everything read through it
+type ChecksumReader struct {
+	r    io.Reader
+	hash hash.Hash
+}
+
+// NewChecksumReader wraps r so the SHA-256 of the data read can be retrieved with Sum
+func NewChecksumReader(r io.Reader) *ChecksumReader {
+	h := sha256.New()
+	return &ChecksumReader{r: io.TeeReader(r, h), hash: h}
+}
+
+// Read implements io.Reader
+func (c *ChecksumReader) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// Sum returns the hex-encoded SHA-256 of the data read so far
+func (c *ChecksumReader) Sum() string {
+	return hex.EncodeToString(c.hash.Sum(nil))
+}
+
+// verifyingReader checks content against its stored checksum once fully read
+type verifyingReader struct {
+	*ChecksumReader
+	closer   io.Closer
+	id       string
+	version  string
+	expected string
+}
+
+// NewVerifyingReader wraps module content so that reaching the end of it
+// returns an ErrIntegrity error instead of io.EOF when its SHA-256 does not
+// match expected
+func NewVerifyingReader(r io.ReadCloser, id, version, expected string) io.ReadCloser {
+	return &verifyingReader{
+		ChecksumReader: NewChecksumReader(r),
+		closer:         r,
+		id:             id,
+		version:        version,
+		expected:       expected,
+	}
+}
+
+// Read implements io.Reader
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.ChecksumReader.Read(p)
+	if err == io.EOF {
+		if actual := v.Sum(); actual != v.expected {
+			return n, &Error{
+				Code:    ErrIntegrity,
+				Message: fmt.Sprintf("content of %s@%s failed verification: expected checksum %s, got %s", v.id, v.version, v.expected, actual),
+			}
+		}
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (v *verifyingReader) Close() error {
+	return v.closer.Close()
+}
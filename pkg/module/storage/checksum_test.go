@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumReader(t *testing.T) {
+	r := NewChecksumReader(strings.NewReader("module content"))
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "module content", string(data))
+	assert.Equal(t, Checksum([]byte("module content")), r.Sum())
+}
+
+func TestVerifyingReader(t *testing.T) {
+	checksum := Checksum([]byte("module content"))
+
+	r := NewVerifyingReader(io.NopCloser(strings.NewReader("module content")), "aws-vpc", "1.0.0", checksum)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "module content", string(data))
+	require.NoError(t, r.Close())
+
+	r = NewVerifyingReader(io.NopCloser(strings.NewReader("corrupted")), "aws-vpc", "1.0.0", checksum)
+	_, err = io.ReadAll(r)
+	assert.Equal(t, ErrIntegrity, errorCode(t, err))
+}
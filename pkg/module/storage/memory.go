@@ -0,0 +1,646 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/module"
+	moduleversion "github.com/StackCatalyst/common-lib/pkg/module/version"
+)
+
+// memoryEntry is a stored module version
+type memoryEntry struct {
+	module    *module.Module
+	content   []byte
+	checksum  string
+	locked    bool
+	deletedAt *time.Time
+}
+
+// MemoryStorage is an in-memory Storage for tests. It mirrors the PostgreSQL
+// storage's semantics, including soft deletes, locking and revisions, and
+// reports failures as *Error values with the matching error code.
+type MemoryStorage struct {
+	mu        sync.RWMutex
+	entries   map[string]*memoryEntry
+	observers Observers
+}
+
+// NewMemory creates a new, empty in-memory storage
+func NewMemory() *MemoryStorage {
+	return &MemoryStorage{
+		entries: make(map[string]*memoryEntry),
+	}
+}
+
+// AddObserver registers an observer notified asynchronously after each successful mutation
+func (s *MemoryStorage) AddObserver(observer StorageObserver) {
+	s.observers.Add(observer)
+}
+
+// Store saves a copy of a module. Like the PostgreSQL storage, storing over a
+// locked version leaves it untouched.
+func (s *MemoryStorage) Store(ctx context.Context, mod *module.Module) error {
+	stored, err := s.store(mod)
+	if err != nil {
+		return err
+	}
+	if stored {
+		s.observers.Notify(ctx, EventStore, mod.ID, mod.Version)
+	}
+	return nil
+}
+
+// StoreBatch saves modules atomically; either all are stored or none are. As
+// with Store, locked versions are left untouched and not notified.
+func (s *MemoryStorage) StoreBatch(ctx context.Context, modules []*module.Module) error {
+	copies := make([]*module.Module, 0, len(modules))
+	for _, mod := range modules {
+		copied, err := copyModule(mod)
+		if err != nil {
+			return err
+		}
+		copies = append(copies, copied)
+	}
+
+	var stored []*module.Module
+	s.mu.Lock()
+	for _, mod := range copies {
+		if s.storeLocked(mod) {
+			stored = append(stored, mod)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, mod := range stored {
+		s.observers.Notify(ctx, EventStore, mod.ID, mod.Version)
+	}
+	return nil
+}
+
+// store saves a copy of a module, reporting whether it was written
+func (s *MemoryStorage) store(mod *module.Module) (bool, error) {
+	copied, err := copyModule(mod)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storeLocked(copied), nil
+}
+
+// storeLocked upserts a module, leaving locked versions untouched; the caller must hold s.mu
+func (s *MemoryStorage) storeLocked(mod *module.Module) bool {
+	key := memoryKey(mod.ID, mod.Version)
+	existing, exists := s.entries[key]
+	if !exists {
+		mod.Revision = 0
+		s.entries[key] = &memoryEntry{module: mod}
+		return true
+	}
+	if existing.locked {
+		return false
+	}
+
+	// Match the upsert, which keeps the provider and creation time, replaces
+	// the content and clears any deletion
+	mod.Provider = existing.module.Provider
+	mod.CreatedAt = existing.module.CreatedAt
+	mod.Revision = existing.module.Revision + 1
+	existing.module = mod
+	existing.content = nil
+	existing.checksum = ""
+	existing.deletedAt = nil
+	return true
+}
+
+// Get retrieves a copy of a module by its ID and version
+func (s *MemoryStorage) Get(ctx context.Context, id, version string) (*module.Module, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, err := s.live(id, version)
+	if err != nil {
+		return nil, err
+	}
+	return copyModule(entry.module)
+}
+
+// List returns modules matching the given filter, newest first. Empty filter
+// fields match everything and a zero Limit returns all matches.
+func (s *MemoryStorage) List(ctx context.Context, filter Filter) ([]*module.Module, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var name *regexp.Regexp
+	if filter.NamePattern != "" {
+		var err error
+		if name, err = likePattern(filter.NamePattern); err != nil {
+			return nil, &Error{Code: ErrInvalidInput, Message: "invalid name pattern", Err: err}
+		}
+	}
+
+	s.mu.RLock()
+	var matches []*module.Module
+	for _, entry := range s.entries {
+		mod := entry.module
+		switch {
+		case entry.deletedAt != nil:
+		case filter.Provider != "" && mod.Provider != filter.Provider:
+		case len(filter.Tags) > 0 && !hasAnyTag(mod.Tags, filter.Tags):
+		case name != nil && !name.MatchString(mod.Name):
+		case filter.Version != "" && mod.Version != filter.Version:
+		default:
+			matches = append(matches, mod)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].CreatedAt.After(matches[j].CreatedAt)
+	})
+
+	if filter.Offset >= len(matches) {
+		return nil, nil
+	}
+	matches = matches[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matches) {
+		matches = matches[:filter.Limit]
+	}
+
+	return copyModules(matches)
+}
+
+// Delete soft-deletes a module; it is hidden until restored or purged
+func (s *MemoryStorage) Delete(ctx context.Context, id, version string) error {
+	s.mu.Lock()
+	err := s.deleteLocked(id, version, time.Now())
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.observers.Notify(ctx, EventDelete, id, version)
+	return nil
+}
+
+// DeleteBatch soft-deletes modules atomically; either all are deleted or none are
+func (s *MemoryStorage) DeleteBatch(ctx context.Context, modules []*module.Module) error {
+	s.mu.Lock()
+	for _, mod := range modules {
+		if _, err := s.writable(mod.ID, mod.Version); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+	}
+	now := time.Now()
+	for _, mod := range modules {
+		s.deleteLocked(mod.ID, mod.Version, now)
+	}
+	s.mu.Unlock()
+
+	for _, mod := range modules {
+		s.observers.Notify(ctx, EventDelete, mod.ID, mod.Version)
+	}
+	return nil
+}
+
+// deleteLocked soft-deletes a module version; the caller must hold s.mu
+func (s *MemoryStorage) deleteLocked(id, version string, now time.Time) error {
+	entry, err := s.writable(id, version)
+	if err != nil {
+		return err
+	}
+	entry.deletedAt = &now
+	return nil
+}
+
+// Restore undoes a soft delete
+func (s *MemoryStorage) Restore(ctx context.Context, id, version string) error {
+	s.mu.Lock()
+	entry, exists := s.entries[memoryKey(id, version)]
+	if !exists || entry.deletedAt == nil {
+		s.mu.Unlock()
+		return notFound("deleted module not found", id, version)
+	}
+	entry.deletedAt = nil
+	s.mu.Unlock()
+
+	s.observers.Notify(ctx, EventRestore, id, version)
+	return nil
+}
+
+// Purge permanently removes modules soft-deleted more than olderThan ago
+func (s *MemoryStorage) Purge(ctx context.Context, olderThan time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	purged := 0
+	for key, entry := range s.entries {
+		if entry.deletedAt != nil && entry.deletedAt.Before(cutoff) {
+			delete(s.entries, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// GetVersions returns all versions of a module, newest first by semantic version
+func (s *MemoryStorage) GetVersions(ctx context.Context, id string) ([]string, error) {
+	s.mu.RLock()
+	var versions []string
+	for _, entry := range s.entries {
+		if entry.module.ID == id && entry.deletedAt == nil {
+			versions = append(versions, entry.module.Version)
+		}
+	}
+	s.mu.RUnlock()
+
+	return moduleversion.SortDescending(versions), nil
+}
+
+// GetLatestVersion returns the latest version of a module
+func (s *MemoryStorage) GetLatestVersion(ctx context.Context, id string) (string, error) {
+	versions, err := s.GetVersions(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	latest, err := moduleversion.Latest(versions)
+	if err != nil {
+		return "", &Error{Code: ErrNotFound, Message: "module not found: " + id, Err: err}
+	}
+	return latest, nil
+}
+
+// GetLatestStableVersion returns the latest non-prerelease version of a module
+func (s *MemoryStorage) GetLatestStableVersion(ctx context.Context, id string) (string, error) {
+	versions, err := s.GetVersions(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	latest, err := moduleversion.LatestStable(versions)
+	if err != nil {
+		return "", &Error{Code: ErrNotFound, Message: "no stable version found: " + id, Err: err}
+	}
+	return latest, nil
+}
+
+// Lock marks a version as immutable
+func (s *MemoryStorage) Lock(ctx context.Context, id, version string) error {
+	s.mu.Lock()
+	entry, err := s.live(id, version)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	entry.locked = true
+	s.mu.Unlock()
+
+	s.observers.Notify(ctx, EventLock, id, version)
+	return nil
+}
+
+// GetMetadata retrieves module metadata without content
+func (s *MemoryStorage) GetMetadata(ctx context.Context, id, version string) (*module.Module, error) {
+	return s.Get(ctx, id, version)
+}
+
+// UpdateMetadata updates module metadata without changing content
+func (s *MemoryStorage) UpdateMetadata(ctx context.Context, id, version string, metadata map[string]interface{}) error {
+	_, err := s.updateMetadata(ctx, id, version, nil, metadata)
+	return err
+}
+
+// UpdateMetadataIfUnchanged updates module metadata only if the stored revision
+// still equals expectedRev, returning the new revision or an ErrConflict error
+func (s *MemoryStorage) UpdateMetadataIfUnchanged(ctx context.Context, id, version string, expectedRev int, metadata map[string]interface{}) (int, error) {
+	return s.updateMetadata(ctx, id, version, &expectedRev, metadata)
+}
+
+// updateMetadata replaces metadata, checking the revision when expectedRev is set
+func (s *MemoryStorage) updateMetadata(ctx context.Context, id, version string, expectedRev *int, metadata map[string]interface{}) (int, error) {
+	copied, err := copyMetadata(metadata)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	entry, err := s.writable(id, version)
+	if err != nil {
+		s.mu.Unlock()
+		return 0, err
+	}
+	if expectedRev != nil && entry.module.Revision != *expectedRev {
+		current := entry.module.Revision
+		s.mu.Unlock()
+		return 0, &Error{
+			Code:    ErrConflict,
+			Message: fmt.Sprintf("module %s@%s was modified: expected revision %d, found %d", id, version, *expectedRev, current),
+		}
+	}
+	entry.module.Metadata = copied
+	entry.module.UpdatedAt = time.Now()
+	entry.module.Revision++
+	revision := entry.module.Revision
+	s.mu.Unlock()
+
+	s.observers.Notify(ctx, EventUpdate, id, version)
+	return revision, nil
+}
+
+// StoreContent saves module content to storage
+func (s *MemoryStorage) StoreContent(ctx context.Context, id, version string, content []byte) error {
+	s.mu.Lock()
+	entry, err := s.writable(id, version)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	entry.content = append([]byte(nil), content...)
+	entry.checksum = Checksum(content)
+	entry.module.UpdatedAt = time.Now()
+	entry.module.Revision++
+	s.mu.Unlock()
+
+	s.observers.Notify(ctx, EventUpdate, id, version)
+	return nil
+}
+
+// GetContent retrieves module content from storage
+func (s *MemoryStorage) GetContent(ctx context.Context, id, version string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, err := s.live(id, version)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), entry.content...), nil
+}
+
+// StoreContentStream saves module content read from r
+func (s *MemoryStorage) StoreContentStream(ctx context.Context, id, version string, r io.Reader) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read content: %w", err)
+	}
+	return s.StoreContent(ctx, id, version, content)
+}
+
+// GetContentStream returns a reader over module content
+func (s *MemoryStorage) GetContentStream(ctx context.Context, id, version string) (io.ReadCloser, error) {
+	content, err := s.GetContent(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// GetContentChecksum returns the SHA-256 of module content
+func (s *MemoryStorage) GetContentChecksum(ctx context.Context, id, version string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, err := s.live(id, version)
+	if err != nil {
+		return "", err
+	}
+	if entry.checksum == "" {
+		return "", &Error{Code: ErrNotFound, Message: fmt.Sprintf("no content stored for %s@%s", id, version)}
+	}
+	return entry.checksum, nil
+}
+
+// Exists checks if a module version exists
+func (s *MemoryStorage) Exists(ctx context.Context, id, version string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, err := s.live(id, version)
+	return err == nil, nil
+}
+
+// GetDependencies returns all modules that depend on the given module version
+func (s *MemoryStorage) GetDependencies(ctx context.Context, id, version string) ([]*module.Module, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	var dependents []*module.Module
+	for _, entry := range s.entries {
+		if entry.deletedAt != nil {
+			continue
+		}
+		for _, dep := range entry.module.Dependencies {
+			if dep != nil && dep.Source == id && dep.Version == version {
+				dependents = append(dependents, entry.module)
+				break
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	return copyModules(dependents)
+}
+
+// ListProviders returns the distinct providers of all stored modules, sorted by name
+func (s *MemoryStorage) ListProviders(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	seen := make(map[string]bool)
+	var providers []string
+	for _, entry := range s.entries {
+		if entry.deletedAt == nil && !seen[entry.module.Provider] {
+			seen[entry.module.Provider] = true
+			providers = append(providers, entry.module.Provider)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(providers)
+	return providers, nil
+}
+
+// ListTags returns every tag with the number of modules carrying it, most used first
+func (s *MemoryStorage) ListTags(ctx context.Context) ([]TagCount, error) {
+	s.mu.RLock()
+	modulesByTag := make(map[string]map[string]bool)
+	for _, entry := range s.entries {
+		if entry.deletedAt != nil {
+			continue
+		}
+		for _, tag := range entry.module.Tags {
+			if modulesByTag[tag] == nil {
+				modulesByTag[tag] = make(map[string]bool)
+			}
+			modulesByTag[tag][entry.module.ID] = true
+		}
+	}
+	s.mu.RUnlock()
+
+	tags := make([]TagCount, 0, len(modulesByTag))
+	for tag, modules := range modulesByTag {
+		tags = append(tags, TagCount{Tag: tag, Count: len(modules)})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags, nil
+}
+
+// Stats returns aggregate statistics about the stored modules
+func (s *MemoryStorage) Stats(ctx context.Context) (*Stats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := &Stats{}
+	ids := make(map[string]bool)
+	for _, entry := range s.entries {
+		if entry.deletedAt != nil {
+			continue
+		}
+		ids[entry.module.ID] = true
+		stats.TotalVersions++
+		stats.StorageSize += int64(len(entry.content))
+		if entry.module.UpdatedAt.After(stats.LastUpdated) {
+			stats.LastUpdated = entry.module.UpdatedAt
+		}
+	}
+	stats.TotalModules = len(ids)
+	return stats, nil
+}
+
+// Close waits for pending observer notifications
+func (s *MemoryStorage) Close() error {
+	s.observers.Wait()
+	return nil
+}
+
+// live returns a module version that has not been deleted; the caller must hold s.mu
+func (s *MemoryStorage) live(id, version string) (*memoryEntry, error) {
+	entry, exists := s.entries[memoryKey(id, version)]
+	if !exists || entry.deletedAt != nil {
+		return nil, notFound("module not found", id, version)
+	}
+	return entry, nil
+}
+
+// writable returns a live module version that is not locked; the caller must hold s.mu
+func (s *MemoryStorage) writable(id, version string) (*memoryEntry, error) {
+	entry, err := s.live(id, version)
+	if err != nil {
+		return nil, err
+	}
+	if entry.locked {
+		return nil, &Error{Code: ErrConflict, Message: fmt.Sprintf("module locked: %s@%s", id, version)}
+	}
+	return entry, nil
+}
+
+func memoryKey(id, version string) string {
+	return id + "@" + version
+}
+
+func notFound(message, id, version string) *Error {
+	return &Error{Code: ErrNotFound, Message: fmt.Sprintf("%s: %s@%s", message, id, version)}
+}
+
+// copyModule deep-copies a module through JSON, as a round trip through the database would
+func copyModule(mod *module.Module) (*module.Module, error) {
+	if mod == nil || mod.ID == "" || mod.Version == "" {
+		return nil, &Error{Code: ErrInvalidInput, Message: "module ID and version are required"}
+	}
+
+	data, err := json.Marshal(mod)
+	if err != nil {
+		return nil, &Error{Code: ErrInvalidInput, Message: "failed to encode module", Err: err}
+	}
+	copied := &module.Module{}
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, &Error{Code: ErrInternal, Message: "failed to decode module", Err: err}
+	}
+	return copied, nil
+}
+
+// copyModules deep-copies a list of modules
+func copyModules(mods []*module.Module) ([]*module.Module, error) {
+	copies := make([]*module.Module, 0, len(mods))
+	for _, mod := range mods {
+		copied, err := copyModule(mod)
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, copied)
+	}
+	return copies, nil
+}
+
+// copyMetadata deep-copies metadata through JSON
+func copyMetadata(metadata map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, &Error{Code: ErrInvalidInput, Message: "failed to encode metadata", Err: err}
+	}
+	var copied map[string]interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, &Error{Code: ErrInternal, Message: "failed to decode metadata", Err: err}
+	}
+	return copied, nil
+}
+
+// hasAnyTag reports whether tags and wanted share at least one tag
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// likePattern compiles a SQL LIKE pattern the way PostgreSQL reads it: % and _
+// are the only wildcards and a backslash makes the next character literal
+func likePattern(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if escaped {
+		return nil, errors.New("LIKE pattern must not end with the escape character")
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
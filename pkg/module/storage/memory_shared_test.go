@@ -0,0 +1,14 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/StackCatalyst/common-lib/pkg/module/storage"
+	"github.com/StackCatalyst/common-lib/pkg/module/storage/storagetest"
+)
+
+func TestMemoryStorageShared(t *testing.T) {
+	t.Run("re-store", func(t *testing.T) {
+		storagetest.TestReStore(t, storage.NewMemory())
+	})
+}
@@ -0,0 +1,346 @@
+package storage
+
+import (
+	"context"
+	"crypto/ed25519"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/module"
+	moduleversion "github.com/StackCatalyst/common-lib/pkg/module/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errorCode returns the storage error code of err
+func errorCode(t *testing.T, err error) string {
+	t.Helper()
+	require.Error(t, err)
+	storageErr, ok := err.(*Error)
+	require.True(t, ok, "expected *Error, got %T", err)
+	return storageErr.Code
+}
+
+func newTestModule(id, version string, created time.Time) *module.Module {
+	return &module.Module{
+		ID:        id,
+		Name:      id,
+		Provider:  "aws",
+		Version:   version,
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+}
+
+func TestMemoryStorageStoreAndGet(t *testing.T) {
+	ctx := context.Background()
+	var s Storage = NewMemory()
+
+	mod := newTestModule("aws-vpc", "1.0.0", time.Now())
+	mod.Metadata = map[string]interface{}{"owner": "alice"}
+	require.NoError(t, s.Store(ctx, mod))
+
+	got, err := s.Get(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "aws-vpc", got.ID)
+	assert.Equal(t, "alice", got.Metadata["owner"])
+	assert.Equal(t, 0, got.Revision)
+
+	// Stored modules are copies
+	mod.Metadata["owner"] = "mallory"
+	got.Name = "changed"
+	again, err := s.Get(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", again.Metadata["owner"])
+	assert.Equal(t, "aws-vpc", again.Name)
+
+	// Storing again bumps the revision
+	require.NoError(t, s.Store(ctx, mod))
+	again, err = s.Get(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, 1, again.Revision)
+
+	_, err = s.Get(ctx, "aws-vpc", "2.0.0")
+	assert.Equal(t, ErrNotFound, errorCode(t, err))
+
+	err = s.Store(ctx, &module.Module{ID: "no-version"})
+	assert.Equal(t, ErrInvalidInput, errorCode(t, err))
+
+	exists, err := s.Exists(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestMemoryStorageList(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory()
+
+	now := time.Now()
+	vpc := newTestModule("aws-vpc", "1.0.0", now.Add(-2*time.Hour))
+	vpc.Tags = []string{"network"}
+	s3 := newTestModule("aws-s3", "1.0.0", now.Add(-time.Hour))
+	s3.Tags = []string{"storage"}
+	gcp := newTestModule("gcp-vpc", "1.0.0", now)
+	gcp.Provider = "gcp"
+	gcp.Tags = []string{"network"}
+	require.NoError(t, s.StoreBatch(ctx, []*module.Module{vpc, s3, gcp}))
+
+	ids := func(mods []*module.Module) []string {
+		var result []string
+		for _, mod := range mods {
+			result = append(result, mod.ID)
+		}
+		return result
+	}
+
+	all, err := s.List(ctx, Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gcp-vpc", "aws-s3", "aws-vpc"}, ids(all))
+
+	byProvider, err := s.List(ctx, Filter{Provider: "aws"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws-s3", "aws-vpc"}, ids(byProvider))
+
+	byTag, err := s.List(ctx, Filter{Tags: []string{"network"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gcp-vpc", "aws-vpc"}, ids(byTag))
+
+	byName, err := s.List(ctx, Filter{NamePattern: "%-vpc"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gcp-vpc", "aws-vpc"}, ids(byName))
+
+	byPrefix, err := s.List(ctx, Filter{NamePattern: "aws-%"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws-s3", "aws-vpc"}, ids(byPrefix))
+
+	// As in PostgreSQL, * is a literal character and a backslash escapes wildcards
+	byGlob, err := s.List(ctx, Filter{NamePattern: "aws-*"})
+	require.NoError(t, err)
+	assert.Empty(t, byGlob)
+
+	byLiteral, err := s.List(ctx, Filter{NamePattern: `aws\_vpc`})
+	require.NoError(t, err)
+	assert.Empty(t, byLiteral)
+
+	bySingle, err := s.List(ctx, Filter{NamePattern: "aws_vpc"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws-vpc"}, ids(bySingle))
+
+	page, err := s.List(ctx, Filter{Offset: 1, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws-s3"}, ids(page))
+
+	providers, err := s.ListProviders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws", "gcp"}, providers)
+
+	tags, err := s.ListTags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []TagCount{{Tag: "network", Count: 2}, {Tag: "storage", Count: 1}}, tags)
+}
+
+func TestMemoryStorageVersions(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory()
+
+	now := time.Now()
+	for _, version := range []string{"1.2.0", "1.10.0", "2.0.0-beta.1"} {
+		require.NoError(t, s.Store(ctx, newTestModule("aws-vpc", version, now)))
+	}
+
+	versions, err := s.GetVersions(ctx, "aws-vpc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2.0.0-beta.1", "1.10.0", "1.2.0"}, versions)
+
+	latest, err := s.GetLatestVersion(ctx, "aws-vpc")
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0-beta.1", latest)
+
+	stable, err := s.GetLatestStableVersion(ctx, "aws-vpc")
+	require.NoError(t, err)
+	assert.Equal(t, "1.10.0", stable)
+
+	_, err = s.GetLatestVersion(ctx, "missing")
+	assert.Equal(t, ErrNotFound, errorCode(t, err))
+}
+
+func TestMemoryStorageLocking(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory()
+
+	mod := newTestModule("aws-vpc", "1.0.0", time.Now())
+	mod.Description = "original"
+	require.NoError(t, s.Store(ctx, mod))
+	require.NoError(t, s.Lock(ctx, "aws-vpc", "1.0.0"))
+
+	// Storing over a locked version leaves it untouched
+	mod.Description = "changed"
+	require.NoError(t, s.Store(ctx, mod))
+	got, err := s.Get(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "original", got.Description)
+
+	assert.Equal(t, ErrConflict, errorCode(t, s.Delete(ctx, "aws-vpc", "1.0.0")))
+	assert.Equal(t, ErrConflict, errorCode(t, s.UpdateMetadata(ctx, "aws-vpc", "1.0.0", nil)))
+	assert.Equal(t, ErrConflict, errorCode(t, s.StoreContent(ctx, "aws-vpc", "1.0.0", []byte("x"))))
+	assert.Equal(t, ErrNotFound, errorCode(t, s.Lock(ctx, "aws-vpc", "9.9.9")))
+}
+
+func TestMemoryStorageMetadataAndContent(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory()
+
+	require.NoError(t, s.Store(ctx, newTestModule("aws-vpc", "1.0.0", time.Now())))
+
+	require.NoError(t, s.StoreContent(ctx, "aws-vpc", "1.0.0", []byte("module content")))
+	content, err := s.GetContent(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("module content"), content)
+
+	stats, err := s.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.TotalModules)
+	assert.Equal(t, int64(len("module content")), stats.StorageSize)
+
+	// The content write bumped the revision, so revision 0 is stale
+	_, err = s.UpdateMetadataIfUnchanged(ctx, "aws-vpc", "1.0.0", 0, map[string]interface{}{"owner": "bob"})
+	assert.Equal(t, ErrConflict, errorCode(t, err))
+
+	newRev, err := s.UpdateMetadataIfUnchanged(ctx, "aws-vpc", "1.0.0", 1, map[string]interface{}{"owner": "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, newRev)
+
+	meta, err := s.GetMetadata(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", meta.Metadata["owner"])
+
+	_, err = s.GetContent(ctx, "missing", "1.0.0")
+	assert.Equal(t, ErrNotFound, errorCode(t, err))
+
+	require.NoError(t, s.StoreContentStream(ctx, "aws-vpc", "1.0.0", strings.NewReader("streamed content")))
+	r, err := s.GetContentStream(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	defer r.Close()
+	streamed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "streamed content", string(streamed))
+
+	checksum, err := s.GetContentChecksum(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, Checksum([]byte("streamed content")), checksum)
+}
+
+func TestMemoryStorageDependencies(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory()
+
+	now := time.Now()
+	vpc := newTestModule("aws-vpc", "1.0.0", now)
+	eks := newTestModule("aws-eks", "1.0.0", now)
+	eks.Dependencies = []*module.Dependency{{Name: "vpc", Source: "aws-vpc", Version: "1.0.0"}}
+	rds := newTestModule("aws-rds", "1.0.0", now)
+	rds.Dependencies = []*module.Dependency{{Name: "vpc", Source: "aws-vpc", Version: "2.0.0"}}
+	require.NoError(t, s.StoreBatch(ctx, []*module.Module{vpc, eks, rds}))
+
+	dependents, err := s.GetDependencies(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	require.Len(t, dependents, 1)
+	assert.Equal(t, "aws-eks", dependents[0].ID)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = s.GetDependencies(cancelled, "aws-vpc", "1.0.0")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = s.List(cancelled, Filter{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMemoryStorageSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory()
+
+	now := time.Now()
+	mods := []*module.Module{
+		newTestModule("aws-vpc", "1.0.0", now),
+		newTestModule("aws-vpc", "1.1.0", now),
+	}
+	require.NoError(t, s.StoreBatch(ctx, mods))
+
+	require.NoError(t, s.Delete(ctx, "aws-vpc", "1.0.0"))
+	_, err := s.Get(ctx, "aws-vpc", "1.0.0")
+	assert.Equal(t, ErrNotFound, errorCode(t, err))
+
+	require.NoError(t, s.Restore(ctx, "aws-vpc", "1.0.0"))
+	_, err = s.Get(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, ErrNotFound, errorCode(t, s.Restore(ctx, "aws-vpc", "1.0.0")))
+
+	// A batch with a missing module deletes nothing
+	err = s.DeleteBatch(ctx, append(mods, newTestModule("aws-vpc", "9.9.9", now)))
+	assert.Equal(t, ErrNotFound, errorCode(t, err))
+	versions, err := s.GetVersions(ctx, "aws-vpc")
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+
+	require.NoError(t, s.DeleteBatch(ctx, mods))
+	purged, err := s.Purge(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+	purged, err = s.Purge(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	assert.Equal(t, ErrNotFound, errorCode(t, s.Restore(ctx, "aws-vpc", "1.0.0")))
+}
+
+func TestMemoryStorageObservers(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory()
+	observer := &recordingObserver{}
+	s.AddObserver(observer)
+
+	require.NoError(t, s.Store(ctx, newTestModule("aws-vpc", "1.0.0", time.Now())))
+	require.NoError(t, s.Lock(ctx, "aws-vpc", "1.0.0"))
+
+	// Locked versions skipped by a batch are not reported as stored
+	require.NoError(t, s.StoreBatch(ctx, []*module.Module{
+		newTestModule("aws-vpc", "1.0.0", time.Now()),
+		newTestModule("aws-vpc", "1.1.0", time.Now()),
+	}))
+	require.NoError(t, s.Close())
+
+	assert.ElementsMatch(t, []string{"store:aws-vpc@1.0.0", "lock:aws-vpc@1.0.0", "store:aws-vpc@1.1.0"}, observer.recorded())
+}
+
+func TestMemoryStorageSignedModule(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemory()
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	verifier := moduleversion.NewManagerWithOptions(moduleversion.Options{PublicKey: publicKey})
+
+	mod := newTestModule("aws-vpc", "1.0.0", time.Now())
+	mod.Source = "github.com/acme/aws-vpc"
+	mod.Variables = []*module.Variable{{Name: "cidr", Type: "string", Default: "10.0.0.0/16"}}
+	require.NoError(t, moduleversion.Sign(mod, privateKey))
+	require.NoError(t, s.Store(ctx, mod))
+
+	// The signature survives storage writes that don't change the published fields
+	require.NoError(t, s.UpdateMetadata(ctx, mod.ID, mod.Version, map[string]interface{}{"owner": "alice"}))
+	require.NoError(t, s.StoreContent(ctx, mod.ID, mod.Version, []byte("content")))
+	require.NoError(t, s.Store(ctx, mod))
+
+	stored, err := s.Get(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, mod.Signature, stored.Signature)
+	assert.NoError(t, verifier.Verify(stored))
+
+	// Revision bumps from optimistic updates don't invalidate it either
+	revision, err := s.UpdateMetadataIfUnchanged(ctx, mod.ID, mod.Version, stored.Revision, map[string]interface{}{"owner": "bob"})
+	require.NoError(t, err)
+	stored, err = s.Get(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, revision, stored.Revision)
+	assert.NoError(t, verifier.Verify(stored))
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/StackCatalyst/common-lib/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// EventType identifies a storage mutation
+type EventType string
+
+// Storage mutation events
+const (
+	EventStore   EventType = "store"
+	EventDelete  EventType = "delete"
+	EventRestore EventType = "restore"
+	EventLock    EventType = "lock"
+	EventUpdate  EventType = "update"
+)
+
+// StorageObserver is notified after a storage mutation succeeds
+type StorageObserver interface {
+	// OnStore is called after a module version is stored
+	OnStore(ctx context.Context, id, version string) error
+	// OnDelete is called after a module version is deleted
+	OnDelete(ctx context.Context, id, version string) error
+	// OnRestore is called after a deleted module version is restored
+	OnRestore(ctx context.Context, id, version string) error
+	// OnLock is called after a module version is locked
+	OnLock(ctx context.Context, id, version string) error
+	// OnUpdate is called after a module version's metadata or content changes
+	OnUpdate(ctx context.Context, id, version string) error
+}
+
+// Observers notifies registered observers of storage mutations. Observers run
+// asynchronously so they never block the mutation, and their errors are logged
+// with the logger from the mutation's context. The zero value is ready to use.
+type Observers struct {
+	mu        sync.RWMutex
+	observers []StorageObserver
+	inflight  sync.WaitGroup
+}
+
+// Add registers an observer
+func (o *Observers) Add(observer StorageObserver) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observers = append(o.observers, observer)
+}
+
+// Notify sends an event for a module version to every observer in the background
+func (o *Observers) Notify(ctx context.Context, event EventType, id, version string) {
+	o.mu.RLock()
+	observers := o.observers
+	o.mu.RUnlock()
+	if len(observers) == 0 {
+		return
+	}
+
+	// Observers outlive the mutation, so they must not be cancelled with it
+	ctx = context.WithoutCancel(ctx)
+	for _, observer := range observers {
+		o.inflight.Add(1)
+		go func(observer StorageObserver) {
+			defer o.inflight.Done()
+			if err := dispatch(ctx, observer, event, id, version); err != nil {
+				logging.LoggerFromContext(ctx).Error("Storage observer failed",
+					zap.String("event", string(event)),
+					zap.String("module_id", id),
+					zap.String("version", version),
+					zap.Error(err),
+				)
+			}
+		}(observer)
+	}
+}
+
+// Wait blocks until all in-flight notifications have completed
+func (o *Observers) Wait() {
+	o.inflight.Wait()
+}
+
+// dispatch calls the observer method matching event
+func dispatch(ctx context.Context, observer StorageObserver, event EventType, id, version string) error {
+	switch event {
+	case EventStore:
+		return observer.OnStore(ctx, id, version)
+	case EventDelete:
+		return observer.OnDelete(ctx, id, version)
+	case EventRestore:
+		return observer.OnRestore(ctx, id, version)
+	case EventLock:
+		return observer.OnLock(ctx, id, version)
+	default:
+		return observer.OnUpdate(ctx, id, version)
+	}
+}
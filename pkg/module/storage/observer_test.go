@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/logging"
+	loggingtest "github.com/StackCatalyst/common-lib/pkg/logging/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingObserver records the events it receives
+type recordingObserver struct {
+	mu      sync.Mutex
+	events  []string
+	err     error
+	release chan struct{} // when set, every call blocks until it is closed
+}
+
+func (o *recordingObserver) record(event EventType, id, version string) error {
+	if o.release != nil {
+		<-o.release
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, string(event)+":"+id+"@"+version)
+	return o.err
+}
+
+func (o *recordingObserver) recorded() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.events...)
+}
+
+func (o *recordingObserver) OnStore(ctx context.Context, id, version string) error {
+	return o.record(EventStore, id, version)
+}
+
+func (o *recordingObserver) OnDelete(ctx context.Context, id, version string) error {
+	return o.record(EventDelete, id, version)
+}
+
+func (o *recordingObserver) OnRestore(ctx context.Context, id, version string) error {
+	return o.record(EventRestore, id, version)
+}
+
+func (o *recordingObserver) OnLock(ctx context.Context, id, version string) error {
+	return o.record(EventLock, id, version)
+}
+
+func (o *recordingObserver) OnUpdate(ctx context.Context, id, version string) error {
+	return o.record(EventUpdate, id, version)
+}
+
+func TestObserversNotify(t *testing.T) {
+	var observers Observers
+	first, second := &recordingObserver{}, &recordingObserver{}
+	observers.Add(first)
+	observers.Add(second)
+
+	ctx := context.Background()
+	for _, event := range []EventType{EventStore, EventDelete, EventRestore, EventLock, EventUpdate} {
+		observers.Notify(ctx, event, "aws-vpc", "1.0.0")
+		observers.Wait()
+	}
+
+	expected := []string{
+		"store:aws-vpc@1.0.0",
+		"delete:aws-vpc@1.0.0",
+		"restore:aws-vpc@1.0.0",
+		"lock:aws-vpc@1.0.0",
+		"update:aws-vpc@1.0.0",
+	}
+	assert.Equal(t, expected, first.recorded())
+	assert.Equal(t, expected, second.recorded())
+}
+
+func TestObserversDoNotBlock(t *testing.T) {
+	var observers Observers
+	observer := &recordingObserver{release: make(chan struct{})}
+	observers.Add(observer)
+
+	// Cancelling the mutation's context must not cancel the notification
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	observers.Notify(ctx, EventStore, "aws-vpc", "1.0.0")
+	cancel()
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.Empty(t, observer.recorded())
+
+	close(observer.release)
+	observers.Wait()
+	assert.Equal(t, []string{"store:aws-vpc@1.0.0"}, observer.recorded())
+}
+
+func TestObserversLogErrors(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := loggingtest.NewTestLogger(&buf)
+	require.NoError(t, err)
+	ctx := logging.ContextWithLogger(context.Background(), logger)
+
+	var observers Observers
+	observers.Add(&recordingObserver{err: errors.New("index unavailable")})
+
+	observers.Notify(ctx, EventDelete, "aws-vpc", "1.0.0")
+	observers.Wait()
+
+	assert.Contains(t, buf.String(), "Storage observer failed")
+	assert.Contains(t, buf.String(), "index unavailable")
+	assert.Contains(t, buf.String(), "aws-vpc")
+}
+
+func TestObserversEmpty(t *testing.T) {
+	var observers Observers
+	observers.Notify(context.Background(), EventStore, "aws-vpc", "1.0.0")
+	observers.Wait()
+}
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS modules (
+    id             TEXT NOT NULL,
+    name           TEXT NOT NULL,
+    provider       TEXT NOT NULL,
+    version        TEXT NOT NULL,
+    description    TEXT,
+    source         TEXT,
+    variables      JSONB,
+    outputs        JSONB,
+    dependencies   JSONB,
+    tags           TEXT[],
+    created_at     TIMESTAMPTZ NOT NULL,
+    updated_at     TIMESTAMPTZ NOT NULL,
+    metadata       JSONB,
+    content        BYTEA,
+    content_sha256 TEXT,
+    signature      TEXT,
+    locked         BOOLEAN NOT NULL DEFAULT false,
+    revision       INTEGER NOT NULL DEFAULT 0,
+    deleted_at     TIMESTAMPTZ,
+    PRIMARY KEY (id, version)
+);
+
+CREATE TABLE IF NOT EXISTS module_content_chunks (
+    module_id      TEXT NOT NULL,
+    module_version TEXT NOT NULL,
+    seq            INTEGER NOT NULL,
+    data           BYTEA NOT NULL,
+    PRIMARY KEY (module_id, module_version, seq),
+    FOREIGN KEY (module_id, module_version) REFERENCES modules (id, version) ON DELETE CASCADE
+);
@@ -0,0 +1,19 @@
+-- Brings a modules table created before these migrations existed up to the
+-- schema in 0001_create_modules.sql; on a fresh database it changes nothing.
+ALTER TABLE modules
+    ADD COLUMN IF NOT EXISTS metadata       JSONB,
+    ADD COLUMN IF NOT EXISTS content        BYTEA,
+    ADD COLUMN IF NOT EXISTS content_sha256 TEXT,
+    ADD COLUMN IF NOT EXISTS signature      TEXT,
+    ADD COLUMN IF NOT EXISTS locked         BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS revision       INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS deleted_at     TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS module_content_chunks (
+    module_id      TEXT NOT NULL,
+    module_version TEXT NOT NULL,
+    seq            INTEGER NOT NULL,
+    data           BYTEA NOT NULL,
+    PRIMARY KEY (module_id, module_version, seq),
+    FOREIGN KEY (module_id, module_version) REFERENCES modules (id, version) ON DELETE CASCADE
+);
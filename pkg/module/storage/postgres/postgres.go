@@ -1,28 +1,47 @@
 package postgres
 
 import (
+	"bytes"
 	"context"
+	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/StackCatalyst/common-lib/pkg/database"
 	"github.com/StackCatalyst/common-lib/pkg/metrics"
 	"github.com/StackCatalyst/common-lib/pkg/module"
 	"github.com/StackCatalyst/common-lib/pkg/module/storage"
+	moduleversion "github.com/StackCatalyst/common-lib/pkg/module/version"
 	"github.com/jackc/pgx/v5"
 )
 
+// Migrations holds the SQL migrations creating, or upgrading, the tables the storage uses,
+// in MigrationsDir. Apply them with Storage.Migrate or database.Client.Migrate.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// MigrationsDir is the directory within Migrations holding the migrations
+const MigrationsDir = "migrations"
+
 // Storage implements the storage.Storage interface using PostgreSQL
 type Storage struct {
-	db      *database.Client
-	metrics *metrics.Reporter
+	db              *database.Client
+	metrics         *metrics.Reporter
+	observers       storage.Observers
+	verifyChecksums bool
 }
 
 // Config represents PostgreSQL storage configuration
 type Config struct {
 	DBConfig      database.Config
 	MetricsPrefix string
+	// VerifyChecksums makes content reads fail with an ErrIntegrity error when
+	// the content does not match the SHA-256 recorded when it was stored
+	VerifyChecksums bool
 }
 
 // New creates a new PostgreSQL storage instance
@@ -33,51 +52,86 @@ func New(config Config, metrics *metrics.Reporter) (*Storage, error) {
 	}
 
 	return &Storage{
-		db:      db,
-		metrics: metrics,
+		db:              db,
+		metrics:         metrics,
+		verifyChecksums: config.VerifyChecksums,
 	}, nil
 }
 
-// Store saves a module to PostgreSQL
-func (s *Storage) Store(ctx context.Context, module *module.Module) error {
-	query := `
-		INSERT INTO modules (
-			id, name, provider, version, description, source,
-			variables, outputs, dependencies, tags,
-			created_at, updated_at, metadata, content
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
-		)
-		ON CONFLICT (id, version) DO UPDATE SET
-			name = EXCLUDED.name,
-			description = EXCLUDED.description,
-			source = EXCLUDED.source,
-			variables = EXCLUDED.variables,
-			outputs = EXCLUDED.outputs,
-			dependencies = EXCLUDED.dependencies,
-			tags = EXCLUDED.tags,
-			updated_at = EXCLUDED.updated_at,
-			metadata = EXCLUDED.metadata,
-			content = EXCLUDED.content
-		WHERE NOT modules.locked
-	`
+// Migrate applies any pending Migrations to the storage's database
+func (s *Storage) Migrate(ctx context.Context) error {
+	if err := s.db.Migrate(ctx, Migrations, MigrationsDir); err != nil {
+		return fmt.Errorf("failed to migrate module storage: %w", err)
+	}
+	return nil
+}
+
+// AddObserver registers an observer notified asynchronously after each successful mutation
+func (s *Storage) AddObserver(observer storage.StorageObserver) {
+	s.observers.Add(observer)
+}
 
+// storeQuery upserts a module version, leaving locked versions untouched
+const storeQuery = `
+	INSERT INTO modules (
+		id, name, provider, version, description, source,
+		variables, outputs, dependencies, tags,
+		created_at, updated_at, metadata, content, signature
+	) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+	)
+	ON CONFLICT (id, version) DO UPDATE SET
+		name = EXCLUDED.name,
+		description = EXCLUDED.description,
+		source = EXCLUDED.source,
+		variables = EXCLUDED.variables,
+		outputs = EXCLUDED.outputs,
+		dependencies = EXCLUDED.dependencies,
+		tags = EXCLUDED.tags,
+		updated_at = EXCLUDED.updated_at,
+		metadata = EXCLUDED.metadata,
+		content = EXCLUDED.content,
+		content_sha256 = NULL,
+		signature = EXCLUDED.signature,
+		revision = modules.revision + 1,
+		deleted_at = NULL
+	WHERE NOT modules.locked
+`
+
+// clearContentQuery drops the content chunks of a module version that storeQuery
+// just replaced. Locked versions, which storeQuery leaves untouched, keep theirs.
+const clearContentQuery = `
+	DELETE FROM module_content_chunks c
+	USING modules m
+	WHERE c.module_id = $1 AND c.module_version = $2
+		AND m.id = c.module_id AND m.version = c.module_version AND NOT m.locked
+`
+
+// deleteQuery soft-deletes a module version unless it is locked
+const deleteQuery = `
+	UPDATE modules
+	SET deleted_at = $3
+	WHERE id = $1 AND version = $2 AND NOT locked AND deleted_at IS NULL
+`
+
+// storeArgs returns the storeQuery arguments for a module
+func storeArgs(module *module.Module) ([]interface{}, error) {
 	variables, err := json.Marshal(module.Variables)
 	if err != nil {
-		return fmt.Errorf("failed to marshal variables: %w", err)
+		return nil, fmt.Errorf("failed to marshal variables: %w", err)
 	}
 
 	outputs, err := json.Marshal(module.Outputs)
 	if err != nil {
-		return fmt.Errorf("failed to marshal outputs: %w", err)
+		return nil, fmt.Errorf("failed to marshal outputs: %w", err)
 	}
 
 	dependencies, err := json.Marshal(module.Dependencies)
 	if err != nil {
-		return fmt.Errorf("failed to marshal dependencies: %w", err)
+		return nil, fmt.Errorf("failed to marshal dependencies: %w", err)
 	}
 
-	_, err = s.db.Exec(ctx, query,
+	return []interface{}{
 		module.ID,
 		module.Name,
 		module.Provider,
@@ -92,12 +146,86 @@ func (s *Storage) Store(ctx context.Context, module *module.Module) error {
 		module.UpdatedAt,
 		module.Metadata,
 		nil, // content is stored separately
-	)
+		nullable(module.Signature),
+	}, nil
+}
 
+// Store saves a module to PostgreSQL
+func (s *Storage) Store(ctx context.Context, module *module.Module) error {
+	args, err := storeArgs(module)
+	if err != nil {
+		return err
+	}
+
+	var stored bool
+	err = s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, storeQuery, args...)
+		if err != nil {
+			return err
+		}
+		// Locked versions are left untouched
+		stored = result.RowsAffected() > 0
+		if !stored {
+			return nil
+		}
+		_, err = tx.Exec(ctx, clearContentQuery, module.ID, module.Version)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to store module: %w", err)
 	}
 
+	if stored {
+		s.observers.Notify(ctx, storage.EventStore, module.ID, module.Version)
+	}
+
+	return nil
+}
+
+// StoreBatch saves modules in a single transaction, storing none of them if any
+// fails. As with Store, locked versions are left untouched and not notified.
+func (s *Storage) StoreBatch(ctx context.Context, modules []*module.Module) error {
+	if len(modules) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, mod := range modules {
+		args, err := storeArgs(mod)
+		if err != nil {
+			return fmt.Errorf("module %s@%s: %w", mod.ID, mod.Version, err)
+		}
+		batch.Queue(storeQuery, args...)
+		batch.Queue(clearContentQuery, mod.ID, mod.Version)
+	}
+
+	var stored []*module.Module
+	err := s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		results := tx.SendBatch(ctx, batch)
+		defer results.Close()
+
+		for _, mod := range modules {
+			result, err := results.Exec()
+			if err != nil {
+				return err
+			}
+			if result.RowsAffected() > 0 {
+				stored = append(stored, mod)
+			}
+			if _, err := results.Exec(); err != nil {
+				return err
+			}
+		}
+		return results.Close()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store modules: %w", err)
+	}
+
+	for _, mod := range stored {
+		s.observers.Notify(ctx, storage.EventStore, mod.ID, mod.Version)
+	}
+
 	return nil
 }
 
@@ -107,15 +235,16 @@ func (s *Storage) Get(ctx context.Context, id, version string) (*module.Module,
 		SELECT
 			id, name, provider, version, description, source,
 			variables, outputs, dependencies, tags,
-			created_at, updated_at, metadata
+			created_at, updated_at, metadata, revision, signature
 		FROM modules
-		WHERE id = $1 AND version = $2
+		WHERE id = $1 AND version = $2 AND deleted_at IS NULL
 	`
 
 	row := s.db.QueryRow(ctx, query, id, version)
 	module := &module.Module{}
 
 	var variables, outputs, dependencies []byte
+	var signature *string
 
 	err := row.Scan(
 		&module.ID,
@@ -131,10 +260,12 @@ func (s *Storage) Get(ctx context.Context, id, version string) (*module.Module,
 		&module.CreatedAt,
 		&module.UpdatedAt,
 		&module.Metadata,
+		&module.Revision,
+		&signature,
 	)
 
 	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("module not found: %s@%s", id, version)
+		return nil, notFound("module not found", id, version)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan module: %w", err)
@@ -152,18 +283,28 @@ func (s *Storage) Get(ctx context.Context, id, version string) (*module.Module,
 		return nil, fmt.Errorf("failed to unmarshal dependencies: %w", err)
 	}
 
+	if signature != nil {
+		module.Signature = *signature
+	}
+
 	return module, nil
 }
 
-// List returns modules matching the given filter
+// List returns modules matching the given filter, newest first. Empty filter
+// fields match everything.
 func (s *Storage) List(ctx context.Context, filter storage.Filter) ([]*module.Module, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query modules: %w", err)
+	}
+
 	query := `
 		SELECT
 			id, name, provider, version, description, source,
 			variables, outputs, dependencies, tags,
-			created_at, updated_at, metadata
+			created_at, updated_at, metadata, revision, signature
 		FROM modules
-		WHERE ($1::text IS NULL OR provider = $1)
+		WHERE deleted_at IS NULL
+		AND ($1::text IS NULL OR provider = $1)
 		AND ($2::text[] IS NULL OR tags && $2)
 		AND ($3::text IS NULL OR name LIKE $3)
 		AND ($4::text IS NULL OR version = $4)
@@ -171,14 +312,7 @@ func (s *Storage) List(ctx context.Context, filter storage.Filter) ([]*module.Mo
 		OFFSET $5 LIMIT $6
 	`
 
-	rows, err := s.db.Query(ctx, query,
-		filter.Provider,
-		filter.Tags,
-		filter.NamePattern,
-		filter.Version,
-		filter.Offset,
-		filter.Limit,
-	)
+	rows, err := s.db.Query(ctx, query, listArgs(filter)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query modules: %w", err)
 	}
@@ -189,6 +323,7 @@ func (s *Storage) List(ctx context.Context, filter storage.Filter) ([]*module.Mo
 	for rows.Next() {
 		module := &module.Module{}
 		var variables, outputs, dependencies []byte
+		var signature *string
 
 		err := rows.Scan(
 			&module.ID,
@@ -204,6 +339,8 @@ func (s *Storage) List(ctx context.Context, filter storage.Filter) ([]*module.Mo
 			&module.CreatedAt,
 			&module.UpdatedAt,
 			&module.Metadata,
+			&module.Revision,
+			&signature,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan module: %w", err)
@@ -221,30 +358,186 @@ func (s *Storage) List(ctx context.Context, filter storage.Filter) ([]*module.Mo
 			return nil, fmt.Errorf("failed to unmarshal dependencies: %w", err)
 		}
 
+		if signature != nil {
+			module.Signature = *signature
+		}
+
 		modules = append(modules, module)
 	}
 
 	return modules, nil
 }
 
-// Delete removes a module from storage
+// listArgs returns the List query arguments, passing NULL for unset filter
+// fields so they match every module. A zero Limit returns all matches.
+func listArgs(filter storage.Filter) []interface{} {
+	var tags []string
+	if len(filter.Tags) > 0 {
+		tags = filter.Tags
+	}
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+	return []interface{}{
+		nullable(filter.Provider),
+		tags,
+		nullable(filter.NamePattern),
+		nullable(filter.Version),
+		filter.Offset,
+		limit,
+	}
+}
+
+// nullable returns nil for an empty string so it is sent as NULL
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// notFound returns an ErrNotFound error for a module version
+func notFound(message, id, version string) *storage.Error {
+	return &storage.Error{Code: storage.ErrNotFound, Message: fmt.Sprintf("%s: %s@%s", message, id, version)}
+}
+
+// lockedError returns an ErrConflict error for a write to a locked module version
+func lockedError(id, version string) *storage.Error {
+	return &storage.Error{Code: storage.ErrConflict, Message: fmt.Sprintf("module locked: %s@%s", id, version)}
+}
+
+// writeError explains why a write to a live, unlocked module version matched
+// no rows: an ErrNotFound error if the version is missing or deleted, and an
+// ErrConflict error if it is locked
+func writeError(ctx context.Context, tx pgx.Tx, id, version string) error {
+	var locked bool
+	err := tx.QueryRow(ctx, `SELECT locked FROM modules WHERE id = $1 AND version = $2 AND deleted_at IS NULL`, id, version).Scan(&locked)
+	if err == pgx.ErrNoRows {
+		return notFound("module not found", id, version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check module: %w", err)
+	}
+	if locked {
+		return lockedError(id, version)
+	}
+	// The version was deleted and restored in between
+	return &storage.Error{Code: storage.ErrConflict, Message: fmt.Sprintf("module modified concurrently: %s@%s", id, version)}
+}
+
+// writeVersion runs a write to one live, unlocked module version, returning
+// writeError's explanation if it matched no rows. The write and the lookup run
+// in one transaction so both go to the primary even when reads are routed to
+// replicas.
+func (s *Storage) writeVersion(ctx context.Context, op, id, version, query string, args ...interface{}) error {
+	err := s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return writeError(ctx, tx, id, version)
+		}
+		return nil
+	})
+	var storageErr *storage.Error
+	if errors.As(err, &storageErr) {
+		return storageErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", op, err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a module, hiding it until it is restored or purged
 func (s *Storage) Delete(ctx context.Context, id, version string) error {
-	query := `DELETE FROM modules WHERE id = $1 AND version = $2 AND NOT locked`
+	if err := s.writeVersion(ctx, "delete module", id, version, deleteQuery, id, version, time.Now()); err != nil {
+		return err
+	}
+
+	s.observers.Notify(ctx, storage.EventDelete, id, version)
+	return nil
+}
+
+// DeleteBatch soft-deletes modules in a single transaction, deleting none of them
+// if any is missing or locked
+func (s *Storage) DeleteBatch(ctx context.Context, modules []*module.Module) error {
+	if len(modules) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	batch := &pgx.Batch{}
+	for _, mod := range modules {
+		batch.Queue(deleteQuery, mod.ID, mod.Version, now)
+	}
+
+	err := s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		results := tx.SendBatch(ctx, batch)
+		defer results.Close()
+
+		for _, mod := range modules {
+			result, err := results.Exec()
+			if err != nil {
+				return err
+			}
+			if result.RowsAffected() == 0 {
+				// The batch must be closed before the transaction can be queried
+				if err := results.Close(); err != nil {
+					return err
+				}
+				return writeError(ctx, tx, mod.ID, mod.Version)
+			}
+		}
+		return results.Close()
+	})
+	var storageErr *storage.Error
+	if errors.As(err, &storageErr) {
+		return storageErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete modules: %w", err)
+	}
+
+	for _, mod := range modules {
+		s.observers.Notify(ctx, storage.EventDelete, mod.ID, mod.Version)
+	}
+
+	return nil
+}
+
+// Restore undoes a soft delete
+func (s *Storage) Restore(ctx context.Context, id, version string) error {
+	query := `UPDATE modules SET deleted_at = NULL WHERE id = $1 AND version = $2 AND deleted_at IS NOT NULL`
 	result, err := s.db.Exec(ctx, query, id, version)
 	if err != nil {
-		return fmt.Errorf("failed to delete module: %w", err)
+		return fmt.Errorf("failed to restore module: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("module not found or locked: %s@%s", id, version)
+		return notFound("deleted module not found", id, version)
 	}
 
+	s.observers.Notify(ctx, storage.EventRestore, id, version)
 	return nil
 }
 
-// GetVersions returns all versions of a module
+// Purge permanently removes modules soft-deleted more than olderThan ago,
+// returning the number of versions removed
+func (s *Storage) Purge(ctx context.Context, olderThan time.Duration) (int, error) {
+	query := `DELETE FROM modules WHERE deleted_at < $1`
+	result, err := s.db.Exec(ctx, query, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge modules: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// GetVersions returns all versions of a module, newest first by semantic version
 func (s *Storage) GetVersions(ctx context.Context, id string) ([]string, error) {
-	query := `SELECT version FROM modules WHERE id = $1 ORDER BY version DESC`
+	query := `SELECT version FROM modules WHERE id = $1 AND deleted_at IS NULL`
 	rows, err := s.db.Query(ctx, query, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query versions: %w", err)
@@ -260,35 +553,50 @@ func (s *Storage) GetVersions(ctx context.Context, id string) ([]string, error)
 		versions = append(versions, version)
 	}
 
-	return versions, nil
+	return moduleversion.SortDescending(versions), nil
 }
 
 // GetLatestVersion returns the latest version of a module
 func (s *Storage) GetLatestVersion(ctx context.Context, id string) (string, error) {
-	query := `SELECT version FROM modules WHERE id = $1 ORDER BY version DESC LIMIT 1`
-	var version string
-	err := s.db.QueryRow(ctx, query, id).Scan(&version)
-	if err == pgx.ErrNoRows {
-		return "", fmt.Errorf("module not found: %s", id)
-	}
+	versions, err := s.GetVersions(ctx, id)
 	if err != nil {
 		return "", fmt.Errorf("failed to get latest version: %w", err)
 	}
-	return version, nil
+
+	latest, err := moduleversion.Latest(versions)
+	if err != nil {
+		return "", &storage.Error{Code: storage.ErrNotFound, Message: "module not found: " + id, Err: err}
+	}
+	return latest, nil
+}
+
+// GetLatestStableVersion returns the latest non-prerelease version of a module
+func (s *Storage) GetLatestStableVersion(ctx context.Context, id string) (string, error) {
+	versions, err := s.GetVersions(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to get latest stable version: %w", err)
+	}
+
+	latest, err := moduleversion.LatestStable(versions)
+	if err != nil {
+		return "", &storage.Error{Code: storage.ErrNotFound, Message: "no stable version found: " + id, Err: err}
+	}
+	return latest, nil
 }
 
 // Lock marks a version as immutable
 func (s *Storage) Lock(ctx context.Context, id, version string) error {
-	query := `UPDATE modules SET locked = true WHERE id = $1 AND version = $2`
+	query := `UPDATE modules SET locked = true WHERE id = $1 AND version = $2 AND deleted_at IS NULL`
 	result, err := s.db.Exec(ctx, query, id, version)
 	if err != nil {
 		return fmt.Errorf("failed to lock module: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("module not found: %s@%s", id, version)
+		return notFound("module not found", id, version)
 	}
 
+	s.observers.Notify(ctx, storage.EventLock, id, version)
 	return nil
 }
 
@@ -301,57 +609,243 @@ func (s *Storage) GetMetadata(ctx context.Context, id, version string) (*module.
 func (s *Storage) UpdateMetadata(ctx context.Context, id, version string, metadata map[string]interface{}) error {
 	query := `
 		UPDATE modules
-		SET metadata = $3, updated_at = $4
-		WHERE id = $1 AND version = $2 AND NOT locked
+		SET metadata = $3, updated_at = $4, revision = revision + 1
+		WHERE id = $1 AND version = $2 AND NOT locked AND deleted_at IS NULL
 	`
-	result, err := s.db.Exec(ctx, query, id, version, metadata, time.Now())
-	if err != nil {
-		return fmt.Errorf("failed to update metadata: %w", err)
-	}
-
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("module not found or locked: %s@%s", id, version)
+	if err := s.writeVersion(ctx, "update metadata", id, version, query, id, version, metadata, time.Now()); err != nil {
+		return err
 	}
 
+	s.observers.Notify(ctx, storage.EventUpdate, id, version)
 	return nil
 }
 
-// StoreContent saves module content to storage
-func (s *Storage) StoreContent(ctx context.Context, id, version string, content []byte) error {
+// UpdateMetadataIfUnchanged updates module metadata only if the stored revision
+// still equals expectedRev, so concurrent updaters cannot overwrite each other
+func (s *Storage) UpdateMetadataIfUnchanged(ctx context.Context, id, version string, expectedRev int, metadata map[string]interface{}) (int, error) {
 	query := `
 		UPDATE modules
-		SET content = $3, updated_at = $4
-		WHERE id = $1 AND version = $2 AND NOT locked
+		SET metadata = $3, updated_at = $4, revision = revision + 1
+		WHERE id = $1 AND version = $2 AND revision = $5 AND NOT locked AND deleted_at IS NULL
+		RETURNING revision
 	`
-	result, err := s.db.Exec(ctx, query, id, version, content, time.Now())
+	// Both statements run in a transaction so they go to the primary even when
+	// reads are routed to replicas
+	var newRev int
+	err := s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, id, version, metadata, time.Now(), expectedRev).Scan(&newRev)
+		if err != pgx.ErrNoRows {
+			return err
+		}
+
+		// Nothing was updated; work out why
+		var currentRev int
+		var locked bool
+		err = tx.QueryRow(ctx, `SELECT revision, locked FROM modules WHERE id = $1 AND version = $2 AND deleted_at IS NULL`, id, version).Scan(&currentRev, &locked)
+		if err == pgx.ErrNoRows {
+			return notFound("module not found", id, version)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check revision: %w", err)
+		}
+		if locked {
+			return lockedError(id, version)
+		}
+		return &storage.Error{
+			Code:    storage.ErrConflict,
+			Message: fmt.Sprintf("module %s@%s was modified: expected revision %d, found %d", id, version, expectedRev, currentRev),
+		}
+	})
+	var storageErr *storage.Error
+	if errors.As(err, &storageErr) {
+		return 0, storageErr
+	}
 	if err != nil {
-		return fmt.Errorf("failed to store content: %w", err)
+		return 0, fmt.Errorf("failed to update metadata: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("module not found or locked: %s@%s", id, version)
-	}
+	s.observers.Notify(ctx, storage.EventUpdate, id, version)
+	return newRev, nil
+}
 
-	return nil
+// contentChunkSize is the size of the chunks module content is stored in
+const contentChunkSize = 1 << 20
+
+// StoreContent saves module content to storage
+func (s *Storage) StoreContent(ctx context.Context, id, version string, content []byte) error {
+	return s.StoreContentStream(ctx, id, version, bytes.NewReader(content))
 }
 
 // GetContent retrieves module content from storage
 func (s *Storage) GetContent(ctx context.Context, id, version string) ([]byte, error) {
-	query := `SELECT content FROM modules WHERE id = $1 AND version = $2`
-	var content []byte
-	err := s.db.QueryRow(ctx, query, id, version).Scan(&content)
-	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("module not found: %s@%s", id, version)
+	r, err := s.GetContentStream(ctx, id, version)
+	if err != nil {
+		return nil, err
 	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get content: %w", err)
 	}
 	return content, nil
 }
 
+// StoreContentStream saves module content read from r as a sequence of chunks,
+// replacing any previous content in a single transaction
+func (s *Storage) StoreContentStream(ctx context.Context, id, version string, r io.Reader) error {
+	err := s.db.WithTx(ctx, func(tx pgx.Tx) error {
+		query := `
+			UPDATE modules
+			SET content = NULL, content_sha256 = NULL, updated_at = $3, revision = revision + 1
+			WHERE id = $1 AND version = $2 AND NOT locked AND deleted_at IS NULL
+		`
+		result, err := tx.Exec(ctx, query, id, version, time.Now())
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return writeError(ctx, tx, id, version)
+		}
+
+		query = `DELETE FROM module_content_chunks WHERE module_id = $1 AND module_version = $2`
+		if _, err := tx.Exec(ctx, query, id, version); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO module_content_chunks (module_id, module_version, seq, data) VALUES ($1, $2, $3, $4)`
+		content := storage.NewChecksumReader(r)
+		buf := make([]byte, contentChunkSize)
+		for seq := 0; ; seq++ {
+			n, err := io.ReadFull(content, buf)
+			if n > 0 {
+				if _, err := tx.Exec(ctx, query, id, version, seq, buf[:n]); err != nil {
+					return err
+				}
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read content: %w", err)
+			}
+		}
+
+		query = `UPDATE modules SET content_sha256 = $3 WHERE id = $1 AND version = $2`
+		_, err = tx.Exec(ctx, query, id, version, content.Sum())
+		return err
+	})
+	var storageErr *storage.Error
+	if errors.As(err, &storageErr) {
+		return storageErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store content: %w", err)
+	}
+
+	s.observers.Notify(ctx, storage.EventUpdate, id, version)
+	return nil
+}
+
+// GetContentStream returns a reader that fetches module content one chunk at a
+// time. Content stored before chunking was introduced is read from the modules
+// table. With VerifyChecksums set, the reader fails at the end of the content
+// if it does not match its recorded checksum.
+func (s *Storage) GetContentStream(ctx context.Context, id, version string) (io.ReadCloser, error) {
+	query := `
+		SELECT m.content, m.content_sha256, (
+			SELECT COUNT(*) FROM module_content_chunks c
+			WHERE c.module_id = m.id AND c.module_version = m.version
+		)
+		FROM modules m
+		WHERE m.id = $1 AND m.version = $2 AND m.deleted_at IS NULL
+	`
+	var legacy []byte
+	var checksum *string
+	var chunks int
+	err := s.db.QueryRow(ctx, query, id, version).Scan(&legacy, &checksum, &chunks)
+	if err == pgx.ErrNoRows {
+		return nil, notFound("module not found", id, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get content: %w", err)
+	}
+
+	var r io.ReadCloser
+	if chunks == 0 {
+		r = io.NopCloser(bytes.NewReader(legacy))
+	} else {
+		r = &chunkReader{ctx: ctx, db: s.db, id: id, version: version, chunks: chunks}
+	}
+
+	// Content stored before checksums were recorded cannot be verified
+	if s.verifyChecksums && checksum != nil {
+		r = storage.NewVerifyingReader(r, id, version, *checksum)
+	}
+	return r, nil
+}
+
+// GetContentChecksum returns the SHA-256 recorded when the module content was stored
+func (s *Storage) GetContentChecksum(ctx context.Context, id, version string) (string, error) {
+	query := `SELECT content_sha256 FROM modules WHERE id = $1 AND version = $2 AND deleted_at IS NULL`
+	var checksum *string
+	err := s.db.QueryRow(ctx, query, id, version).Scan(&checksum)
+	if err == pgx.ErrNoRows {
+		return "", notFound("module not found", id, version)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get content checksum: %w", err)
+	}
+	if checksum == nil {
+		return "", &storage.Error{Code: storage.ErrNotFound, Message: fmt.Sprintf("no content stored for %s@%s", id, version)}
+	}
+	return *checksum, nil
+}
+
+// chunkReader reads module content chunks in sequence
+type chunkReader struct {
+	ctx     context.Context
+	db      *database.Client
+	id      string
+	version string
+	chunks  int
+	next    int
+	buf     []byte
+}
+
+// Read implements io.Reader
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.next >= r.chunks {
+			return 0, io.EOF
+		}
+
+		query := `SELECT data FROM module_content_chunks WHERE module_id = $1 AND module_version = $2 AND seq = $3`
+		err := r.db.QueryRow(r.ctx, query, r.id, r.version, r.next).Scan(&r.buf)
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("content of %s@%s changed while reading", r.id, r.version)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read content chunk %d: %w", r.next, err)
+		}
+		r.next++
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close implements io.Closer
+func (r *chunkReader) Close() error {
+	r.buf = nil
+	r.next = r.chunks
+	return nil
+}
+
 // Exists checks if a module version exists
 func (s *Storage) Exists(ctx context.Context, id, version string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM modules WHERE id = $1 AND version = $2)`
+	query := `SELECT EXISTS(SELECT 1 FROM modules WHERE id = $1 AND version = $2 AND deleted_at IS NULL)`
 	var exists bool
 	err := s.db.QueryRow(ctx, query, id, version).Scan(&exists)
 	if err != nil {
@@ -362,15 +856,22 @@ func (s *Storage) Exists(ctx context.Context, id, version string) (bool, error)
 
 // GetDependencies returns all modules that depend on the given module
 func (s *Storage) GetDependencies(ctx context.Context, id, version string) ([]*module.Module, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query dependencies: %w", err)
+	}
+
 	query := `
 		SELECT
 			id, name, provider, version, description, source,
 			variables, outputs, dependencies, tags,
-			created_at, updated_at, metadata
+			created_at, updated_at, metadata, revision, signature
 		FROM modules
-		WHERE dependencies @> $1
+		WHERE dependencies @> $1::jsonb AND deleted_at IS NULL
 	`
-	dependency := fmt.Sprintf(`[{"source": "%s", "version": "%s"}]`, id, version)
+	dependency, err := dependencyFilter(id, version)
+	if err != nil {
+		return nil, err
+	}
 
 	rows, err := s.db.Query(ctx, query, dependency)
 	if err != nil {
@@ -382,6 +883,7 @@ func (s *Storage) GetDependencies(ctx context.Context, id, version string) ([]*m
 	for rows.Next() {
 		module := &module.Module{}
 		var variables, outputs, dependencies []byte
+		var signature *string
 
 		err := rows.Scan(
 			&module.ID,
@@ -397,6 +899,8 @@ func (s *Storage) GetDependencies(ctx context.Context, id, version string) ([]*m
 			&module.CreatedAt,
 			&module.UpdatedAt,
 			&module.Metadata,
+			&module.Revision,
+			&signature,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan module: %w", err)
@@ -414,14 +918,115 @@ func (s *Storage) GetDependencies(ctx context.Context, id, version string) ([]*m
 			return nil, fmt.Errorf("failed to unmarshal dependencies: %w", err)
 		}
 
+		if signature != nil {
+			module.Signature = *signature
+		}
+
 		modules = append(modules, module)
 	}
 
 	return modules, nil
 }
 
-// Close releases any resources held by the storage
+// dependencyFilter builds the JSON containment filter matching a dependency on
+// id at version. Values are JSON-encoded so quotes cannot alter the filter.
+func dependencyFilter(id, version string) (string, error) {
+	data, err := json.Marshal([]map[string]string{{"source": id, "version": version}})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal dependency filter: %w", err)
+	}
+	return string(data), nil
+}
+
+// ListProviders returns the distinct providers of all stored modules, sorted by name
+func (s *Storage) ListProviders(ctx context.Context) ([]string, error) {
+	query := `SELECT DISTINCT provider FROM modules WHERE deleted_at IS NULL ORDER BY provider`
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query providers: %w", err)
+	}
+	defer rows.Close()
+
+	var providers []string
+	for rows.Next() {
+		var provider string
+		if err := rows.Scan(&provider); err != nil {
+			return nil, fmt.Errorf("failed to scan provider: %w", err)
+		}
+		providers = append(providers, provider)
+	}
+
+	return providers, rows.Err()
+}
+
+// ListTags returns every tag with the number of modules carrying it, most used first.
+// A module is counted once per tag regardless of how many of its versions carry it.
+func (s *Storage) ListTags(ctx context.Context) ([]storage.TagCount, error) {
+	query := `
+		SELECT tag, COUNT(DISTINCT id)
+		FROM modules, unnest(tags) AS tag
+		WHERE deleted_at IS NULL
+		GROUP BY tag
+		ORDER BY COUNT(DISTINCT id) DESC, tag
+	`
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []storage.TagCount
+	for rows.Next() {
+		var tag storage.TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// Stats returns aggregate statistics about the stored modules
+func (s *Storage) Stats(ctx context.Context) (*storage.Stats, error) {
+	query := `
+		SELECT
+			COUNT(DISTINCT id),
+			COUNT(*),
+			COALESCE(SUM(OCTET_LENGTH(content)), 0) + COALESCE((
+				SELECT SUM(OCTET_LENGTH(c.data))
+				FROM module_content_chunks c
+				JOIN modules m ON m.id = c.module_id AND m.version = c.module_version
+				WHERE m.deleted_at IS NULL
+			), 0),
+			MAX(updated_at)
+		FROM modules
+		WHERE deleted_at IS NULL
+	`
+
+	stats := &storage.Stats{}
+	var lastUpdated *time.Time
+
+	err := s.db.QueryRow(ctx, query).Scan(
+		&stats.TotalModules,
+		&stats.TotalVersions,
+		&stats.StorageSize,
+		&lastUpdated,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage stats: %w", err)
+	}
+
+	if lastUpdated != nil {
+		stats.LastUpdated = *lastUpdated
+	}
+
+	return stats, nil
+}
+
+// Close waits for pending observer notifications and releases any resources held by the storage
 func (s *Storage) Close() error {
+	s.observers.Wait()
 	if s.db != nil {
 		s.db.Close()
 	}
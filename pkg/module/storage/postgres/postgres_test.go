@@ -0,0 +1,683 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/database"
+	"github.com/StackCatalyst/common-lib/pkg/metrics"
+	"github.com/StackCatalyst/common-lib/pkg/module"
+	"github.com/StackCatalyst/common-lib/pkg/module/storage"
+	"github.com/StackCatalyst/common-lib/pkg/module/storage/storagetest"
+	moduleversion "github.com/StackCatalyst/common-lib/pkg/module/version"
+	commontesting "github.com/StackCatalyst/common-lib/pkg/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func isDockerAvailable() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx, "docker", "info").Run() == nil
+}
+
+// newTestStorage starts a Postgres container and applies the storage migrations
+func newTestStorage(t *testing.T) *Storage {
+	if testing.Short() {
+		t.Skip("Skipping container tests in short mode")
+	}
+	if !isDockerAvailable() {
+		t.Skip("Docker is not available")
+	}
+
+	ctx := context.Background()
+	container, err := commontesting.PostgresContainer(ctx, commontesting.PostgresConfig{
+		Database: "modules",
+		User:     "test",
+		Password: "test",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { container.Stop(ctx) })
+
+	host, err := container.GetHost(ctx)
+	require.NoError(t, err)
+	mappedPort, err := container.GetHostPort(ctx, "5432/tcp")
+	require.NoError(t, err)
+	port, err := strconv.Atoi(mappedPort)
+	require.NoError(t, err)
+
+	dbConfig := database.DefaultConfig()
+	dbConfig.Host = host
+	dbConfig.Port = port
+	dbConfig.Database = "modules"
+	dbConfig.User = "test"
+	dbConfig.Password = "test"
+
+	reporter := metrics.New(metrics.Options{
+		Namespace: "test",
+		Subsystem: "module_storage",
+		Registry:  prometheus.NewRegistry(),
+	})
+	s, err := New(Config{DBConfig: dbConfig}, reporter)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	require.NoError(t, s.Migrate(ctx))
+	return s
+}
+
+func TestMigrations(t *testing.T) {
+	for _, file := range []string{"0001_create_modules.sql", "0002_upgrade_modules.sql"} {
+		data, err := fs.ReadFile(Migrations, path.Join(MigrationsDir, file))
+		require.NoError(t, err)
+
+		// Columns and tables the queries rely on
+		for _, name := range []string{"content_sha256", "signature", "locked", "revision", "deleted_at", "module_content_chunks"} {
+			assert.Contains(t, string(data), name, file)
+		}
+		assert.Equal(t, strings.Count(string(data), "CREATE TABLE "), strings.Count(string(data), "CREATE TABLE IF NOT EXISTS "), file)
+	}
+}
+
+func TestMigrateLegacySchema(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	// Recreate the table as it was before the storage shipped migrations
+	_, err := s.db.Exec(ctx, `
+		DROP TABLE module_content_chunks, modules, schema_migrations;
+		CREATE TABLE modules (
+			id           TEXT NOT NULL,
+			name         TEXT NOT NULL,
+			provider     TEXT NOT NULL,
+			version      TEXT NOT NULL,
+			description  TEXT,
+			source       TEXT,
+			variables    JSONB,
+			outputs      JSONB,
+			dependencies JSONB,
+			tags         TEXT[],
+			created_at   TIMESTAMPTZ NOT NULL,
+			updated_at   TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (id, version)
+		)
+	`)
+	require.NoError(t, err)
+	require.NoError(t, s.Migrate(ctx))
+
+	now := time.Now()
+	mod := &module.Module{ID: "legacy", Name: "legacy", Provider: "aws", Version: "1.0.0", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.Store(ctx, mod))
+	require.NoError(t, s.StoreContentStream(ctx, mod.ID, mod.Version, strings.NewReader("content")))
+	_, err = s.GetContentChecksum(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+}
+
+func TestShared(t *testing.T) {
+	t.Run("re-store", func(t *testing.T) {
+		storagetest.TestReStore(t, newTestStorage(t))
+	})
+}
+
+func TestFacets(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	for _, mod := range []*module.Module{
+		{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: "1.0.0", Tags: []string{"network", "vpc"}},
+		{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: "1.1.0", Tags: []string{"network", "vpc"}},
+		{ID: "aws-s3", Name: "s3", Provider: "aws", Version: "1.0.0", Tags: []string{"storage"}},
+		{ID: "gcp-vpc", Name: "vpc", Provider: "gcp", Version: "0.1.0", Tags: []string{"network"}},
+		{ID: "azure-vm", Name: "vm", Provider: "azure", Version: "2.0.0"},
+	} {
+		mod.CreatedAt = now
+		mod.UpdatedAt = now
+		require.NoError(t, s.Store(ctx, mod))
+	}
+
+	providers, err := s.ListProviders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws", "azure", "gcp"}, providers)
+
+	tags, err := s.ListTags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []storage.TagCount{
+		{Tag: "network", Count: 2},
+		{Tag: "storage", Count: 1},
+		{Tag: "vpc", Count: 1},
+	}, tags)
+}
+
+func TestUpdateMetadataIfUnchanged(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	mod := &module.Module{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: "1.0.0", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.Store(ctx, mod))
+
+	stored, err := s.Get(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stored.Revision)
+
+	// Two admins read the same revision
+	aliceRev, bobRev := stored.Revision, stored.Revision
+
+	newRev, err := s.UpdateMetadataIfUnchanged(ctx, mod.ID, mod.Version, aliceRev, map[string]interface{}{"owner": "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, newRev)
+
+	// Bob's write is based on a stale revision and must not clobber Alice's
+	_, err = s.UpdateMetadataIfUnchanged(ctx, mod.ID, mod.Version, bobRev, map[string]interface{}{"owner": "bob"})
+	require.Error(t, err)
+	var storageErr *storage.Error
+	require.ErrorAs(t, err, &storageErr)
+	assert.Equal(t, storage.ErrConflict, storageErr.Code)
+
+	stored, err = s.Get(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", stored.Metadata["owner"])
+	assert.Equal(t, 1, stored.Revision)
+
+	// Content writes bump the revision too
+	require.NoError(t, s.StoreContent(ctx, mod.ID, mod.Version, []byte("content")))
+	_, err = s.UpdateMetadataIfUnchanged(ctx, mod.ID, mod.Version, newRev, map[string]interface{}{"owner": "alice"})
+	require.ErrorAs(t, err, &storageErr)
+
+	_, err = s.UpdateMetadataIfUnchanged(ctx, "missing", "1.0.0", 0, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "module not found")
+}
+
+func TestSignedModule(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	verifier := moduleversion.NewManagerWithOptions(moduleversion.Options{PublicKey: publicKey})
+
+	now := time.Now()
+	mod := &module.Module{
+		ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: "1.0.0", Source: "github.com/acme/aws-vpc",
+		Variables: []*module.Variable{{Name: "cidr", Type: "string", Default: "10.0.0.0/16"}},
+		Tags:      []string{"network"},
+		CreatedAt: now, UpdatedAt: now,
+	}
+	require.NoError(t, moduleversion.Sign(mod, privateKey))
+	require.NoError(t, s.Store(ctx, mod))
+	require.NoError(t, s.UpdateMetadata(ctx, mod.ID, mod.Version, map[string]interface{}{"owner": "alice"}))
+
+	stored, err := s.Get(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, mod.Signature, stored.Signature)
+	assert.NoError(t, verifier.Verify(stored))
+
+	modules, err := s.List(ctx, storage.Filter{})
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+	assert.NoError(t, verifier.Verify(modules[0]))
+}
+
+func TestSoftDelete(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	mod := &module.Module{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: "1.0.0", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.Store(ctx, mod))
+
+	require.NoError(t, s.Delete(ctx, mod.ID, mod.Version))
+
+	_, err := s.Get(ctx, mod.ID, mod.Version)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "module not found")
+
+	exists, err := s.Exists(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	modules, err := s.List(ctx, storage.Filter{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, modules)
+
+	// Deleting twice fails since the module is already hidden
+	assert.Error(t, s.Delete(ctx, mod.ID, mod.Version))
+
+	require.NoError(t, s.Restore(ctx, mod.ID, mod.Version))
+
+	restored, err := s.Get(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, mod.ID, restored.ID)
+
+	// Restoring a live module fails
+	assert.Error(t, s.Restore(ctx, mod.ID, mod.Version))
+
+	// Locked modules cannot be deleted
+	require.NoError(t, s.Lock(ctx, mod.ID, mod.Version))
+	assert.Error(t, s.Delete(ctx, mod.ID, mod.Version))
+}
+
+func TestTypedErrors(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	// errorCode returns the code of the *storage.Error err must be
+	errorCode := func(t *testing.T, err error) string {
+		t.Helper()
+		require.Error(t, err)
+		storageErr, ok := err.(*storage.Error)
+		require.True(t, ok, "expected *storage.Error, got %T", err)
+		return storageErr.Code
+	}
+
+	now := time.Now()
+	mod := &module.Module{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: "1.0.0-beta", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.Store(ctx, mod))
+	missing := &module.Module{ID: "aws-vpc", Version: "9.9.9"}
+	metadata := map[string]interface{}{"owner": "alice"}
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := s.Get(ctx, missing.ID, missing.Version)
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, err))
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, s.Delete(ctx, missing.ID, missing.Version)))
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, s.DeleteBatch(ctx, []*module.Module{mod, missing})))
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, s.Restore(ctx, mod.ID, mod.Version)))
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, s.Lock(ctx, missing.ID, missing.Version)))
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, s.UpdateMetadata(ctx, missing.ID, missing.Version, metadata)))
+		_, err = s.UpdateMetadataIfUnchanged(ctx, missing.ID, missing.Version, 0, metadata)
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, err))
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, s.StoreContent(ctx, missing.ID, missing.Version, []byte("content"))))
+		_, err = s.GetContentStream(ctx, missing.ID, missing.Version)
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, err))
+		_, err = s.GetContentChecksum(ctx, missing.ID, missing.Version)
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, err))
+		_, err = s.GetContentChecksum(ctx, mod.ID, mod.Version)
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, err), "no content stored yet")
+		_, err = s.GetLatestVersion(ctx, "unknown")
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, err))
+		_, err = s.GetLatestStableVersion(ctx, mod.ID)
+		assert.Equal(t, storage.ErrNotFound, errorCode(t, err), "only a prerelease is stored")
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		require.NoError(t, s.Lock(ctx, mod.ID, mod.Version))
+
+		assert.Equal(t, storage.ErrConflict, errorCode(t, s.Delete(ctx, mod.ID, mod.Version)))
+		assert.Equal(t, storage.ErrConflict, errorCode(t, s.DeleteBatch(ctx, []*module.Module{mod})))
+		assert.Equal(t, storage.ErrConflict, errorCode(t, s.UpdateMetadata(ctx, mod.ID, mod.Version, metadata)))
+		_, err := s.UpdateMetadataIfUnchanged(ctx, mod.ID, mod.Version, 0, metadata)
+		assert.Equal(t, storage.ErrConflict, errorCode(t, err))
+		assert.Equal(t, storage.ErrConflict, errorCode(t, s.StoreContent(ctx, mod.ID, mod.Version, []byte("content"))))
+	})
+}
+
+func TestPurge(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	for _, version := range []string{"1.0.0", "1.1.0", "1.2.0"} {
+		mod := &module.Module{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: version, CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, s.Store(ctx, mod))
+	}
+	require.NoError(t, s.Delete(ctx, "aws-vpc", "1.0.0"))
+	require.NoError(t, s.Delete(ctx, "aws-vpc", "1.1.0"))
+
+	// Age one of the deletions past the retention window
+	_, err := s.db.Exec(ctx, `UPDATE modules SET deleted_at = $1 WHERE id = $2 AND version = $3`,
+		now.Add(-48*time.Hour), "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+
+	purged, err := s.Purge(ctx, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	// The recently deleted version can still be restored, the purged one cannot
+	require.NoError(t, s.Restore(ctx, "aws-vpc", "1.1.0"))
+	assert.Error(t, s.Restore(ctx, "aws-vpc", "1.0.0"))
+
+	versions, err := s.GetVersions(ctx, "aws-vpc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.2.0", "1.1.0"}, versions)
+}
+
+func TestContentStream(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	mod := &module.Module{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: "1.0.0", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.Store(ctx, mod))
+
+	// Spans several chunks, the last one partial
+	content := bytes.Repeat([]byte("0123456789"), (2*contentChunkSize+100)/10)
+	require.NoError(t, s.StoreContentStream(ctx, mod.ID, mod.Version, bytes.NewReader(content)))
+
+	r, err := s.GetContentStream(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	streamed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, content, streamed)
+
+	stats, err := s.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), stats.StorageSize)
+
+	// The byte-slice methods replace the chunks
+	require.NoError(t, s.StoreContent(ctx, mod.ID, mod.Version, []byte("small")))
+	stored, err := s.GetContent(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("small"), stored)
+
+	// Content stored before chunking is still readable
+	_, err = s.db.Exec(ctx, `DELETE FROM module_content_chunks`)
+	require.NoError(t, err)
+	_, err = s.db.Exec(ctx, `UPDATE modules SET content = $1`, []byte("legacy"))
+	require.NoError(t, err)
+	stored, err = s.GetContent(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("legacy"), stored)
+
+	_, err = s.GetContentStream(ctx, "missing", "1.0.0")
+	assert.Error(t, err)
+	require.NoError(t, s.Lock(ctx, mod.ID, mod.Version))
+	assert.Error(t, s.StoreContentStream(ctx, mod.ID, mod.Version, bytes.NewReader(content)))
+}
+
+func TestContentChecksum(t *testing.T) {
+	s := newTestStorage(t)
+	s.verifyChecksums = true
+	ctx := context.Background()
+
+	now := time.Now()
+	mod := &module.Module{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: "1.0.0", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.Store(ctx, mod))
+
+	_, err := s.GetContentChecksum(ctx, mod.ID, mod.Version)
+	assert.Error(t, err)
+
+	content := []byte("module content")
+	require.NoError(t, s.StoreContent(ctx, mod.ID, mod.Version, content))
+
+	checksum, err := s.GetContentChecksum(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, storage.Checksum(content), checksum)
+
+	stored, err := s.GetContent(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, content, stored)
+
+	// Corrupt the stored content behind the storage's back
+	_, err = s.db.Exec(ctx, `UPDATE module_content_chunks SET data = $1`, []byte("tampered"))
+	require.NoError(t, err)
+
+	_, err = s.GetContent(ctx, mod.ID, mod.Version)
+	var storageErr *storage.Error
+	require.ErrorAs(t, err, &storageErr)
+	assert.Equal(t, storage.ErrIntegrity, storageErr.Code)
+
+	s.verifyChecksums = false
+	stored, err = s.GetContent(ctx, mod.ID, mod.Version)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("tampered"), stored)
+}
+
+func TestStoreBatch(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	var modules []*module.Module
+	for _, version := range []string{"1.0.0", "1.1.0", "1.2.0"} {
+		modules = append(modules, &module.Module{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: version, CreatedAt: now, UpdatedAt: now})
+	}
+	require.NoError(t, s.StoreBatch(ctx, modules))
+
+	versions, err := s.GetVersions(ctx, "aws-vpc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.2.0", "1.1.0", "1.0.0"}, versions)
+
+	// A module that cannot be encoded rolls back the whole batch
+	err = s.StoreBatch(ctx, []*module.Module{
+		{ID: "gcp-vpc", Name: "vpc", Provider: "gcp", Version: "1.0.0", CreatedAt: now, UpdatedAt: now},
+		{ID: "gcp-vpc", Name: "vpc", Provider: "gcp", Version: "1.1.0", CreatedAt: now, UpdatedAt: now,
+			Metadata: map[string]interface{}{"invalid": make(chan int)}},
+	})
+	require.Error(t, err)
+
+	exists, err := s.Exists(ctx, "gcp-vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestDeleteBatch(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	var modules []*module.Module
+	for _, version := range []string{"1.0.0", "1.1.0", "1.2.0"} {
+		modules = append(modules, &module.Module{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: version, CreatedAt: now, UpdatedAt: now})
+	}
+	require.NoError(t, s.StoreBatch(ctx, modules))
+
+	// A missing module rolls back the whole batch
+	missing := &module.Module{ID: "aws-vpc", Version: "9.9.9"}
+	err := s.DeleteBatch(ctx, []*module.Module{modules[0], missing})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "aws-vpc@9.9.9")
+
+	exists, err := s.Exists(ctx, "aws-vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, s.DeleteBatch(ctx, modules[:2]))
+
+	versions, err := s.GetVersions(ctx, "aws-vpc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.2.0"}, versions)
+}
+
+// eventRecorder is a storage.StorageObserver recording received events
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) record(event storage.EventType, id, version string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, string(event)+":"+id+"@"+version)
+	return nil
+}
+
+func (r *eventRecorder) OnStore(ctx context.Context, id, version string) error {
+	return r.record(storage.EventStore, id, version)
+}
+
+func (r *eventRecorder) OnDelete(ctx context.Context, id, version string) error {
+	return r.record(storage.EventDelete, id, version)
+}
+
+func (r *eventRecorder) OnRestore(ctx context.Context, id, version string) error {
+	return r.record(storage.EventRestore, id, version)
+}
+
+func (r *eventRecorder) OnLock(ctx context.Context, id, version string) error {
+	return r.record(storage.EventLock, id, version)
+}
+
+func (r *eventRecorder) OnUpdate(ctx context.Context, id, version string) error {
+	return r.record(storage.EventUpdate, id, version)
+}
+
+func TestObservers(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	recorder := &eventRecorder{}
+	s.AddObserver(recorder)
+
+	now := time.Now()
+	mod := &module.Module{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: "1.0.0", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.Store(ctx, mod))
+	require.NoError(t, s.UpdateMetadata(ctx, mod.ID, mod.Version, map[string]interface{}{"owner": "alice"}))
+	require.NoError(t, s.Delete(ctx, mod.ID, mod.Version))
+	require.NoError(t, s.Restore(ctx, mod.ID, mod.Version))
+	require.NoError(t, s.Lock(ctx, mod.ID, mod.Version))
+
+	// Failed mutations are not reported
+	require.Error(t, s.Delete(ctx, mod.ID, mod.Version))
+
+	// Locked versions skipped by a batch are not reported as stored
+	next := &module.Module{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: "1.1.0", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.StoreBatch(ctx, []*module.Module{mod, next}))
+
+	s.observers.Wait()
+	assert.ElementsMatch(t, []string{
+		"store:aws-vpc@1.0.0",
+		"update:aws-vpc@1.0.0",
+		"delete:aws-vpc@1.0.0",
+		"restore:aws-vpc@1.0.0",
+		"lock:aws-vpc@1.0.0",
+		"store:aws-vpc@1.1.0",
+	}, recorder.events)
+}
+
+func TestDependencyFilter(t *testing.T) {
+	filter, err := dependencyFilter(`o'brien "vpc"`, `1.0.0"}]`)
+	require.NoError(t, err)
+
+	var decoded []map[string]string
+	require.NoError(t, json.Unmarshal([]byte(filter), &decoded))
+	assert.Equal(t, []map[string]string{{"source": `o'brien "vpc"`, "version": `1.0.0"}]`}}, decoded)
+}
+
+func TestCancelledContext(t *testing.T) {
+	// The pool connects lazily, so no database is needed to check early returns
+	dbConfig := database.DefaultConfig()
+	dbConfig.Database = "modules"
+	dbConfig.User = "test"
+	dbConfig.Password = "test"
+	reporter := metrics.New(metrics.Options{
+		Namespace: "test",
+		Subsystem: "module_storage",
+		Registry:  prometheus.NewRegistry(),
+	})
+	s, err := New(Config{DBConfig: dbConfig}, reporter)
+	require.NoError(t, err)
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	_, err = s.List(ctx, storage.Filter{})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = s.GetDependencies(ctx, "aws-vpc", "1.0.0")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestGetDependenciesQuotedID(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	quotedID := `o'brien "vpc"`
+	vpc := &module.Module{ID: quotedID, Name: "vpc", Provider: "aws", Version: "1.0.0", CreatedAt: now, UpdatedAt: now}
+	eks := &module.Module{ID: "aws-eks", Name: "eks", Provider: "aws", Version: "1.0.0", CreatedAt: now, UpdatedAt: now,
+		Dependencies: []*module.Dependency{{Name: "vpc", Source: quotedID, Version: "1.0.0"}}}
+	other := &module.Module{ID: "aws-rds", Name: "rds", Provider: "aws", Version: "1.0.0", CreatedAt: now, UpdatedAt: now,
+		Dependencies: []*module.Dependency{{Name: "vpc", Source: "o'brien", Version: "1.0.0"}}}
+	require.NoError(t, s.StoreBatch(ctx, []*module.Module{vpc, eks, other}))
+
+	dependents, err := s.GetDependencies(ctx, quotedID, "1.0.0")
+	require.NoError(t, err)
+	require.Len(t, dependents, 1)
+	assert.Equal(t, "aws-eks", dependents[0].ID)
+
+	// A crafted version cannot widen the match
+	dependents, err = s.GetDependencies(ctx, quotedID, `1.0.0"}, {"source": "aws-vpc`)
+	require.NoError(t, err)
+	assert.Empty(t, dependents)
+}
+
+func TestListArgs(t *testing.T) {
+	args := listArgs(storage.Filter{Tags: []string{}})
+	assert.Nil(t, args[0], "provider")
+	assert.Nil(t, args[1], "tags")
+	assert.Nil(t, args[2], "name pattern")
+	assert.Nil(t, args[3], "version")
+	assert.Equal(t, 0, args[4], "offset")
+	assert.Nil(t, args[5], "limit")
+
+	args = listArgs(storage.Filter{
+		Provider:    "aws",
+		Tags:        []string{"network"},
+		NamePattern: "%vpc",
+		Version:     "1.0.0",
+		Offset:      10,
+		Limit:       5,
+	})
+	assert.Equal(t, "aws", *args[0].(*string))
+	assert.Equal(t, []string{"network"}, args[1])
+	assert.Equal(t, "%vpc", *args[2].(*string))
+	assert.Equal(t, "1.0.0", *args[3].(*string))
+	assert.Equal(t, 10, args[4])
+	assert.Equal(t, 5, *args[5].(*int))
+}
+
+func TestListFilters(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	require.NoError(t, s.StoreBatch(ctx, []*module.Module{
+		{ID: "aws-vpc", Name: "vpc", Provider: "aws", Version: "1.0.0", Tags: []string{"network"}, CreatedAt: now.Add(-time.Hour), UpdatedAt: now},
+		{ID: "aws-s3", Name: "s3", Provider: "aws", Version: "2.0.0", Tags: []string{"storage"}, CreatedAt: now, UpdatedAt: now},
+		{ID: "gcp-vpc", Name: "vpc", Provider: "gcp", Version: "1.0.0", Tags: []string{"network"}, CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now},
+	}))
+
+	ids := func(mods []*module.Module) []string {
+		var result []string
+		for _, mod := range mods {
+			result = append(result, mod.ID)
+		}
+		return result
+	}
+
+	all, err := s.List(ctx, storage.Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws-s3", "aws-vpc", "gcp-vpc"}, ids(all))
+
+	byProvider, err := s.List(ctx, storage.Filter{Provider: "aws"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws-s3", "aws-vpc"}, ids(byProvider))
+
+	byTag, err := s.List(ctx, storage.Filter{Tags: []string{"network"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws-vpc", "gcp-vpc"}, ids(byTag))
+
+	byVersion, err := s.List(ctx, storage.Filter{Version: "1.0.0", Provider: "gcp"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gcp-vpc"}, ids(byVersion))
+
+	page, err := s.List(ctx, storage.Filter{Offset: 1, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws-vpc"}, ids(page))
+}
@@ -23,21 +23,36 @@ type Storage interface {
 	// Store saves a module to the storage
 	Store(ctx context.Context, module *module.Module) error
 
+	// StoreBatch saves modules atomically; either all are stored or none are
+	StoreBatch(ctx context.Context, modules []*module.Module) error
+
 	// Get retrieves a module by its ID and version
 	Get(ctx context.Context, id, version string) (*module.Module, error)
 
-	// List returns modules matching the given filter
+	// List returns modules matching the given filter; empty fields match everything
 	List(ctx context.Context, filter Filter) ([]*module.Module, error)
 
-	// Delete removes a module from storage
+	// Delete soft-deletes a module; it is hidden until restored or purged
 	Delete(ctx context.Context, id, version string) error
 
+	// DeleteBatch soft-deletes modules atomically; either all are deleted or none are
+	DeleteBatch(ctx context.Context, modules []*module.Module) error
+
+	// Restore undoes a soft delete
+	Restore(ctx context.Context, id, version string) error
+
+	// Purge permanently removes modules soft-deleted more than olderThan ago
+	Purge(ctx context.Context, olderThan time.Duration) (int, error)
+
 	// GetVersions returns all versions of a module
 	GetVersions(ctx context.Context, id string) ([]string, error)
 
 	// GetLatestVersion returns the latest version of a module
 	GetLatestVersion(ctx context.Context, id string) (string, error)
 
+	// GetLatestStableVersion returns the latest non-prerelease version of a module
+	GetLatestStableVersion(ctx context.Context, id string) (string, error)
+
 	// Lock marks a version as immutable
 	Lock(ctx context.Context, id, version string) error
 
@@ -47,22 +62,47 @@ type Storage interface {
 	// UpdateMetadata updates module metadata without changing content
 	UpdateMetadata(ctx context.Context, id, version string, metadata map[string]interface{}) error
 
+	// UpdateMetadataIfUnchanged updates module metadata only if the stored revision
+	// still equals expectedRev, returning the new revision or an ErrConflict error
+	UpdateMetadataIfUnchanged(ctx context.Context, id, version string, expectedRev int, metadata map[string]interface{}) (int, error)
+
 	// StoreContent saves module content to storage
 	StoreContent(ctx context.Context, id, version string, content []byte) error
 
 	// GetContent retrieves module content from storage
 	GetContent(ctx context.Context, id, version string) ([]byte, error)
 
+	// StoreContentStream saves module content read from r without buffering it in memory
+	StoreContentStream(ctx context.Context, id, version string, r io.Reader) error
+
+	// GetContentStream returns a reader over module content; the caller must close it
+	GetContentStream(ctx context.Context, id, version string) (io.ReadCloser, error)
+
+	// GetContentChecksum returns the hex-encoded SHA-256 of the module content
+	GetContentChecksum(ctx context.Context, id, version string) (string, error)
+
 	// Exists checks if a module version exists
 	Exists(ctx context.Context, id, version string) (bool, error)
 
 	// GetDependencies returns all modules that depend on the given module
 	GetDependencies(ctx context.Context, id, version string) ([]*module.Module, error)
 
+	// ListProviders returns the distinct providers of all stored modules
+	ListProviders(ctx context.Context) ([]string, error)
+
+	// ListTags returns every tag with the number of modules carrying it
+	ListTags(ctx context.Context) ([]TagCount, error)
+
 	// Close releases any resources held by the storage
 	Close() error
 }
 
+// TagCount is a tag and the number of distinct modules carrying it
+type TagCount struct {
+	Tag   string // Tag name
+	Count int    // Number of modules with the tag
+}
+
 // Stats represents storage statistics
 type Stats struct {
 	TotalModules      int       // Total number of modules
@@ -76,6 +116,12 @@ type Stats struct {
 	ActiveConnections int       // Number of active connections
 }
 
+// StatsProvider is implemented by storages that can report usage statistics
+type StatsProvider interface {
+	// Stats returns current storage statistics
+	Stats(ctx context.Context) (*Stats, error)
+}
+
 // Backend represents a storage backend for modules
 type Backend interface {
 	// Store stores a module in the backend
@@ -186,5 +232,7 @@ const (
 	ErrNotFound      = "NOT_FOUND"
 	ErrAlreadyExists = "ALREADY_EXISTS"
 	ErrInvalidInput  = "INVALID_INPUT"
+	ErrConflict      = "CONFLICT"
+	ErrIntegrity     = "INTEGRITY"
 	ErrInternal      = "INTERNAL"
 )
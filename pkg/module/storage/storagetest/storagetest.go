@@ -0,0 +1,84 @@
+// Package storagetest provides tests shared by the storage.Storage
+// implementations, so they can be checked to behave alike.
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/module"
+	"github.com/StackCatalyst/common-lib/pkg/module/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newModule returns a minimal module version ready to store
+func newModule(id, version string) *module.Module {
+	now := time.Now()
+	return &module.Module{ID: id, Name: id, Provider: "aws", Version: version, CreatedAt: now, UpdatedAt: now}
+}
+
+// readContent reads a module version's content through GetContentStream
+func readContent(t *testing.T, s storage.Storage, id, version string) []byte {
+	t.Helper()
+	r, err := s.GetContentStream(context.Background(), id, version)
+	require.NoError(t, err)
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return content
+}
+
+// TestReStore checks that storing over an existing version discards the
+// content written for it, while storing over a locked version keeps it
+func TestReStore(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	content := bytes.Repeat([]byte("module content "), 1000)
+
+	t.Run("replaces content", func(t *testing.T) {
+		mod := newModule("restore-content", "1.0.0")
+		require.NoError(t, s.Store(ctx, mod))
+		require.NoError(t, s.StoreContentStream(ctx, mod.ID, mod.Version, bytes.NewReader(content)))
+
+		require.NoError(t, s.Store(ctx, mod))
+
+		stored, err := s.GetContent(ctx, mod.ID, mod.Version)
+		require.NoError(t, err)
+		assert.Empty(t, stored)
+		assert.Empty(t, readContent(t, s, mod.ID, mod.Version))
+
+		// The checksum of the discarded content is dropped with it
+		_, err = s.GetContentChecksum(ctx, mod.ID, mod.Version)
+		var storageErr *storage.Error
+		require.ErrorAs(t, err, &storageErr)
+		assert.Equal(t, storage.ErrNotFound, storageErr.Code)
+	})
+
+	t.Run("replaces content in a batch", func(t *testing.T) {
+		mod := newModule("restore-batch", "1.0.0")
+		require.NoError(t, s.Store(ctx, mod))
+		require.NoError(t, s.StoreContentStream(ctx, mod.ID, mod.Version, bytes.NewReader(content)))
+
+		require.NoError(t, s.StoreBatch(ctx, []*module.Module{mod}))
+
+		assert.Empty(t, readContent(t, s, mod.ID, mod.Version))
+	})
+
+	t.Run("keeps locked content", func(t *testing.T) {
+		mod := newModule("restore-locked", "1.0.0")
+		require.NoError(t, s.Store(ctx, mod))
+		require.NoError(t, s.StoreContentStream(ctx, mod.ID, mod.Version, bytes.NewReader(content)))
+		require.NoError(t, s.Lock(ctx, mod.ID, mod.Version))
+
+		require.NoError(t, s.Store(ctx, mod))
+		require.NoError(t, s.StoreBatch(ctx, []*module.Module{mod}))
+
+		assert.Equal(t, content, readContent(t, s, mod.ID, mod.Version))
+		checksum, err := s.GetContentChecksum(ctx, mod.ID, mod.Version)
+		require.NoError(t, err)
+		assert.Equal(t, storage.Checksum(content), checksum)
+	})
+}
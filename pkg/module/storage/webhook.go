@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookConfig holds the webhook observer configuration
+type WebhookConfig struct {
+	// URL receives a POST for every storage event
+	URL string `json:"url" yaml:"url"`
+	// Timeout bounds each webhook request
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// Headers are added to every webhook request, e.g. for authentication
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+}
+
+// DefaultWebhookConfig returns the default webhook configuration for url
+func DefaultWebhookConfig(url string) WebhookConfig {
+	return WebhookConfig{
+		URL:     url,
+		Timeout: 5 * time.Second,
+	}
+}
+
+// WebhookEvent is the JSON body posted by WebhookObserver
+type WebhookEvent struct {
+	Event     EventType `json:"event"`
+	ID        string    `json:"id"`
+	Version   string    `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// WebhookObserver posts storage events to an HTTP endpoint
+type WebhookObserver struct {
+	config WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookObserver creates a new webhook observer
+func NewWebhookObserver(config WebhookConfig) (*WebhookObserver, error) {
+	if config.URL == "" {
+		return nil, &Error{Code: ErrInvalidInput, Message: "webhook URL is required"}
+	}
+	return &WebhookObserver{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// OnStore posts a store event
+func (w *WebhookObserver) OnStore(ctx context.Context, id, version string) error {
+	return w.post(ctx, EventStore, id, version)
+}
+
+// OnDelete posts a delete event
+func (w *WebhookObserver) OnDelete(ctx context.Context, id, version string) error {
+	return w.post(ctx, EventDelete, id, version)
+}
+
+// OnRestore posts a restore event
+func (w *WebhookObserver) OnRestore(ctx context.Context, id, version string) error {
+	return w.post(ctx, EventRestore, id, version)
+}
+
+// OnLock posts a lock event
+func (w *WebhookObserver) OnLock(ctx context.Context, id, version string) error {
+	return w.post(ctx, EventLock, id, version)
+}
+
+// OnUpdate posts an update event
+func (w *WebhookObserver) OnUpdate(ctx context.Context, id, version string) error {
+	return w.post(ctx, EventUpdate, id, version)
+}
+
+// post sends an event to the webhook, failing on non-2xx responses
+func (w *WebhookObserver) post(ctx context.Context, event EventType, id, version string) error {
+	body, err := json.Marshal(WebhookEvent{
+		Event:     event,
+		ID:        id,
+		Version:   version,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
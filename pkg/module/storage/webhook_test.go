@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookObserver(t *testing.T) {
+	var received []WebhookEvent
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		authHeader = r.Header.Get("Authorization")
+
+		var event WebhookEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received = append(received, event)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	config := DefaultWebhookConfig(server.URL)
+	config.Headers = map[string]string{"Authorization": "Bearer token"}
+	observer, err := NewWebhookObserver(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, observer.OnStore(ctx, "aws-vpc", "1.0.0"))
+	require.NoError(t, observer.OnLock(ctx, "aws-vpc", "1.0.0"))
+
+	require.Len(t, received, 2)
+	assert.Equal(t, EventStore, received[0].Event)
+	assert.Equal(t, "aws-vpc", received[0].ID)
+	assert.Equal(t, "1.0.0", received[0].Version)
+	assert.False(t, received[0].Timestamp.IsZero())
+	assert.Equal(t, EventLock, received[1].Event)
+	assert.Equal(t, "Bearer token", authHeader)
+}
+
+func TestWebhookObserverErrors(t *testing.T) {
+	_, err := NewWebhookObserver(WebhookConfig{})
+	require.Error(t, err)
+	assert.Equal(t, ErrInvalidInput, err.(*Error).Code)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	observer, err := NewWebhookObserver(DefaultWebhookConfig(server.URL))
+	require.NoError(t, err)
+
+	err = observer.OnDelete(context.Background(), "aws-vpc", "1.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
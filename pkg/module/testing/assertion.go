@@ -28,99 +28,122 @@ type AssertionContext struct {
 	Resources []*Resource
 }
 
-// EvaluateAssertion evaluates a single assertion
-func EvaluateAssertion(assertion string, ctx *AssertionContext) *AssertionResult {
-	result := &AssertionResult{
-		Assertion: assertion,
-	}
+// ParsedAssertion is the structured form of an assertion string such as
+// "variable name equals test" or "resource r1 config.port equals 8080"
+type ParsedAssertion struct {
+	// Reference is the reference type: variable, output or resource
+	Reference string
+	// Name is the variable, output or resource ID being checked
+	Name string
+	// Property is the resource property path; empty for variables and outputs
+	Property string
+	// Condition is the comparison to perform
+	Condition string
+	// Expected is the expected value; empty for exists
+	Expected string
+}
 
-	// Parse assertion components
+// ParseAssertion tokenizes an assertion and checks that it is well formed,
+// without evaluating it against any values
+func ParseAssertion(assertion string) (*ParsedAssertion, error) {
 	parts := strings.Fields(assertion)
 	if len(parts) < 3 {
-		result.Success = false
-		result.Message = "invalid assertion format: must contain at least 3 parts"
-		return result
+		return nil, fmt.Errorf("invalid assertion format: must contain at least 3 parts")
 	}
 
-	// Get value based on reference type
-	var actualValue interface{}
-	var expectedValue string
+	parsed := &ParsedAssertion{
+		Reference: parts[0],
+		Name:      parts[1],
+	}
 	switch parts[0] {
 	case "variable":
-		actualValue = ctx.Variables[parts[1]]
+		parsed.Condition = parts[2]
 		if len(parts) > 3 && parts[2] != "exists" {
-			expectedValue = parts[3]
+			parsed.Expected = parts[3]
 		}
 	case "output":
-		actualValue = ctx.Outputs[parts[1]]
+		parsed.Condition = parts[2]
 		if len(parts) > 3 {
-			expectedValue = parts[3]
+			parsed.Expected = parts[3]
 		}
 	case "resource":
 		if len(parts) < 4 {
-			result.Success = false
-			result.Message = "invalid resource assertion format: must contain at least 4 parts"
-			return result
+			return nil, fmt.Errorf("invalid resource assertion format: must contain at least 4 parts")
 		}
-		actualValue = findResourceProperty(ctx.Resources, parts[1], parts[2])
+		parsed.Property = parts[2]
+		parsed.Condition = parts[3]
 		if len(parts) > 4 {
-			expectedValue = parts[4]
+			parsed.Expected = parts[4]
 		}
-		parts = []string{parts[0], parts[1], parts[3]} // Adjust parts for condition evaluation
-		if expectedValue != "" {
-			parts = append(parts, expectedValue)
+	default:
+		return nil, fmt.Errorf("unknown reference type: %s", parts[0])
+	}
+
+	switch parsed.Condition {
+	case "equals", "=", "contains", "matches", "type":
+		if parsed.Expected == "" {
+			return nil, fmt.Errorf("missing expected value for %s condition", parsed.Condition)
 		}
+	case "exists":
 	default:
+		return nil, fmt.Errorf("unknown condition: %s", parsed.Condition)
+	}
+
+	if parsed.Condition == "matches" {
+		if _, err := regexp.Compile(parsed.Expected); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", parsed.Expected, err)
+		}
+	}
+
+	return parsed, nil
+}
+
+// EvaluateAssertion evaluates a single assertion
+func EvaluateAssertion(assertion string, ctx *AssertionContext) *AssertionResult {
+	result := &AssertionResult{
+		Assertion: assertion,
+	}
+
+	parsed, err := ParseAssertion(assertion)
+	if err != nil {
 		result.Success = false
-		result.Message = fmt.Sprintf("unknown reference type: %s", parts[0])
+		result.Message = err.Error()
 		return result
 	}
 
+	// Get value based on reference type
+	var actualValue interface{}
+	switch parsed.Reference {
+	case "variable":
+		actualValue = ctx.Variables[parsed.Name]
+	case "output":
+		actualValue = ctx.Outputs[parsed.Name]
+	case "resource":
+		actualValue = findResourceProperty(ctx.Resources, parsed.Name, parsed.Property)
+	}
+
 	// Evaluate condition
-	switch parts[2] {
+	expectedValue := parsed.Expected
+	switch parsed.Condition {
 	case "equals", "=":
-		if expectedValue == "" {
-			result.Success = false
-			result.Message = "missing expected value for equals condition"
-			return result
-		}
 		result.Success = evaluateEquals(actualValue, expectedValue)
 		result.Message = fmt.Sprintf("expected %v to equal %v", actualValue, expectedValue)
 
 	case "contains":
-		if expectedValue == "" {
-			result.Success = false
-			result.Message = "missing expected value for contains condition"
-			return result
-		}
 		result.Success = evaluateContains(actualValue, expectedValue)
 		result.Message = fmt.Sprintf("expected %v to contain %v", actualValue, expectedValue)
 
 	case "matches":
-		if expectedValue == "" {
-			result.Success = false
-			result.Message = "missing expected value for matches condition"
-			return result
-		}
 		result.Success = evaluateMatches(actualValue, expectedValue)
 		result.Message = fmt.Sprintf("expected %v to match pattern %v", actualValue, expectedValue)
 
 	case "exists":
 		result.Success = actualValue != nil
-		result.Message = fmt.Sprintf("expected %s to exist", parts[1])
+		result.Message = fmt.Sprintf("expected %s to exist", parsed.Name)
 
 	case "type":
-		if expectedValue == "" {
-			result.Success = false
-			result.Message = "missing expected value for type condition"
-			return result
-		}
 		result.Success = evaluateType(actualValue, expectedValue)
 		result.Message = fmt.Sprintf("expected %v to be of type %s", actualValue, expectedValue)
-
-	default:
-		result.Success = false
-		result.Message = fmt.Sprintf("unknown condition: %s", parts[2])
 	}
 
 	return result
@@ -260,3 +260,39 @@ func TestResourcePropertyAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAssertion(t *testing.T) {
+	t.Run("resource assertion", func(t *testing.T) {
+		parsed, err := ParseAssertion("resource r1 config.port equals 8080")
+		assert.NoError(t, err)
+		assert.Equal(t, &ParsedAssertion{
+			Reference: "resource",
+			Name:      "r1",
+			Property:  "config.port",
+			Condition: "equals",
+			Expected:  "8080",
+		}, parsed)
+	})
+
+	t.Run("exists needs no expected value", func(t *testing.T) {
+		parsed, err := ParseAssertion("output result exists")
+		assert.NoError(t, err)
+		assert.Equal(t, "exists", parsed.Condition)
+		assert.Empty(t, parsed.Expected)
+	})
+
+	malformed := map[string]string{
+		"too few parts":           "variable name",
+		"short resource":          "resource r1 equals",
+		"unknown reference":       "module name equals test",
+		"unknown condition":       "variable name equal test",
+		"missing expected value":  "output result contains",
+		"invalid regular pattern": "variable name matches ([a-z",
+	}
+	for name, assertion := range malformed {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseAssertion(assertion)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -38,8 +38,13 @@ type Module struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	// Metadata is additional module metadata
 	Metadata map[string]interface{} `json:"metadata"`
+	// Revision is incremented on every metadata or content write, for optimistic
+	// concurrency. It is managed by the storage and not covered by Signature.
+	Revision int `json:"revision"`
 	// Tests are the module test cases
 	Tests []*Test `json:"tests"`
+	// Signature is the base64-encoded Ed25519 signature over the module's signing payload
+	Signature string `json:"signature,omitempty"`
 }
 
 // Test represents a module test case
@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/StackCatalyst/common-lib/pkg/module"
+	moduletesting "github.com/StackCatalyst/common-lib/pkg/module/testing"
+)
+
+// AssertionValidator defines the interface for test assertion validation
+type AssertionValidator interface {
+	Validate(ctx context.Context, mod *module.Module) (*ValidationResult, error)
+}
+
+// DefaultAssertionValidator implements AssertionValidator
+type DefaultAssertionValidator struct{}
+
+// NewAssertionValidator creates a new DefaultAssertionValidator instance
+func NewAssertionValidator() AssertionValidator {
+	return &DefaultAssertionValidator{}
+}
+
+// Validate checks that every test assertion is well formed, using the same
+// parser the test runner evaluates assertions with
+func (v *DefaultAssertionValidator) Validate(ctx context.Context, mod *module.Module) (*ValidationResult, error) {
+	result := &ValidationResult{
+		Valid:  true,
+		Errors: make([]ValidationError, 0),
+	}
+
+	for i, test := range mod.Tests {
+		if test == nil {
+			continue
+		}
+		for j, assertion := range test.Assertions {
+			if _, err := moduletesting.ParseAssertion(assertion); err != nil {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("tests[%d].assertions[%d]", i, j),
+					Message: err.Error(),
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
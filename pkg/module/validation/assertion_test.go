@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/StackCatalyst/common-lib/pkg/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertionValidator(t *testing.T) {
+	validator := NewAssertionValidator()
+	ctx := context.Background()
+
+	t.Run("valid assertions", func(t *testing.T) {
+		mod := &module.Module{
+			Tests: []*module.Test{
+				{
+					Name: "test1",
+					Assertions: []string{
+						"variable name equals test",
+						"output result exists",
+						"resource r1 config.port equals 8080",
+						"variable name matches ^test$",
+					},
+				},
+			},
+		}
+
+		result, err := validator.Validate(ctx, mod)
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("malformed assertions", func(t *testing.T) {
+		mod := &module.Module{
+			Tests: []*module.Test{
+				{
+					Name: "test1",
+					Assertions: []string{
+						"variable name equals test",
+						"resource r1 config.port equal 8080",
+					},
+				},
+				{
+					Name: "test2",
+					Assertions: []string{
+						"resource r1 equals",
+						"variable name matches ([a-z",
+					},
+				},
+			},
+		}
+
+		result, err := validator.Validate(ctx, mod)
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		require.Len(t, result.Errors, 3)
+		assert.Equal(t, "tests[0].assertions[1]", result.Errors[0].Field)
+		assert.Contains(t, result.Errors[0].Message, "unknown condition")
+		assert.Equal(t, "tests[1].assertions[0]", result.Errors[1].Field)
+		assert.Contains(t, result.Errors[1].Message, "at least 4 parts")
+		assert.Equal(t, "tests[1].assertions[1]", result.Errors[2].Field)
+		assert.Contains(t, result.Errors[2].Message, "invalid pattern")
+	})
+}
@@ -3,6 +3,7 @@ package validation
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"regexp"
 
 	"github.com/StackCatalyst/common-lib/pkg/module"
@@ -108,7 +109,92 @@ func (v *DefaultSchemaValidator) Validate(ctx context.Context, mod *module.Modul
 					})
 				}
 			}
+
+			if v.Validation.MinValue != nil && v.Validation.MaxValue != nil && *v.Validation.MinValue > *v.Validation.MaxValue {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("variables[%d].validation.min_value", i),
+					Message: "min value must not be greater than max value",
+				})
+			}
+
+			if v.Validation.MinLength != nil && v.Validation.MaxLength != nil && *v.Validation.MinLength > *v.Validation.MaxLength {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("variables[%d].validation.min_length", i),
+					Message: "min length must not be greater than max length",
+				})
+			}
+		}
+
+		// Validate the default value against the declared type and allowed values
+		if v.Default != nil && validTypes[v.Type] {
+			if !matchesType(v.Type, v.Default) {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("variables[%d].default", i),
+					Message: fmt.Sprintf("default value is not a valid %s", v.Type),
+				})
+			} else if v.Validation != nil && len(v.Validation.AllowedValues) > 0 && !containsValue(v.Validation.AllowedValues, v.Default) {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("variables[%d].default", i),
+					Message: "default value is not one of the allowed values",
+				})
+			}
+		}
+	}
+
+	// Validate name uniqueness
+	variableNames := make(map[string]bool, len(mod.Variables))
+	for i, v := range mod.Variables {
+		if v.Name == "" {
+			continue
+		}
+		if variableNames[v.Name] {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("variables[%d].name", i),
+				Message: fmt.Sprintf("duplicate variable name %q", v.Name),
+			})
+		}
+		variableNames[v.Name] = true
+	}
+
+	outputNames := make(map[string]bool, len(mod.Outputs))
+	for i, o := range mod.Outputs {
+		if o.Name == "" {
+			continue
+		}
+		if outputNames[o.Name] {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("outputs[%d].name", i),
+				Message: fmt.Sprintf("duplicate output name %q", o.Name),
+			})
+		} else if variableNames[o.Name] {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("outputs[%d].name", i),
+				Message: fmt.Sprintf("output name %q conflicts with a variable of the same name", o.Name),
+			})
+		}
+		outputNames[o.Name] = true
+	}
+
+	dependencyNames := make(map[string]bool, len(mod.Dependencies))
+	for i, d := range mod.Dependencies {
+		if d.Name == "" {
+			continue
+		}
+		if dependencyNames[d.Name] {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("dependencies[%d].name", i),
+				Message: fmt.Sprintf("duplicate dependency name %q", d.Name),
+			})
 		}
+		dependencyNames[d.Name] = true
 	}
 
 	// Validate resources
@@ -151,3 +237,55 @@ func (v *DefaultSchemaValidator) Validate(ctx context.Context, mod *module.Modul
 
 	return result, nil
 }
+
+// matchesType reports whether value is compatible with the given variable type
+func matchesType(typ string, value interface{}) bool {
+	kind := reflect.TypeOf(value).Kind()
+	switch typ {
+	case "string":
+		return kind == reflect.String
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "bool":
+		return kind == reflect.Bool
+	case "list":
+		return kind == reflect.Slice || kind == reflect.Array
+	case "map", "object":
+		return kind == reflect.Map || kind == reflect.Struct
+	default:
+		return false
+	}
+}
+
+// containsValue reports whether value is among allowed, treating numbers of
+// different Go types (e.g. int and float64 from JSON) as equal when their values are
+func containsValue(allowed []interface{}, value interface{}) bool {
+	for _, a := range allowed {
+		if af, ok := toFloat(a); ok {
+			if vf, ok := toFloat(value); ok && af == vf {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(a, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// toFloat converts any Go numeric value to float64
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
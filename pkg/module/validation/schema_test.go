@@ -136,4 +136,167 @@ func TestSchemaValidator(t *testing.T) {
 		assert.False(t, result.Valid)
 		assert.Contains(t, result.Errors[0].Field, "variables[0].validation.pattern")
 	})
+
+	t.Run("default and bounds checks", func(t *testing.T) {
+		intPtr := func(i int) *int { return &i }
+		floatPtr := func(f float64) *float64 { return &f }
+
+		tests := []struct {
+			name      string
+			variable  *module.Variable
+			wantField string
+		}{
+			{
+				name:      "string default on number variable",
+				variable:  &module.Variable{Name: "count", Type: "number", Default: "three"},
+				wantField: "variables[2].default",
+			},
+			{
+				name:      "number default on bool variable",
+				variable:  &module.Variable{Name: "debug", Type: "bool", Default: 1},
+				wantField: "variables[2].default",
+			},
+			{
+				name: "default outside allowed values",
+				variable: &module.Variable{
+					Name:       "size",
+					Type:       "string",
+					Default:    "huge",
+					Validation: &module.Validation{AllowedValues: []interface{}{"small", "large"}},
+				},
+				wantField: "variables[2].default",
+			},
+			{
+				name: "inverted value bounds",
+				variable: &module.Variable{
+					Name:       "replicas",
+					Type:       "number",
+					Validation: &module.Validation{MinValue: floatPtr(10), MaxValue: floatPtr(1)},
+				},
+				wantField: "variables[2].validation.min_value",
+			},
+			{
+				name: "inverted length bounds",
+				variable: &module.Variable{
+					Name:       "prefix",
+					Type:       "string",
+					Validation: &module.Validation{MinLength: intPtr(8), MaxLength: intPtr(4)},
+				},
+				wantField: "variables[2].validation.min_length",
+			},
+			{
+				name: "valid numeric default in allowed values",
+				variable: &module.Variable{
+					Name:       "replicas",
+					Type:       "number",
+					Default:    3,
+					Validation: &module.Validation{AllowedValues: []interface{}{1.0, 3.0, 5.0}, MinValue: floatPtr(1), MaxValue: floatPtr(5)},
+				},
+			},
+			{
+				name:     "valid list default",
+				variable: &module.Variable{Name: "zones", Type: "list", Default: []interface{}{"a", "b"}},
+			},
+			{
+				name:     "valid map default",
+				variable: &module.Variable{Name: "labels", Type: "map", Default: map[string]interface{}{"team": "platform"}},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mod := &module.Module{
+					ID:      "test-module",
+					Name:    "Test Module",
+					Version: "1.0.0",
+					Variables: []*module.Variable{
+						{Name: "region", Type: "string", Default: "us-east-1"},
+						{Name: "enabled", Type: "bool", Default: true},
+						tt.variable,
+					},
+				}
+
+				result, err := validator.Validate(ctx, mod)
+				require.NoError(t, err)
+				require.NotNil(t, result)
+
+				if tt.wantField == "" {
+					assert.True(t, result.Valid)
+					assert.Empty(t, result.Errors)
+					return
+				}
+				assert.False(t, result.Valid)
+				require.Len(t, result.Errors, 1)
+				assert.Equal(t, tt.wantField, result.Errors[0].Field)
+			})
+		}
+	})
+
+	t.Run("duplicate names", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			mod       *module.Module
+			wantField string
+		}{
+			{
+				name: "duplicate variables",
+				mod: &module.Module{
+					Variables: []*module.Variable{
+						{Name: "region", Type: "string"},
+						{Name: "zone", Type: "string"},
+						{Name: "region", Type: "string"},
+					},
+				},
+				wantField: "variables[2].name",
+			},
+			{
+				name: "duplicate outputs",
+				mod: &module.Module{
+					Outputs: []*module.Output{
+						{Name: "arn"},
+						{Name: "arn"},
+					},
+				},
+				wantField: "outputs[1].name",
+			},
+			{
+				name: "duplicate dependencies",
+				mod: &module.Module{
+					Dependencies: []*module.Dependency{
+						{Name: "network", Version: "1.0.0"},
+						{Name: "network", Version: "2.0.0"},
+					},
+				},
+				wantField: "dependencies[1].name",
+			},
+			{
+				name: "output shadows variable",
+				mod: &module.Module{
+					Variables: []*module.Variable{
+						{Name: "bucket", Type: "string"},
+					},
+					Outputs: []*module.Output{
+						{Name: "arn"},
+						{Name: "bucket"},
+					},
+				},
+				wantField: "outputs[1].name",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.mod.ID = "test-module"
+				tt.mod.Name = "Test Module"
+				tt.mod.Version = "1.0.0"
+
+				result, err := validator.Validate(ctx, tt.mod)
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				assert.False(t, result.Valid)
+				require.Len(t, result.Errors, 1)
+				assert.Equal(t, tt.wantField, result.Errors[0].Field)
+			})
+		}
+	})
 }
@@ -24,11 +24,53 @@ type Validator interface {
 	Validate(ctx context.Context, mod *module.Module) (*ValidationResult, error)
 }
 
+// Compile-time checks that the built-in validators can be composed
+var (
+	_ Validator = (*DefaultSchemaValidator)(nil)
+	_ Validator = (*DefaultResourceValidator)(nil)
+	_ Validator = (*DefaultDependencyValidator)(nil)
+	_ Validator = (*DefaultAssertionValidator)(nil)
+)
+
+// ModuleValidator runs a list of validators and merges their results
+type ModuleValidator struct {
+	validators []Validator
+}
+
+// NewModuleValidator creates a validator that runs each of the given validators in order
+func NewModuleValidator(validators ...Validator) *ModuleValidator {
+	return &ModuleValidator{validators: validators}
+}
+
+// Validate runs every validator and returns a single merged result. The module
+// is invalid if any validator reports it invalid, and errors are concatenated
+// in validator order. The first validator error aborts validation.
+func (v *ModuleValidator) Validate(ctx context.Context, mod *module.Module) (*ValidationResult, error) {
+	result := &ValidationResult{
+		Valid:  true,
+		Errors: make([]ValidationError, 0),
+	}
+
+	for _, validator := range v.validators {
+		r, err := validator.Validate(ctx, mod)
+		if err != nil {
+			return nil, err
+		}
+		if !r.Valid {
+			result.Valid = false
+		}
+		result.Errors = append(result.Errors, r.Errors...)
+	}
+
+	return result, nil
+}
+
 // DefaultValidator implements the Validator interface
 type DefaultValidator struct {
 	schemaValidator     SchemaValidator
 	dependencyValidator DependencyValidator
 	resourceValidator   ResourceValidator
+	assertionValidator  AssertionValidator
 }
 
 // NewValidator creates a new DefaultValidator instance
@@ -37,6 +79,7 @@ func NewValidator() *DefaultValidator {
 		schemaValidator:     NewSchemaValidator(),
 		dependencyValidator: NewDependencyValidator(),
 		resourceValidator:   NewResourceValidator(),
+		assertionValidator:  NewAssertionValidator(),
 	}
 }
 
@@ -77,5 +120,15 @@ func (v *DefaultValidator) Validate(ctx context.Context, mod *module.Module) (*V
 		result.Errors = append(result.Errors, resResult.Errors...)
 	}
 
+	// Perform assertion validation
+	assertResult, err := v.assertionValidator.Validate(ctx, mod)
+	if err != nil {
+		return nil, err
+	}
+	if !assertResult.Valid {
+		result.Valid = false
+		result.Errors = append(result.Errors, assertResult.Errors...)
+	}
+
 	return result, nil
 }
@@ -142,3 +142,84 @@ func TestDefaultValidator(t *testing.T) {
 		assert.Contains(t, fields, "tests[0].skip_reason")
 	})
 }
+
+// staticValidator returns a fixed result, standing in for an org-specific validator
+type staticValidator struct {
+	result *ValidationResult
+	err    error
+}
+
+func (v *staticValidator) Validate(ctx context.Context, mod *module.Module) (*ValidationResult, error) {
+	return v.result, v.err
+}
+
+func TestModuleValidator(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("merges schema and resource results", func(t *testing.T) {
+		validator := NewModuleValidator(NewSchemaValidator(), NewResourceValidator())
+		mod := &module.Module{
+			ID:      "test@module",
+			Name:    "Test Module",
+			Version: "1.0.0",
+			Resources: []*module.Resource{
+				{
+					Type:     "test_resource",
+					Provider: "test_provider",
+					Properties: map[string]*module.Property{
+						"prop1": {Description: "missing type"},
+					},
+				},
+			},
+		}
+
+		result, err := validator.Validate(ctx, mod)
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+
+		require.NotEmpty(t, result.Errors)
+		// Schema errors come first, followed by resource errors
+		assert.Equal(t, "id", result.Errors[0].Field)
+		assert.Equal(t, "resources[0].properties.prop1.type", result.Errors[len(result.Errors)-1].Field)
+	})
+
+	t.Run("valid when every validator passes", func(t *testing.T) {
+		validator := NewModuleValidator(
+			&staticValidator{result: &ValidationResult{Valid: true}},
+			&staticValidator{result: &ValidationResult{Valid: true}},
+		)
+
+		result, err := validator.Validate(ctx, &module.Module{})
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("custom validator can invalidate", func(t *testing.T) {
+		validator := NewModuleValidator(
+			&staticValidator{result: &ValidationResult{Valid: true}},
+			&staticValidator{result: &ValidationResult{
+				Valid:  false,
+				Errors: []ValidationError{{Field: "tags", Message: "team tag is required"}},
+			}},
+		)
+
+		result, err := validator.Validate(ctx, &module.Module{})
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, []ValidationError{{Field: "tags", Message: "team tag is required"}}, result.Errors)
+	})
+
+	t.Run("validator errors abort", func(t *testing.T) {
+		validator := NewModuleValidator(&staticValidator{err: assert.AnError})
+
+		_, err := validator.Validate(ctx, &module.Module{})
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("no validators", func(t *testing.T) {
+		result, err := NewModuleValidator().Validate(ctx, &module.Module{})
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+	})
+}
@@ -1,8 +1,15 @@
 package version
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/StackCatalyst/common-lib/pkg/module"
@@ -30,6 +37,45 @@ type Manager interface {
 
 	// IsSatisfied checks if a version satisfies a constraint
 	IsSatisfied(version, constraint string) (bool, error)
+
+	// ResolveGraph orders modules so that every module follows its dependencies
+	ResolveGraph(modules []*module.Module) ([]*module.Module, error)
+
+	// Bump computes the next version for the given bump kind
+	Bump(version string, kind BumpKind) (string, error)
+
+	// BumpPrerelease increments the numeric suffix of a prerelease version
+	BumpPrerelease(version string) (string, error)
+
+	// Intersect combines constraints into a single constraint satisfied only by versions satisfying all of them
+	Intersect(constraints []string) (string, error)
+
+	// BestMatch returns the highest version satisfying every constraint
+	BestMatch(constraints []string, versions []string) (string, error)
+}
+
+// BumpKind identifies which version component to increment
+type BumpKind int
+
+// Bump kinds
+const (
+	BumpMajor BumpKind = iota
+	BumpMinor
+	BumpPatch
+)
+
+// String returns the name of the bump kind
+func (k BumpKind) String() string {
+	switch k {
+	case BumpMajor:
+		return "major"
+	case BumpMinor:
+		return "minor"
+	case BumpPatch:
+		return "patch"
+	default:
+		return fmt.Sprintf("BumpKind(%d)", int(k))
+	}
 }
 
 // Version represents a semantic version
@@ -37,14 +83,35 @@ type Version struct {
 	*semver.Version
 }
 
+// Signature verification errors
+var (
+	// ErrSignatureMissing is returned when a public key is configured but the module is unsigned
+	ErrSignatureMissing = errors.New("module signature is missing")
+	// ErrSignatureInvalid is returned when the module signature does not match its content
+	ErrSignatureInvalid = errors.New("module signature is invalid")
+)
+
+// Options configures a version manager
+type Options struct {
+	// PublicKey enables signature verification in Verify when set
+	PublicKey ed25519.PublicKey
+}
+
 // DefaultManager is the default implementation of Manager
-type DefaultManager struct{}
+type DefaultManager struct {
+	publicKey ed25519.PublicKey
+}
 
 // NewManager creates a new version manager
 func NewManager() Manager {
 	return &DefaultManager{}
 }
 
+// NewManagerWithOptions creates a new version manager with the given options
+func NewManagerWithOptions(opts Options) Manager {
+	return &DefaultManager{publicKey: opts.PublicKey}
+}
+
 // Parse parses a version string into a Version object
 func (m *DefaultManager) Parse(version string) (*Version, error) {
 	v, err := semver.NewVersion(version)
@@ -107,7 +174,82 @@ func (m *DefaultManager) Verify(module *module.Module) error {
 		}
 	}
 
-	// TODO: Implement signature verification
+	if m.publicKey != nil {
+		if err := VerifySignature(module, m.publicKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// signedModule is the part of a module covered by its signature: the fields
+// fixed when a version is published. Fields the storage changes on later
+// writes (timestamps, Metadata and Revision) are excluded so that a signature
+// survives metadata updates and re-stores.
+type signedModule struct {
+	ID           string               `json:"id"`
+	Name         string               `json:"name"`
+	Provider     string               `json:"provider"`
+	Version      string               `json:"version"`
+	Description  string               `json:"description"`
+	Source       string               `json:"source"`
+	Variables    []*module.Variable   `json:"variables"`
+	Outputs      []*module.Output     `json:"outputs"`
+	Dependencies []*module.Dependency `json:"dependencies"`
+	Tags         []string             `json:"tags"`
+}
+
+// SigningPayload returns the canonical bytes a module signature is computed
+// over: the JSON encoding of the module's published fields
+func SigningPayload(mod *module.Module) ([]byte, error) {
+	payload, err := json.Marshal(signedModule{
+		ID:           mod.ID,
+		Name:         mod.Name,
+		Provider:     mod.Provider,
+		Version:      mod.Version,
+		Description:  mod.Description,
+		Source:       mod.Source,
+		Variables:    mod.Variables,
+		Outputs:      mod.Outputs,
+		Dependencies: mod.Dependencies,
+		Tags:         mod.Tags,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode module: %w", err)
+	}
+	return payload, nil
+}
+
+// Sign computes the module's signature with the given private key and stores it on the module
+func Sign(mod *module.Module, privateKey ed25519.PrivateKey) error {
+	payload, err := SigningPayload(mod)
+	if err != nil {
+		return err
+	}
+	mod.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, payload))
+	return nil
+}
+
+// VerifySignature checks the module's signature against the given public key
+func VerifySignature(mod *module.Module, publicKey ed25519.PublicKey) error {
+	if mod.Signature == "" {
+		return ErrSignatureMissing
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(mod.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSignatureInvalid, err)
+	}
+
+	payload, err := SigningPayload(mod)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, payload, signature) {
+		return ErrSignatureInvalid
+	}
 	return nil
 }
 
@@ -147,6 +289,289 @@ func (m *DefaultManager) IsSatisfied(version, constraint string) (bool, error) {
 	return c.Check(v), nil
 }
 
+// ResolveGraph orders modules so that every module follows its dependencies.
+// Dependencies are matched to modules by ID using their Source, or their Name
+// when no Source is set; dependencies outside the given set are ignored.
+// An error naming the cycle is returned if the modules depend on each other circularly.
+func (m *DefaultManager) ResolveGraph(modules []*module.Module) ([]*module.Module, error) {
+	byID := make(map[string]*module.Module, len(modules))
+	for _, mod := range modules {
+		byID[mod.ID] = mod
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(modules))
+	sorted := make([]*module.Module, 0, len(modules))
+	var path []string
+
+	var visit func(mod *module.Module) error
+	visit = func(mod *module.Module) error {
+		switch state[mod.ID] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for i, id := range path {
+				if id == mod.ID {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), mod.ID)
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+
+		state[mod.ID] = visiting
+		path = append(path, mod.ID)
+
+		for _, dep := range mod.Dependencies {
+			target, ok := byID[dependencyID(dep)]
+			if !ok {
+				continue // External dependency
+			}
+			if err := visit(target); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[mod.ID] = visited
+		sorted = append(sorted, mod)
+		return nil
+	}
+
+	for _, mod := range modules {
+		if err := visit(mod); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+// Bump computes the next version for the given bump kind. Lower components are
+// reset to zero and any prerelease or metadata is dropped.
+func (m *DefaultManager) Bump(version string, kind BumpKind) (string, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("invalid version format: %w", err)
+	}
+
+	// Strip prerelease and metadata first so that e.g. a patch bump of
+	// 1.2.3-rc.1 yields 1.2.4 rather than finalizing 1.2.3
+	base := semver.New(v.Major(), v.Minor(), v.Patch(), "", "")
+
+	var next semver.Version
+	switch kind {
+	case BumpMajor:
+		next = base.IncMajor()
+	case BumpMinor:
+		next = base.IncMinor()
+	case BumpPatch:
+		next = base.IncPatch()
+	default:
+		return "", fmt.Errorf("unknown bump kind: %s", kind)
+	}
+
+	return next.String(), nil
+}
+
+// BumpPrerelease increments the trailing numeric identifier of a prerelease
+// version (1.2.3-rc.1 becomes 1.2.3-rc.2). A prerelease without a numeric
+// suffix gets ".1" appended. Metadata is dropped.
+func (m *DefaultManager) BumpPrerelease(version string) (string, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("invalid version format: %w", err)
+	}
+
+	prerelease := v.Prerelease()
+	if prerelease == "" {
+		return "", fmt.Errorf("version %s is not a prerelease", version)
+	}
+
+	parts := strings.Split(prerelease, ".")
+	last := parts[len(parts)-1]
+	if n, err := strconv.ParseUint(last, 10, 64); err == nil {
+		parts[len(parts)-1] = strconv.FormatUint(n+1, 10)
+	} else {
+		parts = append(parts, "1")
+	}
+
+	next, err := v.SetPrerelease(strings.Join(parts, "."))
+	if err != nil {
+		return "", fmt.Errorf("invalid prerelease: %w", err)
+	}
+	next, err = next.SetMetadata("")
+	if err != nil {
+		return "", fmt.Errorf("invalid metadata: %w", err)
+	}
+
+	return next.String(), nil
+}
+
+// Intersect combines constraints into a single constraint string that is
+// satisfied only by versions satisfying all of them. An error is returned if
+// any constraint is invalid or if no version can satisfy them all.
+func (m *DefaultManager) Intersect(constraints []string) (string, error) {
+	if len(constraints) == 0 {
+		return "", fmt.Errorf("no constraints provided")
+	}
+
+	// Distribute AND over OR so that "a || b" and "c" become "a, c || b, c"
+	combined := []string{""}
+	for _, constraint := range constraints {
+		if _, err := semver.NewConstraint(constraint); err != nil {
+			return "", fmt.Errorf("invalid constraint format %q: %w", constraint, err)
+		}
+
+		var next []string
+		for _, prefix := range combined {
+			for _, alt := range strings.Split(constraint, "||") {
+				alt = strings.TrimSpace(alt)
+				if prefix != "" {
+					alt = prefix + ", " + alt
+				}
+				next = append(next, alt)
+			}
+		}
+		combined = next
+	}
+
+	result := strings.Join(combined, " || ")
+	c, err := semver.NewConstraint(result)
+	if err != nil {
+		return "", fmt.Errorf("invalid combined constraint %q: %w", result, err)
+	}
+
+	for _, candidate := range constraintCandidates(constraints) {
+		if c.Check(candidate) {
+			return result, nil
+		}
+	}
+
+	return "", fmt.Errorf("constraints are unsatisfiable: %s", strings.Join(constraints, " and "))
+}
+
+// BestMatch returns the highest version satisfying every constraint, exactly
+// as it appears in versions
+func (m *DefaultManager) BestMatch(constraints []string, versions []string) (string, error) {
+	parsed := make([]*semver.Constraints, 0, len(constraints))
+	for _, constraint := range constraints {
+		c, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return "", fmt.Errorf("invalid constraint format %q: %w", constraint, err)
+		}
+		parsed = append(parsed, c)
+	}
+
+	for _, v := range SortDescending(versions) {
+		sv, err := semver.NewVersion(v)
+		if err != nil {
+			continue // Skip invalid versions
+		}
+
+		satisfied := true
+		for _, c := range parsed {
+			if !c.Check(sv) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return v, nil
+		}
+	}
+
+	return "", fmt.Errorf("no version satisfies all constraints: %s", strings.Join(constraints, " and "))
+}
+
+var constraintVersionRegex = regexp.MustCompile(`v?[0-9]+(\.[0-9]+){0,2}`)
+
+// constraintCandidates returns versions at and just beyond every bound
+// mentioned in the constraints. If any version satisfies a set of range
+// constraints, one of these candidates does too.
+func constraintCandidates(constraints []string) []*semver.Version {
+	candidates := []*semver.Version{semver.MustParse("0.0.0")}
+	for _, constraint := range constraints {
+		for _, match := range constraintVersionRegex.FindAllString(constraint, -1) {
+			v, err := semver.NewVersion(match)
+			if err != nil {
+				continue
+			}
+			patch, minor, major := v.IncPatch(), v.IncMinor(), v.IncMajor()
+			candidates = append(candidates, v, &patch, &minor, &major)
+		}
+	}
+	return candidates
+}
+
+// dependencyID returns the module ID a dependency refers to
+func dependencyID(dep *module.Dependency) string {
+	if dep.Source != "" {
+		return dep.Source
+	}
+	return dep.Name
+}
+
+// SortDescending orders version strings from newest to oldest using semantic
+// version precedence. Strings that are not valid versions are kept, in their
+// original order, after all valid versions.
+func SortDescending(versions []string) []string {
+	type parsed struct {
+		raw string
+		sv  *semver.Version
+	}
+
+	items := make([]parsed, len(versions))
+	for i, v := range versions {
+		sv, _ := semver.NewVersion(v)
+		items[i] = parsed{raw: v, sv: sv}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].sv == nil || items[j].sv == nil {
+			return items[i].sv != nil && items[j].sv == nil
+		}
+		return items[i].sv.GreaterThan(items[j].sv)
+	})
+
+	sorted := make([]string, len(items))
+	for i, item := range items {
+		sorted[i] = item.raw
+	}
+	return sorted
+}
+
+// Latest returns the highest valid version, including prereleases
+func Latest(versions []string) (string, error) {
+	return latest(versions, true)
+}
+
+// LatestStable returns the highest valid version that is not a prerelease
+func LatestStable(versions []string) (string, error) {
+	return latest(versions, false)
+}
+
+func latest(versions []string, includePrerelease bool) (string, error) {
+	for _, v := range SortDescending(versions) {
+		sv, err := semver.NewVersion(v)
+		if err != nil {
+			continue // Skip invalid versions
+		}
+		if !includePrerelease && sv.Prerelease() != "" {
+			continue
+		}
+		return v, nil
+	}
+	return "", fmt.Errorf("no matching version found")
+}
+
 // String returns the string representation of a Version
 func (v *Version) String() string {
 	return v.Version.String()
@@ -1,7 +1,9 @@
 package version
 
 import (
+	"crypto/ed25519"
 	"testing"
+	"time"
 
 	"github.com/StackCatalyst/common-lib/pkg/module"
 	"github.com/stretchr/testify/assert"
@@ -357,3 +359,302 @@ func TestVersionMethods(t *testing.T) {
 	assert.True(t, v.IsPrerelease())
 	assert.Equal(t, "1.2.3-beta.1+20240101", v.Original())
 }
+
+func TestSortDescending(t *testing.T) {
+	versions := []string{"1.9.0", "1.10.0-rc.1", "invalid", "1.10.0", "1.2.0"}
+
+	sorted := SortDescending(versions)
+	assert.Equal(t, []string{"1.10.0", "1.10.0-rc.1", "1.9.0", "1.2.0", "invalid"}, sorted)
+
+	// The input slice is left untouched
+	assert.Equal(t, "1.9.0", versions[0])
+}
+
+func TestLatestVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		versions   []string
+		wantLatest string
+		wantStable string
+		wantErr    bool
+	}{
+		{
+			name:       "numeric ordering",
+			versions:   []string{"1.9.0", "1.10.0", "1.10.0-rc.1"},
+			wantLatest: "1.10.0",
+			wantStable: "1.10.0",
+		},
+		{
+			name:       "prerelease newer than stable",
+			versions:   []string{"1.9.0", "1.10.0-rc.1"},
+			wantLatest: "1.10.0-rc.1",
+			wantStable: "1.9.0",
+		},
+		{
+			name:     "no versions",
+			versions: nil,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latest, err := Latest(tt.versions)
+			stable, stableErr := LatestStable(tt.versions)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Error(t, stableErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, stableErr)
+			assert.Equal(t, tt.wantLatest, latest)
+			assert.Equal(t, tt.wantStable, stable)
+		})
+	}
+}
+
+func TestResolveGraph(t *testing.T) {
+	manager := NewManager()
+
+	t.Run("orders dependencies first", func(t *testing.T) {
+		app := &module.Module{ID: "app", Dependencies: []*module.Dependency{
+			{Name: "network", Source: "network", Version: "1.0.0"},
+			{Name: "database", Source: "database", Version: "1.0.0"},
+		}}
+		database := &module.Module{ID: "database", Dependencies: []*module.Dependency{
+			{Name: "network", Version: "1.0.0"},
+		}}
+		network := &module.Module{ID: "network", Dependencies: []*module.Dependency{
+			{Name: "external", Source: "registry/external", Version: "2.0.0"},
+		}}
+
+		sorted, err := manager.ResolveGraph([]*module.Module{app, database, network})
+		require.NoError(t, err)
+
+		var ids []string
+		for _, mod := range sorted {
+			ids = append(ids, mod.ID)
+		}
+		assert.Equal(t, []string{"network", "database", "app"}, ids)
+	})
+
+	t.Run("detects cycles", func(t *testing.T) {
+		a := &module.Module{ID: "a", Dependencies: []*module.Dependency{{Source: "b"}}}
+		b := &module.Module{ID: "b", Dependencies: []*module.Dependency{{Source: "c"}}}
+		c := &module.Module{ID: "c", Dependencies: []*module.Dependency{{Source: "a"}}}
+
+		_, err := manager.ResolveGraph([]*module.Module{a, b, c})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "a -> b -> c -> a")
+	})
+
+	t.Run("detects self dependency", func(t *testing.T) {
+		a := &module.Module{ID: "a", Dependencies: []*module.Dependency{{Source: "a"}}}
+
+		_, err := manager.ResolveGraph([]*module.Module{a})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "a -> a")
+	})
+}
+
+func TestBump(t *testing.T) {
+	manager := NewManager()
+
+	tests := []struct {
+		name    string
+		version string
+		kind    BumpKind
+		want    string
+		wantErr bool
+	}{
+		{name: "major", version: "1.2.3", kind: BumpMajor, want: "2.0.0"},
+		{name: "minor", version: "1.2.3", kind: BumpMinor, want: "1.3.0"},
+		{name: "patch", version: "1.2.3", kind: BumpPatch, want: "1.2.4"},
+		{name: "major drops prerelease and metadata", version: "1.2.3-rc.1+build.5", kind: BumpMajor, want: "2.0.0"},
+		{name: "patch drops prerelease", version: "1.2.3-rc.1", kind: BumpPatch, want: "1.2.4"},
+		{name: "invalid version", version: "invalid", kind: BumpPatch, wantErr: true},
+		{name: "unknown kind", version: "1.2.3", kind: BumpKind(42), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manager.Bump(tt.version, tt.kind)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBumpPrerelease(t *testing.T) {
+	manager := NewManager()
+
+	tests := []struct {
+		name    string
+		version string
+		want    string
+		wantErr bool
+	}{
+		{name: "numeric suffix", version: "1.2.3-rc.1", want: "1.2.3-rc.2"},
+		{name: "multi-digit suffix", version: "1.2.3-beta.9", want: "1.2.3-beta.10"},
+		{name: "bare number", version: "1.2.3-4", want: "1.2.3-5"},
+		{name: "no numeric suffix", version: "1.2.3-alpha", want: "1.2.3-alpha.1"},
+		{name: "drops metadata", version: "1.2.3-rc.1+build.5", want: "1.2.3-rc.2"},
+		{name: "not a prerelease", version: "1.2.3", wantErr: true},
+		{name: "invalid version", version: "invalid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manager.BumpPrerelease(tt.version)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	manager := NewManager()
+
+	tests := []struct {
+		name        string
+		constraints []string
+		want        string
+		wantErr     bool
+	}{
+		{name: "range", constraints: []string{">=1.0.0", "<2.0.0"}, want: ">=1.0.0, <2.0.0"},
+		{name: "caret and tilde", constraints: []string{"^1.2.0", "~1.4"}, want: "^1.2.0, ~1.4"},
+		{name: "or distributes", constraints: []string{"^1.0.0 || ^3.0.0", ">=1.5.0"}, want: "^1.0.0, >=1.5.0 || ^3.0.0, >=1.5.0"},
+		{name: "disjoint ranges", constraints: []string{">=2.0.0", "<1.0.0"}, wantErr: true},
+		{name: "conflicting exact versions", constraints: []string{"1.2.3", "1.2.4"}, wantErr: true},
+		{name: "invalid constraint", constraints: []string{">=1.0.0", "invalid"}, wantErr: true},
+		{name: "empty", constraints: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manager.Intersect(tt.constraints)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBestMatch(t *testing.T) {
+	manager := NewManager()
+	versions := []string{"0.9.0", "1.0.0", "1.5.0", "1.10.0", "2.0.0", "2.1.0-rc.1"}
+
+	tests := []struct {
+		name        string
+		constraints []string
+		want        string
+		wantErr     bool
+	}{
+		{name: "range", constraints: []string{">=1.0.0", "<2.0.0"}, want: "1.10.0"},
+		{name: "narrowed range", constraints: []string{">=1.0.0", "<2.0.0", "~1.5"}, want: "1.5.0"},
+		{name: "no constraints", constraints: nil, want: "2.1.0-rc.1"},
+		{name: "unsatisfiable", constraints: []string{">=2.0.0", "<1.0.0"}, wantErr: true},
+		{name: "invalid constraint", constraints: []string{"invalid"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manager.BestMatch(tt.constraints, versions)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBestMatchKeepsOriginalVersion(t *testing.T) {
+	manager := NewManager()
+
+	got, err := manager.BestMatch([]string{">=1.0.0"}, []string{"v1.0", "v1.2", "0.9.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2", got)
+}
+
+func TestSignatureVerification(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	otherKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	newModule := func() *module.Module {
+		return &module.Module{
+			ID:       "test-module",
+			Version:  "1.0.0",
+			Source:   "github.com/test/module",
+			Metadata: map[string]interface{}{"team": "platform"},
+		}
+	}
+
+	signed := newModule()
+	require.NoError(t, Sign(signed, privateKey))
+	require.NotEmpty(t, signed.Signature)
+
+	t.Run("valid signature", func(t *testing.T) {
+		manager := NewManagerWithOptions(Options{PublicKey: publicKey})
+		assert.NoError(t, manager.Verify(signed))
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		manager := NewManagerWithOptions(Options{PublicKey: publicKey})
+		assert.ErrorIs(t, manager.Verify(newModule()), ErrSignatureMissing)
+	})
+
+	t.Run("tampered module", func(t *testing.T) {
+		manager := NewManagerWithOptions(Options{PublicKey: publicKey})
+		tampered := *signed
+		tampered.Source = "github.com/evil/module"
+		assert.ErrorIs(t, manager.Verify(&tampered), ErrSignatureInvalid)
+	})
+
+	t.Run("storage-managed fields are not signed", func(t *testing.T) {
+		manager := NewManagerWithOptions(Options{PublicKey: publicKey})
+		updated := *signed
+		updated.Metadata = map[string]interface{}{"team": "networking"}
+		updated.CreatedAt = time.Now()
+		updated.UpdatedAt = time.Now()
+		assert.NoError(t, manager.Verify(&updated))
+	})
+
+	t.Run("revision bumps keep the signature valid", func(t *testing.T) {
+		manager := NewManagerWithOptions(Options{PublicKey: publicKey})
+		bumped := *signed
+		bumped.Revision += 3
+		assert.NoError(t, manager.Verify(&bumped))
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		manager := NewManagerWithOptions(Options{PublicKey: otherKey})
+		assert.ErrorIs(t, manager.Verify(signed), ErrSignatureInvalid)
+	})
+
+	t.Run("malformed signature", func(t *testing.T) {
+		manager := NewManagerWithOptions(Options{PublicKey: publicKey})
+		malformed := *signed
+		malformed.Signature = "not base64!"
+		assert.ErrorIs(t, manager.Verify(&malformed), ErrSignatureInvalid)
+	})
+
+	t.Run("verification is opt-in", func(t *testing.T) {
+		assert.NoError(t, NewManager().Verify(newModule()))
+	})
+}
@@ -0,0 +1,80 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ContainerGroup tracks started containers so they can be terminated together
+type ContainerGroup struct {
+	mu         sync.Mutex
+	containers []*Container
+}
+
+// NewContainerGroup creates an empty container group
+func NewContainerGroup() *ContainerGroup {
+	return &ContainerGroup{}
+}
+
+// defaultGroup is the package-level registry used by TerminateAll
+var defaultGroup = NewContainerGroup()
+
+// DefaultGroup returns the package-level container registry. Set it as
+// ContainerConfig.Group and call TerminateAll from TestMain to guarantee cleanup.
+func DefaultGroup() *ContainerGroup {
+	return defaultGroup
+}
+
+// TerminateAll terminates every container in the package-level registry
+func TerminateAll(ctx context.Context) error {
+	return defaultGroup.TerminateAll(ctx)
+}
+
+// Add registers a container with the group
+func (g *ContainerGroup) Add(c *Container) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c.group = g
+	g.containers = append(g.containers, c)
+}
+
+// Len returns the number of containers tracked by the group
+func (g *ContainerGroup) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.containers)
+}
+
+// remove stops tracking a container
+func (g *ContainerGroup) remove(c *Container) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, tracked := range g.containers {
+		if tracked == c {
+			g.containers = append(g.containers[:i], g.containers[i+1:]...)
+			return
+		}
+	}
+}
+
+// TerminateAll terminates every tracked container, continuing past failures,
+// and returns the combined errors. The group is empty afterwards.
+func (g *ContainerGroup) TerminateAll(ctx context.Context) error {
+	g.mu.Lock()
+	containers := g.containers
+	g.containers = nil
+	g.mu.Unlock()
+
+	var errs []error
+	// Terminate in reverse start order so dependents go before dependencies
+	for i := len(containers) - 1; i >= 0; i-- {
+		c := containers[i]
+		c.group = nil
+		if err := c.container.Terminate(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to terminate container %s: %w", c.config.Image, err))
+		}
+	}
+	return errors.Join(errs...)
+}
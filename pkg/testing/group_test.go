@@ -0,0 +1,101 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+)
+
+// fakeContainer records termination and commands without talking to Docker
+type fakeContainer struct {
+	testcontainers.Container
+	err        error
+	terminated bool
+	// execs records executed commands; exitCode decides each command's exit code
+	execs    [][]string
+	exitCode func(cmd []string) int
+	// logs is returned by Logs
+	logs string
+}
+
+func (f *fakeContainer) Logs(ctx context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(f.logs)), nil
+}
+
+func (f *fakeContainer) Exec(ctx context.Context, cmd []string, options ...tcexec.ProcessOption) (int, io.Reader, error) {
+	f.execs = append(f.execs, cmd)
+	code := 0
+	if f.exitCode != nil {
+		code = f.exitCode(cmd)
+	}
+	return code, strings.NewReader("output"), nil
+}
+
+func (f *fakeContainer) Terminate(ctx context.Context, opts ...testcontainers.TerminateOption) error {
+	f.terminated = true
+	return f.err
+}
+
+func TestContainerGroupTerminateAll(t *testing.T) {
+	group := NewContainerGroup()
+
+	fakes := []*fakeContainer{{}, {err: errors.New("stop failed")}, {}}
+	for _, f := range fakes {
+		group.Add(&Container{container: f, config: ContainerConfig{Image: "fake"}})
+	}
+	require.Equal(t, 3, group.Len())
+
+	err := group.TerminateAll(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stop failed")
+
+	for _, f := range fakes {
+		assert.True(t, f.terminated)
+	}
+	assert.Equal(t, 0, group.Len())
+	assert.NoError(t, group.TerminateAll(context.Background()))
+}
+
+func TestContainerStopRemovesFromGroup(t *testing.T) {
+	group := NewContainerGroup()
+	fake := &fakeContainer{}
+	c := &Container{container: fake}
+	group.Add(c)
+
+	require.NoError(t, c.Stop(context.Background()))
+	assert.True(t, fake.terminated)
+	assert.Equal(t, 0, group.Len())
+}
+
+func TestContainerGroupNewContainer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping container tests in short mode")
+	}
+
+	if !isDockerAvailable() {
+		t.Skip("Docker is not available")
+	}
+
+	ctx := context.Background()
+	group := NewContainerGroup()
+
+	for i := 0; i < 2; i++ {
+		_, err := NewContainer(ctx, ContainerConfig{
+			Image: "redis",
+			Tag:   "6-alpine",
+			Group: group,
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, group.Len())
+
+	assert.NoError(t, group.TerminateAll(ctx))
+	assert.Equal(t, 0, group.Len())
+}
@@ -0,0 +1,10 @@
+// Package tracing provides distributed tracing for services and HTTP handlers.
+//
+// The Tracer is backed by the OpenTelemetry SDK and exports spans over OTLP/gRPC
+// to the collector at AgentHost:AgentPort; SamplingRate sets a parent-based
+// trace-ID ratio sampler. Inject, Extract, HTTPMiddleware and the gRPC
+// interceptors propagate W3C traceparent, tracestate and baggage headers, so a
+// service continues the trace and honors the sampling decision of any
+// W3C-compliant caller. Jaeger's uber-trace-id header is not read: callers
+// still sending it start a new trace.
+package tracing
@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a gRPC interceptor that starts a server span
+// for each unary call, continuing the trace propagated in the incoming metadata
+func UnaryServerInterceptor(tracer *Tracer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		span, ctx := startServerSpan(ctx, tracer, info.FullMethod)
+		resp, err := handler(ctx, req)
+		finishSpan(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a gRPC interceptor that starts a server span
+// for each streaming call, continuing the trace propagated in the incoming metadata
+func StreamServerInterceptor(tracer *Tracer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		span, ctx := startServerSpan(ss.Context(), tracer, info.FullMethod)
+		err := handler(srv, &tracedServerStream{ServerStream: ss, ctx: ctx})
+		finishSpan(span, err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns a gRPC interceptor that starts a client span
+// for each unary call and propagates it to the server in the outgoing metadata
+func UnaryClientInterceptor(tracer *Tracer) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		span, ctx := startClientSpan(ctx, tracer, method)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		finishSpan(span, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a gRPC interceptor that starts a client span
+// for each streaming call and propagates it to the server in the outgoing
+// metadata. The span finishes when the stream ends.
+func StreamClientInterceptor(tracer *Tracer) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		span, ctx := startClientSpan(ctx, tracer, method)
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			finishSpan(span, err)
+			return nil, err
+		}
+
+		stream := &tracedClientStream{ClientStream: cs, span: span}
+		go func() {
+			<-cs.Context().Done()
+			stream.finish(nil)
+		}()
+		return stream, nil
+	}
+}
+
+// startServerSpan starts a span for an incoming call, as a child of the caller's span if one was propagated
+func startServerSpan(ctx context.Context, tracer *Tracer, method string) (trace.Span, context.Context) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx, _ = tracer.Extract(ctx, metadataCarrier(md))
+	}
+
+	span, ctx := tracer.StartSpanFromContext(ctx, method, trace.WithSpanKind(trace.SpanKindServer))
+	span.SetAttributes(attribute.String("component", "grpc"))
+	return span, ctx
+}
+
+// startClientSpan starts a span for an outgoing call and injects it into the outgoing metadata
+func startClientSpan(ctx context.Context, tracer *Tracer, method string) (trace.Span, context.Context) {
+	span, ctx := tracer.StartSpanFromContext(ctx, method, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(attribute.String("component", "grpc"))
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	if err := tracer.Inject(ctx, metadataCarrier(md)); err == nil {
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+	return span, ctx
+}
+
+// finishSpan tags the span with the call's gRPC status code and ends it
+func finishSpan(span trace.Span, err error) {
+	span.SetAttributes(attribute.String("grpc.code", status.Code(err).String()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// metadataCarrier adapts gRPC metadata to the propagation.TextMapCarrier interface
+type metadataCarrier metadata.MD
+
+// Get implements propagation.TextMapCarrier
+func (c metadataCarrier) Get(key string) string {
+	if vals := c[strings.ToLower(key)]; len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// Set implements propagation.TextMapCarrier
+func (c metadataCarrier) Set(key, val string) {
+	c[strings.ToLower(key)] = []string{val}
+}
+
+// Keys implements propagation.TextMapCarrier
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// tracedServerStream carries the server span's context to the stream handler
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// tracedClientStream finishes the client span once the stream ends
+type tracedClientStream struct {
+	grpc.ClientStream
+	span trace.Span
+	once sync.Once
+}
+
+func (s *tracedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == io.EOF {
+		s.finish(nil)
+	} else if err != nil {
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *tracedClientStream) finish(err error) {
+	s.once.Do(func() {
+		finishSpan(s.span, err)
+	})
+}
@@ -0,0 +1,127 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// tracedHealthServer records the context seen by the handler and fails for the "missing" service
+type tracedHealthServer struct {
+	healthpb.UnimplementedHealthServer
+	ctx context.Context
+}
+
+func (s *tracedHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.ctx = ctx
+	if req.Service == "missing" {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	tracer, exporter := newTestTracer()
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(tracer)),
+		grpc.StreamInterceptor(StreamServerInterceptor(tracer)),
+	)
+	handler := &tracedHealthServer{}
+	healthpb.RegisterHealthServer(server, handler)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(tracer)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(tracer)),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	t.Run("links client and server spans", func(t *testing.T) {
+		exporter.Reset()
+		root, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		root.End()
+		require.NotNil(t, handler.ctx)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 3)
+		serverSpan, clientSpan, rootSpan := spans[0], spans[1], spans[2]
+
+		assert.Equal(t, "/grpc.health.v1.Health/Check", serverSpan.Name)
+		assert.Equal(t, "/grpc.health.v1.Health/Check", clientSpan.Name)
+		assert.Equal(t, rootSpan.SpanContext.SpanID(), clientSpan.Parent.SpanID())
+		assert.Equal(t, clientSpan.SpanContext.SpanID(), serverSpan.Parent.SpanID())
+		assert.Equal(t, rootSpan.SpanContext.TraceID(), serverSpan.SpanContext.TraceID())
+
+		assert.Equal(t, trace.SpanKindServer, serverSpan.SpanKind)
+		assert.Equal(t, trace.SpanKindClient, clientSpan.SpanKind)
+		assert.Equal(t, "OK", spanAttributes(serverSpan)["grpc.code"])
+		assert.Equal(t, "OK", spanAttributes(clientSpan)["grpc.code"])
+		assert.Equal(t, serverSpan.SpanContext, trace.SpanContextFromContext(handler.ctx))
+	})
+
+	t.Run("propagates baggage", func(t *testing.T) {
+		exporter.Reset()
+		root, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+		defer root.End()
+		ctx = SetBaggage(ctx, "tenant_id", "acme")
+
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		require.NotNil(t, handler.ctx)
+		assert.Equal(t, "acme", GetBaggage(handler.ctx, "tenant_id"))
+	})
+
+	t.Run("tags errors", func(t *testing.T) {
+		exporter.Reset()
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+		require.Error(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		for _, span := range spans {
+			assert.Equal(t, "NotFound", spanAttributes(span)["grpc.code"])
+			assert.Equal(t, otelcodes.Error, span.Status.Code)
+		}
+		// Without a parent span the client span starts a new trace that the server continues
+		assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	})
+}
+
+func TestClientSpanMetadata(t *testing.T) {
+	tracer, _ := newTestTracer()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "request-1")
+
+	span, ctx := startClientSpan(ctx, tracer, "/test.Service/Method")
+	defer span.End()
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	require.True(t, ok)
+	expected := fmt.Sprintf("00-%s-%s-01", span.SpanContext().TraceID(), span.SpanContext().SpanID())
+	assert.Equal(t, []string{expected}, md.Get("traceparent"))
+	assert.Equal(t, []string{"request-1"}, md.Get("x-request-id"))
+}
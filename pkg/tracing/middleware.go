@@ -5,51 +5,47 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/opentracing/opentracing-go"
-	"github.com/opentracing/opentracing-go/ext"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HTTPMiddleware creates middleware for tracing HTTP requests
 func HTTPMiddleware(tracer *Tracer) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Try to extract span context from headers
-			spanCtx, err := tracer.Extract(
-				opentracing.HTTPHeaders,
-				opentracing.HTTPHeadersCarrier(r.Header),
-			)
+			// Continue the caller's trace if the headers carry one; otherwise
+			// the span starts a new trace
+			ctx, _ := tracer.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
 
 			// Start the span
-			var span opentracing.Span
-			if err != nil {
-				// No span context in headers, create a new one
-				span = tracer.StartSpan(fmt.Sprintf("%s %s", r.Method, r.URL.Path))
-			} else {
-				// Create span as child of extracted context
-				span = tracer.StartSpan(
-					fmt.Sprintf("%s %s", r.Method, r.URL.Path),
-					ext.RPCServerOption(spanCtx),
-				)
-			}
-			defer span.Finish()
+			span, ctx := tracer.StartSpanFromContext(ctx,
+				fmt.Sprintf("%s %s", r.Method, r.URL.Path),
+				trace.WithSpanKind(trace.SpanKindServer),
+			)
+			defer span.End()
 
 			// Set standard HTTP tags
-			ext.HTTPMethod.Set(span, r.Method)
-			ext.HTTPUrl.Set(span, r.URL.String())
-			ext.Component.Set(span, "http")
+			span.SetAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.url", r.URL.String()),
+				attribute.String("component", "http"),
+			)
 
 			// Add request-specific tags
-			span.SetTag("http.remote_addr", r.RemoteAddr)
-			span.SetTag("http.user_agent", r.UserAgent())
+			span.SetAttributes(
+				attribute.String("http.remote_addr", r.RemoteAddr),
+				attribute.String("http.user_agent", r.UserAgent()),
+			)
 			if reqID := r.Header.Get("X-Request-ID"); reqID != "" {
-				span.SetTag("http.request_id", reqID)
+				span.SetAttributes(attribute.String("http.request_id", reqID))
 			}
 
 			// Create wrapped response writer to capture status code
 			wrapped := wrapResponseWriter(w)
 
 			// Add span to request context
-			ctx := opentracing.ContextWithSpan(r.Context(), span)
 			r = r.WithContext(ctx)
 
 			// Record timing
@@ -60,13 +56,15 @@ func HTTPMiddleware(tracer *Tracer) func(http.Handler) http.Handler {
 
 			// Add response tags
 			duration := time.Since(start)
-			span.SetTag("http.status_code", wrapped.status)
-			span.SetTag("http.duration_ms", float64(duration.Milliseconds()))
+			span.SetAttributes(
+				attribute.Int("http.status_code", wrapped.status),
+				attribute.Float64("http.duration_ms", float64(duration.Milliseconds())),
+			)
 
 			// Mark error if status >= 500
 			if wrapped.status >= http.StatusInternalServerError {
-				ext.Error.Set(span, true)
-				span.SetTag("error.type", "server_error")
+				span.SetStatus(codes.Error, http.StatusText(wrapped.status))
+				span.SetAttributes(attribute.String("error.type", "server_error"))
 			}
 		})
 	}
@@ -3,21 +3,30 @@ package tracing
 import (
 	"context"
 	"fmt"
-	"io"
-
-	"github.com/opentracing/opentracing-go"
-	"github.com/uber/jaeger-client-go"
-	"github.com/uber/jaeger-client-go/config"
-	"github.com/uber/jaeger-lib/metrics/prometheus"
+	"net"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+// instrumentationName identifies this package as the creator of its spans
+const instrumentationName = "github.com/StackCatalyst/common-lib/pkg/tracing"
+
 // Config holds the configuration for the tracer
 type Config struct {
 	// ServiceName is the name of the service
 	ServiceName string
-	// AgentHost is the host of the Jaeger agent
+	// AgentHost is the host of the OTLP collector
 	AgentHost string
-	// AgentPort is the port of the Jaeger agent
+	// AgentPort is the port of the collector's OTLP gRPC receiver
 	AgentPort string
 	// Enabled determines if tracing is enabled
 	Enabled bool
@@ -32,7 +41,7 @@ func DefaultConfig() Config {
 	return Config{
 		ServiceName:  "service",
 		AgentHost:    "localhost",
-		AgentPort:    "6831",
+		AgentPort:    "4317",
 		Enabled:      true,
 		SamplingRate: 0.1,
 		Tags:         make(map[string]string),
@@ -41,118 +50,162 @@ func DefaultConfig() Config {
 
 // Tracer manages the distributed tracing functionality
 type Tracer struct {
-	tracer opentracing.Tracer
-	closer io.Closer
-	config Config
+	tracer     trace.Tracer
+	provider   *sdktrace.TracerProvider
+	propagator propagation.TextMapPropagator
+	config     Config
 }
 
-// New creates a new tracer
+// New creates a new tracer exporting spans over OTLP to AgentHost:AgentPort
 func New(cfg Config) (*Tracer, error) {
 	if !cfg.Enabled {
 		return &Tracer{
-			tracer: opentracing.NoopTracer{},
-			config: cfg,
+			tracer:     noop.NewTracerProvider().Tracer(instrumentationName),
+			propagator: newPropagator(),
+			config:     cfg,
 		}, nil
 	}
 
-	jcfg := &config.Configuration{
-		ServiceName: cfg.ServiceName,
-		Sampler: &config.SamplerConfig{
-			Type:  jaeger.SamplerTypeConst,
-			Param: cfg.SamplingRate,
-		},
-		Reporter: &config.ReporterConfig{
-			LocalAgentHostPort: fmt.Sprintf("%s:%s", cfg.AgentHost, cfg.AgentPort),
-		},
-		Tags: []opentracing.Tag{
-			{Key: "service.version", Value: "1.0.0"},
-			{Key: "environment", Value: "production"},
-		},
-	}
-
-	// Add custom tags
-	for k, v := range cfg.Tags {
-		jcfg.Tags = append(jcfg.Tags, opentracing.Tag{Key: k, Value: v})
-	}
-
-	// Initialize metrics factory
-	metricsFactory := prometheus.New()
-
-	// Initialize tracer
-	tracer, closer, err := jcfg.NewTracer(
-		config.Metrics(metricsFactory),
-		config.Logger(jaeger.StdLogger),
+	exporter, err := otlptracegrpc.New(context.Background(),
+		otlptracegrpc.WithEndpoint(net.JoinHostPort(cfg.AgentHost, cfg.AgentPort)),
+		otlptracegrpc.WithInsecure(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tracer: %w", err)
 	}
 
+	t := newTracer(cfg, sdktrace.NewBatchSpanProcessor(exporter))
+
 	// Set as global tracer
-	opentracing.SetGlobalTracer(tracer)
+	otel.SetTracerProvider(t.provider)
+	otel.SetTextMapPropagator(t.propagator)
+
+	return t, nil
+}
+
+// newTracer creates a tracer whose spans are handed to processor
+func newTracer(cfg Config, processor sdktrace.SpanProcessor) *Tracer {
+	attrs := []attribute.KeyValue{attribute.String("service.name", cfg.ServiceName)}
+	for k, v := range cfg.Tags {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(resource.NewSchemaless(attrs...)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplingRate))),
+	)
 
 	return &Tracer{
-		tracer: tracer,
-		closer: closer,
-		config: cfg,
-	}, nil
+		tracer:     provider.Tracer(instrumentationName),
+		provider:   provider,
+		propagator: newPropagator(),
+		config:     cfg,
+	}
 }
 
-// StartSpan starts a new span
-func (t *Tracer) StartSpan(name string, opts ...opentracing.StartSpanOption) opentracing.Span {
-	return t.tracer.StartSpan(name, opts...)
+// newPropagator returns the W3C trace context and baggage propagator
+func newPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// StartSpan starts a new root span
+func (t *Tracer) StartSpan(name string, opts ...trace.SpanStartOption) trace.Span {
+	_, span := t.tracer.Start(context.Background(), name, opts...)
+	return span
 }
 
 // StartSpanFromContext starts a new span from a context
-func (t *Tracer) StartSpanFromContext(ctx context.Context, name string, opts ...opentracing.StartSpanOption) (opentracing.Span, context.Context) {
-	if parentSpan := opentracing.SpanFromContext(ctx); parentSpan != nil {
-		opts = append(opts, opentracing.ChildOf(parentSpan.Context()))
-	}
-	span := t.StartSpan(name, opts...)
-	return span, opentracing.ContextWithSpan(ctx, span)
+func (t *Tracer) StartSpanFromContext(ctx context.Context, name string, opts ...trace.SpanStartOption) (trace.Span, context.Context) {
+	ctx, span := t.tracer.Start(ctx, name, opts...)
+	return span, ctx
 }
 
-// Inject injects span context into carrier
-func (t *Tracer) Inject(ctx context.Context, format interface{}, carrier interface{}) error {
-	span := opentracing.SpanFromContext(ctx)
-	if span == nil {
+// Inject injects the span context and baggage in ctx into carrier
+func (t *Tracer) Inject(ctx context.Context, carrier propagation.TextMapCarrier) error {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
 		return fmt.Errorf("no span in context")
 	}
-	return t.tracer.Inject(span.Context(), format, carrier)
+	t.propagator.Inject(ctx, carrier)
+	return nil
 }
 
-// Extract extracts span context from carrier
-func (t *Tracer) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
-	return t.tracer.Extract(format, carrier)
+// Extract extracts the span context and baggage in carrier into ctx. The
+// returned context carries any baggage found even when an error is returned
+// because the carrier holds no span context.
+func (t *Tracer) Extract(ctx context.Context, carrier propagation.TextMapCarrier) (context.Context, error) {
+	ctx = t.propagator.Extract(ctx, carrier)
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, fmt.Errorf("no span context in carrier")
+	}
+	return ctx, nil
 }
 
-// Close closes the tracer
+// Close flushes pending spans and shuts down the tracer
 func (t *Tracer) Close() error {
-	if t.closer != nil {
-		return t.closer.Close()
+	if t.provider != nil {
+		return t.provider.Shutdown(context.Background())
 	}
 	return nil
 }
 
 // WithField adds a field to the span in context
 func WithField(ctx context.Context, key string, value interface{}) {
-	if span := opentracing.SpanFromContext(ctx); span != nil {
-		span.SetTag(key, value)
-	}
+	trace.SpanFromContext(ctx).SetAttributes(attributeOf(key, value))
 }
 
-// WithError adds an error to the span in context
+// WithError records an error on the span in context and marks the span failed
 func WithError(ctx context.Context, err error) {
-	if span := opentracing.SpanFromContext(ctx); span != nil {
-		span.SetTag("error", true)
-		span.SetTag("error.message", err.Error())
-	}
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
 }
 
 // WithFields adds multiple fields to the span in context
 func WithFields(ctx context.Context, fields map[string]interface{}) {
-	if span := opentracing.SpanFromContext(ctx); span != nil {
-		for k, v := range fields {
-			span.SetTag(k, v)
-		}
+	span := trace.SpanFromContext(ctx)
+	for k, v := range fields {
+		span.SetAttributes(attributeOf(k, v))
+	}
+}
+
+// attributeOf converts a field to a span attribute, formatting types without
+// a native attribute representation as strings
+func attributeOf(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case []string:
+		return attribute.StringSlice(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
 	}
 }
+
+// SetBaggage returns a copy of ctx carrying the baggage item. Baggage is
+// carried to child spans and across process boundaries by Inject and Extract.
+// ctx is returned unchanged if key is empty.
+func SetBaggage(ctx context.Context, key, value string) context.Context {
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// GetBaggage returns a baggage item from the context, or an empty string
+func GetBaggage(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
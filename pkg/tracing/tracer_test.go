@@ -3,14 +3,37 @@ package tracing
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
-	"github.com/opentracing/opentracing-go"
-	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// newTestTracer returns a tracer sampling every span into an in-memory exporter
+func newTestTracer() (*Tracer, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	cfg := DefaultConfig()
+	cfg.SamplingRate = 1
+	return newTracer(cfg, sdktrace.NewSimpleSpanProcessor(exporter)), exporter
+}
+
+// spanAttributes returns the attributes of a finished span keyed by name
+func spanAttributes(span tracetest.SpanStub) map[string]interface{} {
+	attrs := make(map[string]interface{}, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return attrs
+}
+
 func TestTracerCreation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -22,7 +45,7 @@ func TestTracerCreation(t *testing.T) {
 			config: Config{
 				ServiceName:  "test-service",
 				AgentHost:    "localhost",
-				AgentPort:    "6831",
+				AgentPort:    "4317",
 				Enabled:      true,
 				SamplingRate: 0.1,
 			},
@@ -52,30 +75,52 @@ func TestTracerCreation(t *testing.T) {
 	}
 }
 
+func TestTracerConfig(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := newTracer(Config{
+		ServiceName:  "test-service",
+		SamplingRate: 0,
+		Tags:         map[string]string{"environment": "test"},
+	}, sdktrace.NewSimpleSpanProcessor(exporter))
+
+	span := tracer.StartSpan("unsampled")
+	span.End()
+	assert.Empty(t, exporter.GetSpans(), "a zero sampling rate drops root spans")
+
+	// Parent-based sampling keeps spans of traces the caller sampled
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	span, _ = tracer.StartSpanFromContext(trace.ContextWithRemoteSpanContext(context.Background(), parent), "sampled")
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	resource := spans[0].Resource.Set()
+	serviceName, _ := resource.Value("service.name")
+	assert.Equal(t, "test-service", serviceName.AsString())
+	environment, _ := resource.Value(attribute.Key("environment"))
+	assert.Equal(t, "test", environment.AsString())
+}
+
 func TestSpanCreation(t *testing.T) {
-	// Use mock tracer for testing
-	mockTracer := mocktracer.New()
-	tracer := &Tracer{
-		tracer: mockTracer,
-		config: DefaultConfig(),
-	}
+	tracer, exporter := newTestTracer()
 
 	// Test simple span creation
 	span := tracer.StartSpan("test-operation")
 	require.NotNil(t, span)
-	span.Finish()
+	span.End()
 
-	mockSpans := mockTracer.FinishedSpans()
-	require.Len(t, mockSpans, 1)
-	assert.Equal(t, "test-operation", mockSpans[0].OperationName)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "test-operation", spans[0].Name)
 }
 
 func TestSpanContext(t *testing.T) {
-	mockTracer := mocktracer.New()
-	tracer := &Tracer{
-		tracer: mockTracer,
-		config: DefaultConfig(),
-	}
+	tracer, exporter := newTestTracer()
 
 	// Create parent span
 	parentSpan, parentCtx := tracer.StartSpanFromContext(context.Background(), "parent-operation")
@@ -86,22 +131,19 @@ func TestSpanContext(t *testing.T) {
 	require.NotNil(t, childSpan)
 
 	// Finish spans
-	childSpan.Finish()
-	parentSpan.Finish()
+	childSpan.End()
+	parentSpan.End()
 
 	// Verify spans
-	mockSpans := mockTracer.FinishedSpans()
-	require.Len(t, mockSpans, 2)
-	assert.Equal(t, "child-operation", mockSpans[0].OperationName)
-	assert.Equal(t, "parent-operation", mockSpans[1].OperationName)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child-operation", spans[0].Name)
+	assert.Equal(t, "parent-operation", spans[1].Name)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
 }
 
 func TestSpanTags(t *testing.T) {
-	mockTracer := mocktracer.New()
-	tracer := &Tracer{
-		tracer: mockTracer,
-		config: DefaultConfig(),
-	}
+	tracer, exporter := newTestTracer()
 
 	// Create context with span
 	span, ctx := tracer.StartSpanFromContext(context.Background(), "test-operation")
@@ -110,55 +152,173 @@ func TestSpanTags(t *testing.T) {
 	// Add fields
 	WithField(ctx, "string-tag", "value")
 	WithField(ctx, "int-tag", 42)
+	WithField(ctx, "other-tag", struct{ N int }{7})
 	WithError(ctx, fmt.Errorf("test error"))
 	WithFields(ctx, map[string]interface{}{
 		"batch-tag-1": "value1",
 		"batch-tag-2": "value2",
 	})
 
-	span.Finish()
+	span.End()
 
 	// Verify tags
-	mockSpans := mockTracer.FinishedSpans()
-	require.Len(t, mockSpans, 1)
-	mockSpan := mockSpans[0]
-
-	assert.Equal(t, "value", mockSpan.Tags()["string-tag"])
-	assert.Equal(t, 42, mockSpan.Tags()["int-tag"])
-	assert.Equal(t, true, mockSpan.Tags()["error"])
-	assert.Equal(t, "test error", mockSpan.Tags()["error.message"])
-	assert.Equal(t, "value1", mockSpan.Tags()["batch-tag-1"])
-	assert.Equal(t, "value2", mockSpan.Tags()["batch-tag-2"])
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	finished := spans[0]
+	attrs := spanAttributes(finished)
+
+	assert.Equal(t, "value", attrs["string-tag"])
+	assert.Equal(t, int64(42), attrs["int-tag"])
+	assert.Equal(t, "{7}", attrs["other-tag"])
+	assert.Equal(t, "value1", attrs["batch-tag-1"])
+	assert.Equal(t, "value2", attrs["batch-tag-2"])
+	assert.Equal(t, codes.Error, finished.Status.Code)
+	assert.Equal(t, "test error", finished.Status.Description)
+	require.Len(t, finished.Events, 1)
+	assert.Equal(t, "exception", finished.Events[0].Name)
 }
 
 func TestContextPropagation(t *testing.T) {
-	mockTracer := mocktracer.New()
-	tracer := &Tracer{
-		tracer: mockTracer,
-		config: DefaultConfig(),
+	tracer, exporter := newTestTracer()
+
+	t.Run("inject and extract", func(t *testing.T) {
+		exporter.Reset()
+
+		// Create a span and inject it into carrier
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "test-operation")
+		carrier := propagation.MapCarrier{}
+		require.NoError(t, tracer.Inject(ctx, carrier))
+		assert.Contains(t, carrier, "traceparent")
+
+		// Extract span context from carrier
+		remoteCtx, err := tracer.Extract(context.Background(), carrier)
+		require.NoError(t, err)
+
+		// Create a new span with extracted context
+		childSpan, _ := tracer.StartSpanFromContext(remoteCtx, "child-operation")
+		require.NotNil(t, childSpan)
+
+		// Finish spans
+		childSpan.End()
+		span.End()
+
+		// Verify spans
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+		assert.True(t, spans[0].Parent.IsRemote())
+	})
+
+	t.Run("inject without span", func(t *testing.T) {
+		assert.Error(t, tracer.Inject(context.Background(), propagation.MapCarrier{}))
+	})
+
+	t.Run("extract without span context", func(t *testing.T) {
+		_, err := tracer.Extract(context.Background(), propagation.MapCarrier{})
+		assert.Error(t, err)
+	})
+}
+
+func TestBaggage(t *testing.T) {
+	tracer, _ := newTestTracer()
+
+	t.Run("no span", func(t *testing.T) {
+		ctx := SetBaggage(context.Background(), "tenant_id", "acme")
+		assert.Equal(t, "acme", GetBaggage(ctx, "tenant_id"))
+		assert.Empty(t, GetBaggage(context.Background(), "tenant_id"))
+	})
+
+	t.Run("empty key", func(t *testing.T) {
+		ctx := context.Background()
+		assert.Equal(t, ctx, SetBaggage(ctx, "", "acme"))
+	})
+
+	t.Run("child spans", func(t *testing.T) {
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "parent")
+		defer span.End()
+		ctx = SetBaggage(ctx, "tenant_id", "acme")
+		assert.Equal(t, "acme", GetBaggage(ctx, "tenant_id"))
+
+		child, childCtx := tracer.StartSpanFromContext(ctx, "child")
+		defer child.End()
+		assert.Equal(t, "acme", GetBaggage(childCtx, "tenant_id"))
+	})
+
+	t.Run("http headers", func(t *testing.T) {
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "client")
+		defer span.End()
+		ctx = SetBaggage(ctx, "tenant_id", "acme")
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		require.NoError(t, tracer.Inject(ctx, propagation.HeaderCarrier(req.Header)))
+
+		var tenant string
+		handler := HTTPMiddleware(tracer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenant = GetBaggage(r.Context(), "tenant_id")
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, "acme", tenant)
+	})
+}
+
+func TestW3CTraceContext(t *testing.T) {
+	const (
+		traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		tracestate  = "vendor=value"
+	)
+
+	// continueRequest serves a request carrying the given traceparent and
+	// returns the headers the handler injects for a downstream call
+	continueRequest := func(t *testing.T, tracer *Tracer, traceparent string) http.Header {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("traceparent", traceparent)
+		req.Header.Set("tracestate", tracestate)
+
+		outgoing := http.Header{}
+		handler := HTTPMiddleware(tracer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, tracer.Inject(r.Context(), propagation.HeaderCarrier(outgoing)))
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return outgoing
 	}
 
-	// Create a span and inject it into carrier
-	span := tracer.StartSpan("test-operation")
-	carrier := opentracing.TextMapCarrier{}
-	err := tracer.tracer.Inject(span.Context(), opentracing.TextMap, carrier)
-	require.NoError(t, err)
+	t.Run("continues a foreign trace", func(t *testing.T) {
+		tracer, exporter := newTestTracer()
+		outgoing := continueRequest(t, tracer, traceparent)
 
-	// Extract span context from carrier
-	spanContext, err := tracer.Extract(opentracing.TextMap, carrier)
-	require.NoError(t, err)
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
 
-	// Create a new span with extracted context
-	childSpan := tracer.StartSpan("child-operation",
-		opentracing.ChildOf(spanContext))
-	require.NotNil(t, childSpan)
+		expected := fmt.Sprintf("00-4bf92f3577b34da6a3ce929d0e0e4736-%s-01", spans[0].SpanContext.SpanID())
+		assert.Equal(t, expected, outgoing.Get("traceparent"))
+		assert.Equal(t, tracestate, outgoing.Get("tracestate"))
+	})
 
-	// Finish spans
-	childSpan.Finish()
-	span.Finish()
+	t.Run("honors the caller's sampling decision", func(t *testing.T) {
+		tracer, exporter := newTestTracer()
+		outgoing := continueRequest(t, tracer, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
 
-	// Verify spans
-	mockSpans := mockTracer.FinishedSpans()
-	require.Len(t, mockSpans, 2)
-	assert.Equal(t, mockSpans[1].SpanContext.SpanID, mockSpans[0].ParentID)
+		assert.Empty(t, exporter.GetSpans())
+		assert.Regexp(t, `^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-00$`, outgoing.Get("traceparent"))
+	})
+
+	t.Run("disabled tracer passes the trace through", func(t *testing.T) {
+		tracer, err := New(Config{Enabled: false})
+		require.NoError(t, err)
+
+		outgoing := continueRequest(t, tracer, traceparent)
+		assert.Equal(t, traceparent, outgoing.Get("traceparent"))
+		assert.Equal(t, tracestate, outgoing.Get("tracestate"))
+	})
+
+	t.Run("ignores a malformed header", func(t *testing.T) {
+		tracer, exporter := newTestTracer()
+		continueRequest(t, tracer, "not-a-traceparent")
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.False(t, spans[0].Parent.IsValid())
+	})
 }
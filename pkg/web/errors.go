@@ -0,0 +1,34 @@
+package web
+
+import (
+	"errors"
+
+	liberrors "github.com/StackCatalyst/common-lib/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorResponse is the JSON envelope for error responses
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes an error in an ErrorResponse
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// WriteError aborts the request with the HTTP status for err and an
+// ErrorResponse body. Errors without a pkg/errors code are reported as an
+// internal error without exposing their message.
+func WriteError(c *gin.Context, err error) {
+	_ = c.Error(err)
+
+	body := ErrorBody{Code: string(liberrors.ErrInternal), Message: "internal server error"}
+	var appErr *liberrors.AppError
+	if errors.As(err, &appErr) {
+		body = ErrorBody{Code: string(appErr.Code), Message: appErr.Message}
+	}
+
+	c.AbortWithStatusJSON(liberrors.HTTPStatus(err), ErrorResponse{Error: body})
+}
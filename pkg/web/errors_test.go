@@ -0,0 +1,46 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/StackCatalyst/common-lib/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   ErrorBody
+	}{
+		{"not found", errors.New(errors.ErrNotFound, "module not found"), http.StatusNotFound,
+			ErrorBody{Code: "NOT_FOUND", Message: "module not found"}},
+		{"wrapped with fmt", fmt.Errorf("handler: %w", errors.New(errors.ErrValidation, "bad limit")), http.StatusBadRequest,
+			ErrorBody{Code: "VALIDATION", Message: "bad limit"}},
+		{"plain error", fmt.Errorf("connection refused"), http.StatusInternalServerError,
+			ErrorBody{Code: "INTERNAL", Message: "internal server error"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			WriteError(c, tt.err)
+
+			assert.True(t, c.IsAborted())
+			assert.Equal(t, tt.status, w.Code)
+			var resp ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.body, resp.Error)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Package web provides helpers shared by Gin HTTP handlers.
+package web
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Query parameters read by ParsePage
+const (
+	LimitParam  = "limit"
+	OffsetParam = "offset"
+	CursorParam = "cursor"
+)
+
+// PaginationConfig holds the pagination defaults for list endpoints
+type PaginationConfig struct {
+	// DefaultLimit is the page size used when no limit is given
+	DefaultLimit int `json:"default_limit" yaml:"default_limit"`
+	// MaxLimit caps the page size a client can request
+	MaxLimit int `json:"max_limit" yaml:"max_limit"`
+}
+
+// DefaultPaginationConfig returns the default pagination configuration
+func DefaultPaginationConfig() PaginationConfig {
+	return PaginationConfig{
+		DefaultLimit: 20,
+		MaxLimit:     100,
+	}
+}
+
+// Page is a validated page request. Limit and Offset map directly onto
+// storage.Filter's fields of the same name.
+type Page struct {
+	Limit  int     // Maximum number of items to return
+	Offset int     // Number of items to skip
+	Cursor *Cursor // Keyset position to resume after, if given
+}
+
+// ParsePage reads the limit, offset and cursor query parameters. A missing
+// limit uses the default and a limit above the maximum is capped. Malformed
+// or negative values, or an offset combined with a cursor, return an
+// errors.ErrValidation error.
+func ParsePage(c *gin.Context, config PaginationConfig) (Page, error) {
+	page := Page{Limit: config.DefaultLimit}
+
+	if raw := c.Query(LimitParam); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return Page{}, errors.New(errors.ErrValidation, fmt.Sprintf("invalid %s: %q", LimitParam, raw))
+		}
+		page.Limit = limit
+	}
+	if config.MaxLimit > 0 && page.Limit > config.MaxLimit {
+		page.Limit = config.MaxLimit
+	}
+
+	if raw := c.Query(OffsetParam); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return Page{}, errors.New(errors.ErrValidation, fmt.Sprintf("invalid %s: %q", OffsetParam, raw))
+		}
+		page.Offset = offset
+	}
+
+	if raw := c.Query(CursorParam); raw != "" {
+		if page.Offset != 0 {
+			return Page{}, errors.New(errors.ErrValidation, fmt.Sprintf("%s and %s cannot be combined", OffsetParam, CursorParam))
+		}
+		cursor, err := DecodeCursor(raw)
+		if err != nil {
+			return Page{}, err
+		}
+		page.Cursor = &cursor
+	}
+
+	return page, nil
+}
+
+// Cursor is a keyset pagination position over created_at, with the item ID
+// breaking ties between items created at the same instant
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// EncodeCursor returns an opaque, URL-safe token for a cursor
+func EncodeCursor(cursor Cursor) string {
+	// Marshalling a time and a string cannot fail
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by EncodeCursor, returning an
+// errors.ErrValidation error if it is malformed
+func DecodeCursor(token string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, errors.Wrap(err, errors.ErrValidation, "invalid cursor")
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return Cursor{}, errors.Wrap(err, errors.ErrValidation, "invalid cursor")
+	}
+	if cursor.CreatedAt.IsZero() {
+		return Cursor{}, errors.New(errors.ErrValidation, "invalid cursor: missing position")
+	}
+	return cursor, nil
+}
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/StackCatalyst/common-lib/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestContext(query string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/modules?"+query, nil)
+	return c
+}
+
+func TestParsePage(t *testing.T) {
+	config := DefaultPaginationConfig()
+
+	tests := []struct {
+		name  string
+		query string
+		want  Page
+	}{
+		{"defaults", "", Page{Limit: 20}},
+		{"explicit", "limit=50&offset=100", Page{Limit: 50, Offset: 100}},
+		{"at max", "limit=100", Page{Limit: 100}},
+		{"capped", "limit=1000", Page{Limit: 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := ParsePage(newTestContext(tt.query), config)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, page)
+		})
+	}
+}
+
+func TestParsePageInvalid(t *testing.T) {
+	config := DefaultPaginationConfig()
+	cursor := EncodeCursor(Cursor{CreatedAt: time.Now(), ID: "aws-vpc"})
+
+	for _, query := range []string{
+		"limit=abc",
+		"limit=0",
+		"limit=-5",
+		"offset=-1",
+		"offset=1.5",
+		"cursor=not-a-cursor",
+		"offset=10&cursor=" + cursor,
+	} {
+		t.Run(query, func(t *testing.T) {
+			_, err := ParsePage(newTestContext(query), config)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, errors.ErrValidation))
+			assert.Equal(t, http.StatusBadRequest, errors.HTTPStatus(err))
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC), ID: "aws-vpc"}
+
+	token := EncodeCursor(cursor)
+	decoded, err := DecodeCursor(token)
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+
+	page, err := ParsePage(newTestContext("limit=10&cursor="+token), DefaultPaginationConfig())
+	require.NoError(t, err)
+	require.NotNil(t, page.Cursor)
+	assert.Equal(t, "aws-vpc", page.Cursor.ID)
+	assert.Equal(t, 10, page.Limit)
+
+	_, err = DecodeCursor(EncodeCursor(Cursor{ID: "no-position"}))
+	assert.True(t, errors.Is(err, errors.ErrValidation))
+}